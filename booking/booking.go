@@ -0,0 +1,79 @@
+package booking
+
+import (
+	"restaurant_management/models"
+	"sort"
+	"time"
+)
+
+// TurnTime is how long a party of party keeps its table.
+func (c Config) TurnTime(party int) time.Duration {
+	for _, turn := range c.Turn_times {
+		if party <= turn.Max_party {
+			return time.Duration(turn.Minutes) * time.Minute
+		}
+	}
+	return time.Duration(c.Default_turn_minutes) * time.Minute
+}
+
+// Slots returns the start times offered on the day of date in loc, from
+// Opens to Last_seating every Slot_minutes.
+func (c Config) Slots(date time.Time, loc *time.Location) []time.Time {
+	first, last := c.seatingHours(date, loc)
+	slots := []time.Time{}
+	for slot := first; !slot.After(last); slot = slot.Add(time.Duration(c.Slot_minutes) * time.Minute) {
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+// Bookable reports whether a party may be seated at start: within the
+// seating hours of its day in loc.
+func (c Config) Bookable(start time.Time, loc *time.Location) bool {
+	first, last := c.seatingHours(start.In(loc), loc)
+	return !start.Before(first) && !start.After(last)
+}
+
+func (c Config) seatingHours(date time.Time, loc *time.Location) (time.Time, time.Time) {
+	year, month, day := date.In(loc).Date()
+	at := func(clock string) time.Time {
+		parsed, _ := time.Parse("15:04", clock)
+		return time.Date(year, month, day, parsed.Hour(), parsed.Minute(), 0, 0, loc)
+	}
+	return at(c.Opens), at(c.Last_seating)
+}
+
+// Holds reports whether reservation keeps its table for any part of
+// start..end.
+func Holds(reservation models.Reservation, start time.Time, end time.Time) bool {
+	return reservation.Status == models.ReservationBooked &&
+		reservation.Reserved_at.Before(end) && reservation.Ends_at.After(start)
+}
+
+// FreeTables returns the tables that seat party, are in service and no
+// reservation other than except holds during start..end, smallest first so
+// that big tables stay free for big parties.
+func FreeTables(tables []models.Table, reservations []models.Reservation, party int, start time.Time, end time.Time, except string) []models.Table {
+	held := map[string]bool{}
+	for _, reservation := range reservations {
+		if reservation.Reservation_id != except && Holds(reservation, start, end) {
+			held[reservation.Table_id] = true
+		}
+	}
+	free := []models.Table{}
+	for _, table := range tables {
+		if !Seats(table, party) || held[table.Table_id] || table.Status == models.TableOutOfService {
+			continue
+		}
+		free = append(free, table)
+	}
+	sort.SliceStable(free, func(i, j int) bool {
+		return *free[i].Number_of_quests < *free[j].Number_of_quests
+	})
+	return free
+}
+
+// Seats reports whether table has room for party.
+func Seats(table models.Table, party int) bool {
+	return table.Number_of_quests != nil && *table.Number_of_quests >= party
+}
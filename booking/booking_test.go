@@ -0,0 +1,92 @@
+package booking
+
+import (
+	"reflect"
+	"restaurant_management/models"
+	"testing"
+	"time"
+)
+
+var evening = time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC)
+
+func reservation(id string, tableId string, status string, from time.Duration, to time.Duration) models.Reservation {
+	start := evening.Add(from)
+	return models.Reservation{
+		Reservation_id: id,
+		Table_id:       tableId,
+		Status:         status,
+		Reserved_at:    &start,
+		Ends_at:        evening.Add(to),
+	}
+}
+
+func table(id string, seats int, status string) models.Table {
+	return models.Table{Table_id: id, Number_of_quests: &seats, Status: status}
+}
+
+func TestHolds(t *testing.T) {
+	booked := reservation("r", "t", models.ReservationBooked, 0, 2*time.Hour)
+	tests := []struct {
+		name        string
+		reservation models.Reservation
+		from, to    time.Duration
+		want        bool
+	}{
+		{"same time", booked, 0, 2 * time.Hour, true},
+		{"starts during", booked, time.Hour, 3 * time.Hour, true},
+		{"ends during", booked, -time.Hour, time.Hour, true},
+		{"inside", booked, 30 * time.Minute, 90 * time.Minute, true},
+		{"around", booked, -time.Hour, 3 * time.Hour, true},
+		{"ends as it starts", booked, -2 * time.Hour, 0, false},
+		{"starts as it ends", booked, 2 * time.Hour, 4 * time.Hour, false},
+		{"before", booked, -3 * time.Hour, -time.Hour, false},
+		{"after", booked, 3 * time.Hour, 5 * time.Hour, false},
+		{"cancelled", reservation("r", "t", models.ReservationCancelled, 0, 2*time.Hour), 0, 2 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Holds(tt.reservation, evening.Add(tt.from), evening.Add(tt.to)); got != tt.want {
+				t.Errorf("Holds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeTables(t *testing.T) {
+	tables := []models.Table{
+		table("six", 6, ""),
+		table("two", 2, ""),
+		table("four", 4, ""),
+		table("broken", 4, models.TableOutOfService),
+	}
+	reservations := []models.Reservation{
+		reservation("early", "four", models.ReservationBooked, -2*time.Hour, 0),
+		reservation("late", "six", models.ReservationBooked, time.Hour, 3*time.Hour),
+		reservation("gone", "two", models.ReservationCancelled, 0, 2*time.Hour),
+	}
+	tests := []struct {
+		name     string
+		party    int
+		from, to time.Duration
+		except   string
+		want     []string
+	}{
+		{"smallest first", 2, 0, 30 * time.Minute, "", []string{"two", "four", "six"}},
+		{"too big for small tables", 5, 0, 30 * time.Minute, "", []string{"six"}},
+		{"held table left out", 3, 0, 2 * time.Hour, "", []string{"four"}},
+		{"held by the reservation being moved", 3, 0, 2 * time.Hour, "late", []string{"four", "six"}},
+		{"nothing seats the party", 8, 0, time.Hour, "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			free := FreeTables(tables, reservations, tt.party, evening.Add(tt.from), evening.Add(tt.to), tt.except)
+			got := []string{}
+			for _, table := range free {
+				got = append(got, table.Table_id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FreeTables = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package booking
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config holds how long parties keep their table and when they can book.
+// Times of day are "HH:MM" in the restaurant's timezone.
+type Config struct {
+	// Turn_times lists how long a table is held, by party size. The first
+	// entry whose Max_party fits the party applies.
+	Turn_times []TurnTime `json:"turn_times"`
+	// Default_turn_minutes holds tables for parties larger than every
+	// Turn_times entry.
+	Default_turn_minutes int `json:"default_turn_minutes"`
+	// Slot_minutes is the spacing of the start times availability offers.
+	Slot_minutes int    `json:"slot_minutes"`
+	Opens        string `json:"opens"`
+	Last_seating string `json:"last_seating"`
+	// Timezone is the IANA name used for tenants that set none.
+	Timezone string `json:"timezone"`
+	// Reserved_lead_minutes is how long before a reservation the floor plan
+	// shows its table as reserved.
+	Reserved_lead_minutes int `json:"reserved_lead_minutes"`
+}
+
+type TurnTime struct {
+	Max_party int `json:"max_party"`
+	Minutes   int `json:"minutes"`
+}
+
+// LoadConfig reads a JSON config from path. An empty path yields the
+// defaults: 90 minutes for two, 105 for four, 120 for six and 150 beyond,
+// with a slot every 15 minutes from 11:00 to 21:30 UTC, and tables shown
+// as reserved 30 minutes ahead.
+func LoadConfig(path string) (Config, error) {
+	config := Config{
+		Turn_times: []TurnTime{
+			{Max_party: 2, Minutes: 90},
+			{Max_party: 4, Minutes: 105},
+			{Max_party: 6, Minutes: 120},
+		},
+		Default_turn_minutes:  150,
+		Slot_minutes:          15,
+		Opens:                 "11:00",
+		Last_seating:          "21:30",
+		Timezone:              "UTC",
+		Reserved_lead_minutes: 30,
+	}
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, err
+	}
+	if config.Slot_minutes <= 0 || config.Default_turn_minutes <= 0 {
+		return config, fmt.Errorf("slot_minutes and default_turn_minutes must be positive")
+	}
+	if config.Reserved_lead_minutes < 0 {
+		return config, fmt.Errorf("reserved_lead_minutes must not be negative")
+	}
+	for _, clock := range []string{config.Opens, config.Last_seating} {
+		if _, err := time.Parse("15:04", clock); err != nil {
+			return config, fmt.Errorf("opens and last_seating must be HH:MM: %w", err)
+		}
+	}
+	if _, err := time.LoadLocation(config.Timezone); err != nil {
+		return config, err
+	}
+	return config, nil
+}
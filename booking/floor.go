@@ -0,0 +1,46 @@
+package booking
+
+import (
+	"restaurant_management/models"
+	"time"
+)
+
+// TableStatus returns the live status of table from its orders and the
+// reservations around now. Out of service wins over everything and an
+// order in progress over cleaning; a table is reserved when a booking holds
+// it now or within the configured lead.
+func (c Config) TableStatus(table models.Table, orders []models.Order, reservations []models.Reservation, now time.Time) string {
+	if table.Status == models.TableOutOfService {
+		return models.TableOutOfService
+	}
+	for _, order := range orders {
+		if order.Table_id != nil && *order.Table_id == table.Table_id && Occupies(order) {
+			return models.TableOccupied
+		}
+	}
+	if table.Status == models.TableCleaning {
+		return models.TableCleaning
+	}
+	lead := now.Add(time.Duration(c.Reserved_lead_minutes) * time.Minute)
+	for _, reservation := range reservations {
+		if reservation.Table_id == table.Table_id && Holds(reservation, now, lead) {
+			return models.TableReserved
+		}
+	}
+	return models.TableFree
+}
+
+// NextReservation returns the earliest booked reservation of table that has
+// not ended by now, or nil.
+func NextReservation(table models.Table, reservations []models.Reservation, now time.Time) *models.Reservation {
+	var next *models.Reservation
+	for i, reservation := range reservations {
+		if reservation.Table_id != table.Table_id || reservation.Status != models.ReservationBooked || !reservation.Ends_at.After(now) {
+			continue
+		}
+		if next == nil || reservation.Reserved_at.Before(*next.Reserved_at) {
+			next = &reservations[i]
+		}
+	}
+	return next
+}
@@ -0,0 +1,105 @@
+package booking
+
+import (
+	"restaurant_management/models"
+	"slices"
+	"time"
+)
+
+// Occupies reports whether order still holds its table: it is open, with the
+// kitchen, served or billed but not yet paid.
+func Occupies(order models.Order) bool {
+	return order.Status == "" || slices.Contains(models.OccupyingOrderStatuses, order.Status)
+}
+
+// Turnovers returns, per table, how long its finished orders kept it on
+// average: from the order being opened until it was paid or closed.
+func Turnovers(orders []models.Order) map[string]time.Duration {
+	totals := map[string]time.Duration{}
+	counts := map[string]int{}
+	for _, order := range orders {
+		if order.Table_id == nil {
+			continue
+		}
+		for _, change := range order.Status_history {
+			if change.To != models.OrderStatusPaid && change.To != models.OrderStatusClosed {
+				continue
+			}
+			if held := change.Changed_at.Sub(order.Created_at); held > 0 {
+				totals[*order.Table_id] += held
+				counts[*order.Table_id]++
+			}
+			break
+		}
+	}
+	turnovers := map[string]time.Duration{}
+	for tableId, total := range totals {
+		turnovers[tableId] = total / time.Duration(counts[tableId])
+	}
+	return turnovers
+}
+
+// EstimateWait is how long a party of party arriving at now can expect to
+// wait for one of tables, behind the parties ahead of it. A table that is
+// occupied is expected back once its order has lasted the table's average
+// turnover, or the turn time for its size when it has no history; each party
+// ahead takes the first table that frees up for it. ok is false if no table
+// seats the party at all.
+func (c Config) EstimateWait(tables []models.Table, orders []models.Order, ahead []models.WaitlistEntry, party int, now time.Time) (wait time.Duration, ok bool) {
+	turnovers := Turnovers(orders)
+	hold := func(table models.Table, party int) time.Duration {
+		if turnover, ok := turnovers[table.Table_id]; ok {
+			return turnover
+		}
+		return c.TurnTime(party)
+	}
+
+	freeAt := map[string]time.Time{}
+	for _, table := range tables {
+		freeAt[table.Table_id] = now
+	}
+	for _, order := range orders {
+		if order.Table_id == nil || !Occupies(order) {
+			continue
+		}
+		for _, table := range tables {
+			if table.Table_id != *order.Table_id || table.Number_of_quests == nil {
+				continue
+			}
+			if back := order.Created_at.Add(hold(table, *table.Number_of_quests)); back.After(freeAt[table.Table_id]) {
+				freeAt[table.Table_id] = back
+			}
+		}
+	}
+
+	// first is the table that seats a party soonest, the smallest one on
+	// a tie so that big tables stay free for big parties
+	first := func(party int) (models.Table, bool) {
+		var best models.Table
+		found := false
+		for _, table := range tables {
+			if !Seats(table, party) {
+				continue
+			}
+			if !found || freeAt[table.Table_id].Before(freeAt[best.Table_id]) ||
+				(freeAt[table.Table_id].Equal(freeAt[best.Table_id]) && *table.Number_of_quests < *best.Number_of_quests) {
+				best, found = table, true
+			}
+		}
+		return best, found
+	}
+
+	for _, entry := range ahead {
+		if entry.Party_size == nil {
+			continue
+		}
+		if table, found := first(*entry.Party_size); found {
+			freeAt[table.Table_id] = freeAt[table.Table_id].Add(hold(table, *entry.Party_size))
+		}
+	}
+	table, found := first(party)
+	if !found {
+		return 0, false
+	}
+	return freeAt[table.Table_id].Sub(now), true
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/mail"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyEmail marks the user's email verified given a token mailed to them.
+// A token for an address the user no longer has is refused.
+func VerifyEmail(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Token string `json:"token" validate:"required"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		claims, err := helpers.ValidateVerificationToken(body.Token)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		user, err := store.Users.FindByID(ctx, claims.Uid)
+		if err == repository.ErrNotFound || (err == nil && (user.Email == nil || *user.Email != claims.Email)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": helpers.ErrTokenInvalid.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+
+		if !user.Email_verified {
+			user.Email_verified = true
+			user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			if err := store.Users.Update(ctx, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": user.User_id, "email_verified": true})
+	}
+}
+
+// ResendVerification mails a new verification token. It answers the same
+// whether or not the address belongs to anyone.
+func ResendVerification(store *repository.Store, mailer mail.Sender) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Email string `json:"email" validate:"required,email"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		user, err := store.Users.FindByEmail(ctx, body.Email)
+		if err != nil && err != repository.ErrNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		if err == nil && !user.Email_verified {
+			if err := sendVerification(ctx, mailer, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while sending email"})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "If the address needs verifying, a new link is on its way"})
+	}
+}
+
+// ForgotPassword mails a single-use password reset token. It answers the
+// same whether or not the address belongs to anyone, so it cannot be used to
+// find accounts.
+func ForgotPassword(store *repository.Store, mailer mail.Sender) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Email string `json:"email" validate:"required,email"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		user, err := store.Users.FindByEmail(ctx, body.Email)
+		if err != nil && err != repository.ErrNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		if err == nil {
+			reset, token, err := helpers.NewPasswordReset(user.User_id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating reset token"})
+				return
+			}
+			if err := store.PasswordResets.Create(ctx, reset); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating reset token"})
+				return
+			}
+			err = mailer.Send(ctx, mail.Message{
+				To:      *user.Email,
+				Subject: "Reset your password",
+				Body: "Someone asked to reset the password of your account. If it was you, send this token to POST /users/password/reset\n" +
+					"with your new password within the hour:\n\n" + token + "\n\nIf it was not you, ignore this email.",
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while sending email"})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "If the address has an account, a reset link is on its way"})
+	}
+}
+
+// ResetPassword sets a new password given a reset token. Each token works
+// once and only until it expires.
+func ResetPassword(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Token    string `json:"token" validate:"required"`
+			Password string `json:"password" validate:"required,min=6,max=12"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		reset, err := store.PasswordResets.FindByTokenHash(ctx, helpers.HashResetToken(body.Token))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reset token is invalid or expired"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking reset token"})
+			return
+		}
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if now.After(reset.Expires_at) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reset token is invalid or expired"})
+			return
+		}
+		user, err := store.Users.FindByID(ctx, reset.User_id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reset token is invalid or expired"})
+			return
+		}
+
+		err = store.PasswordResets.MarkUsed(ctx, reset.Reset_id, now)
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reset token was already used"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking reset token"})
+			return
+		}
+
+		password := HashPassword(body.Password)
+		user.Password = &password
+		// the token arrived by mail, which proves the address too
+		user.Email_verified = true
+		user.Updated_at = now
+		if err := store.Users.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+	}
+}
+
+func sendVerification(ctx context.Context, mailer mail.Sender, user models.User) error {
+	token, err := helpers.GenerateVerificationToken(user)
+	if err != nil {
+		return err
+	}
+	return mailer.Send(ctx, mail.Message{
+		To:      *user.Email,
+		Subject: "Verify your email address",
+		Body:    "Welcome " + *user.First_name + "! Confirm this address by sending this token to POST /users/verify within 48 hours:\n\n" + token,
+	})
+}
+
+// logMailError is for mail sent on the side of a request that has already
+// succeeded; the user can ask for it again.
+func logMailError(err error) {
+	if err != nil {
+		log.Printf("sending mail: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func GetAPIKeys(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		keys, err := store.APIKeys.List(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing API keys"})
+			return
+		}
+		c.JSON(http.StatusOK, keys)
+	}
+}
+
+// CreateAPIKey issues a key for a machine client. The key itself is in the
+// response only; afterwards just its prefix is known.
+func CreateAPIKey(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var key models.APIKey
+
+		if err := c.BindJSON(&key); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(key); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if key.Expires_at != nil && !key.Expires_at.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+
+		secret, prefix, hash, err := helpers.GenerateAPIKey()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating API key"})
+			return
+		}
+		key.ID = primitive.NewObjectID()
+		key.Key_id = key.ID.Hex()
+		key.Prefix = prefix
+		key.Key_hash = hash
+		key.Tenant_id = repository.TenantID(ctx)
+		key.Created_by = c.GetString("uid")
+		key.Last_used_at = nil
+		key.Revoked_at = nil
+		key.Created_at = now
+
+		if err := store.APIKeys.Create(ctx, key); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating API key"})
+			return
+		}
+		recordAudit(ctx, store, models.AuditEvent{
+			Type:      models.AuditAPIKeyCreated,
+			Tenant_id: key.Tenant_id,
+			Actor_id:  c.GetString("uid"),
+			Ip:        c.ClientIP(),
+			Detail:    key.Key_id + " " + *key.Name,
+		})
+		c.JSON(http.StatusOK, gin.H{"key": secret, "api_key": key})
+	}
+}
+
+// RevokeAPIKey stops a key from working. Revoked keys stay listed.
+func RevokeAPIKey(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		keyId := c.Param("id")
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		err := store.APIKeys.Revoke(ctx, keyId, now)
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking API key"})
+			return
+		}
+		key, err := store.APIKeys.FindByID(ctx, keyId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking API key"})
+			return
+		}
+		recordAudit(ctx, store, models.AuditEvent{
+			Type:      models.AuditAPIKeyRevoked,
+			Tenant_id: key.Tenant_id,
+			Actor_id:  c.GetString("uid"),
+			Ip:        c.ClientIP(),
+			Detail:    key.Key_id + " " + *key.Name,
+		})
+		c.JSON(http.StatusOK, key)
+	}
+}
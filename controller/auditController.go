@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"restaurant_management/repository"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetAuditEvents(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
+		if err != nil || recordPerPage < 1 {
+			recordPerPage = 10
+		}
+
+		page, err := strconv.Atoi(c.Query("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+
+		startIndex := int64((page - 1) * recordPerPage)
+
+		events, err := store.AuditEvents.List(ctx, repository.Page{Skip: startIndex, Limit: int64(recordPerPage)})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing audit events"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"page":  page,
+			"size":  recordPerPage,
+			"items": events,
+		})
+	}
+}
@@ -3,30 +3,24 @@ package controller
 import (
 	"context"
 	"fmt"
-	"log"
-	"math"
 	"net/http"
-	"restaurant_management/database"
+	"restaurant_management/helpers"
 	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var validate = validator.New()
-var foodCollection *mongo.Collection = database.OpenCollection(database.Client, "food_collection")
 
-// var menuCollection *mongo.Collection = database.OpenCollection(database.Client, "menu_collection")
-
-func GetFoods() gin.HandlerFunc {
+func GetFoods(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
 		if err != nil || recordPerPage < 1 {
@@ -38,47 +32,21 @@ func GetFoods() gin.HandlerFunc {
 		}
 		startIndex := (page - 1) * recordPerPage
 
-		matchStage := bson.D{{Key: "$match", Value: bson.D{{}}}}
-		groupStage := bson.D{{
-			Key: "$group", Value: bson.D{
-				{Key: "_id", Value: nil},
-				{Key: "total_count", Value: bson.D{{Key: "$sum", Value: 1}}},
-				{Key: "data", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
-			},
-		}}
-		projectStage := bson.D{
-			{
-				Key: "$project", Value: bson.D{
-					{Key: "_id", Value: 0},
-					{Key: "total_count", Value: 1},
-					{Key: "food_items", Value: bson.D{
-						{Key: "$slice", Value: []any{"$data", startIndex, recordPerPage}},
-					},
-					},
-				},
-			},
-		}
-
-		result, err := foodCollection.Aggregate(ctx, mongo.Pipeline{matchStage, groupStage, projectStage})
+		foods, total, err := store.Foods.List(ctx, repository.Page{Skip: int64(startIndex), Limit: int64(recordPerPage)})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		var allFoods []bson.M
-		if err = result.All(ctx, &allFoods); err != nil {
-			log.Fatal(err)
-		}
-		c.JSON(http.StatusOK, allFoods)
+		c.JSON(http.StatusOK, []gin.H{{"total_count": total, "food_items": foods}})
 	}
 }
 
-func GetFood() gin.HandlerFunc {
+func GetFood(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		foodId := c.Param("id")
-		var food models.Food
-		err := foodCollection.FindOne(ctx, bson.M{"food_id": foodId}).Decode(&food)
+		food, err := store.Foods.FindByID(ctx, foodId)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -87,11 +55,10 @@ func GetFood() gin.HandlerFunc {
 	}
 }
 
-func CreateFood() gin.HandlerFunc {
+func CreateFood(store *repository.Store, prices pricing.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		var menu models.Menu
 		var food models.Food
 
 		if err := c.BindJSON(&food); err != nil {
@@ -103,7 +70,20 @@ func CreateFood() gin.HandlerFunc {
 			c.JSON(http.StatusBadGateway, gin.H{"error": validationErr.Error()})
 			return
 		}
-		err := menuCollection.FindOne(ctx, bson.M{"menu_id": food.Menu_id}).Decode(&menu)
+		if err := helpers.PrepareModifierGroups(food.Modifier_groups, nil); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		prices, err := tenantPrices(ctx, store, prices)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := helpers.PriceFoodIn(&food, prices.Currency); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		_, err = store.Menus.FindByID(ctx, *food.Menu_id)
 		if err != nil {
 			msg := fmt.Sprintf("Menu was not found")
 			c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
@@ -113,23 +93,20 @@ func CreateFood() gin.HandlerFunc {
 		food.Update_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		food.ID = primitive.NewObjectID()
 		food.Food_id = food.ID.Hex()
-		var num = toFixed(*food.Price, 2)
-		food.Price = &num
-		result, resultError := foodCollection.InsertOne(ctx, food)
+		resultError := store.Foods.Create(ctx, food)
 		if resultError != nil {
 			msg := fmt.Sprintf("Food item was not created")
 			c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
 			return
 		}
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, gin.H{"InsertedID": food.ID})
 	}
 }
 
-func UpdateFood() gin.HandlerFunc {
+func UpdateFood(store *repository.Store, prices pricing.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		var menu models.Menu
 		var food models.Food
 
 		foodId := c.Param("id")
@@ -139,52 +116,80 @@ func UpdateFood() gin.HandlerFunc {
 			return
 		}
 
-		var updateObj primitive.D
+		foundFood, err := store.Foods.FindByID(ctx, foodId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 
 		if food.Name != nil {
-			updateObj = append(updateObj, bson.E{Key: "name", Value: food.Name})
+			foundFood.Name = food.Name
 		}
 
 		if food.Price != nil {
-			updateObj = append(updateObj, bson.E{Key: "price", Value: food.Price})
+			foundFood.Price = food.Price
+		}
+
+		if food.Sizes != nil {
+			validationErr := validate.Var(food.Sizes, "dive")
+			if validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			foundFood.Sizes = food.Sizes
+		}
+
+		if food.Modifier_groups != nil {
+			validationErr := validate.Var(food.Modifier_groups, "dive")
+			if validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			if err := helpers.PrepareModifierGroups(food.Modifier_groups, foundFood.Modifier_groups); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			foundFood.Modifier_groups = food.Modifier_groups
 		}
 
 		if food.Food_image != nil {
-			updateObj = append(updateObj, bson.E{Key: "food_image", Value: food.Food_image})
+			foundFood.Food_image = food.Food_image
+		}
+
+		if food.Station != nil {
+			if err := validate.Var(*food.Station, stationValidation); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown station " + *food.Station})
+				return
+			}
+			foundFood.Station = food.Station
 		}
 
 		if food.Menu_id != nil {
-			err := menuCollection.FindOne(ctx, bson.M{"menu_id": food.Menu_id}).Decode(&menu)
+			_, err := store.Menus.FindByID(ctx, *food.Menu_id)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Menu not found"})
 				return
 			}
-			updateObj = append(updateObj, bson.E{Key: "menu_id", Value: food.Menu_id})
+			foundFood.Menu_id = food.Menu_id
 		}
 
-		food.Update_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: food.Update_at})
-
-		filter := bson.M{"food_id": foodId}
-		upsert := true
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		prices, err := tenantPrices(ctx, store, prices)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := helpers.PriceFoodIn(&foundFood, prices.Currency); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		result, resultErr := foodCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}, &opt)
 
+		foundFood.Update_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		resultErr := store.Foods.Update(ctx, foundFood)
 		if resultErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
 			return
 		}
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, foundFood)
 	}
 }
-
-func round(num float64) int {
-	return int(num + math.Copysign(0.5, num))
-}
-
-func toFixed(num float64, precision int) float64 {
-	output := math.Pow(10, float64(precision))
-	return float64(round(num*output)) / output
-}
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func GetInvoices(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		allInvoices, err := store.Invoices.List(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching invoices"})
+			return
+		}
+		c.JSON(http.StatusOK, allInvoices)
+	}
+}
+
+func GetInvoice(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		invoiceId := c.Param("id")
+		invoice, err := store.Invoices.FindByID(ctx, invoiceId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching invoice"})
+			return
+		}
+
+		allOrderItems, err := store.OrderItems.ItemsByOrder(ctx, *invoice.Order_id)
+		if err != nil {
+			c.JSON(billStatus(err), gin.H{"error": "Error while fetching order items"})
+			return
+		}
+
+		var invoiceView views.InvoiceViewFormat
+		invoiceView.Invoice_id = invoice.Invoice_id
+		invoiceView.Order_id = *invoice.Order_id
+		if invoice.Payment_method != nil {
+			invoiceView.Payment_method = *invoice.Payment_method
+		}
+		if invoice.Payment_status != nil {
+			invoiceView.Payment_status = *invoice.Payment_status
+		}
+		invoiceView.Payment_due = invoice.Payment_due
+		invoiceView.Payment_due_date = invoice.Payment_due_date
+		invoiceView.Pricing = invoice.Pricing
+		if len(allOrderItems) > 0 {
+			invoiceView.Table_number = allOrderItems[0].Table_number
+			invoiceView.Order_details = allOrderItems[0].Order_items
+		}
+		c.JSON(http.StatusOK, invoiceView)
+	}
+}
+
+func CreateInvoice(store *repository.Store, prices pricing.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var invoice models.Invoice
+
+		if err := c.BindJSON(&invoice); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		validationErr := validate.Struct(invoice)
+		if validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if err := paymentFieldsErr(invoice); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		invoice, status, err := createInvoice(ctx, store, prices, invoice, c.GetString("uid"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, invoice)
+	}
+}
+
+// GenerateOrderInvoice invoices the order in the path for the amount its
+// items currently add up to.
+func GenerateOrderInvoice(store *repository.Store, prices pricing.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var invoice models.Invoice
+
+		if c.Request.ContentLength != 0 {
+			if err := c.BindJSON(&invoice); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		orderId := c.Param("id")
+		invoice.Order_id = &orderId
+
+		validationErr := validate.Struct(invoice)
+		if validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if err := paymentFieldsErr(invoice); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		invoice, status, err := createInvoice(ctx, store, prices, invoice, c.GetString("uid"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, invoice)
+	}
+}
+
+// UpdateInvoice refuses to change how an invoice is paid: its payment
+// status follows its payments, which only POST /payments/:id/pay settles.
+// Everything else on an invoice is worked out when it is created.
+func UpdateInvoice(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var invoice models.Invoice
+		invoiceId := c.Param("id")
+
+		if err := c.BindJSON(&invoice); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := paymentFieldsErr(invoice); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		foundInvoice, status, err := findInvoice(ctx, store, invoiceId)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, foundInvoice)
+	}
+}
+
+// paymentFieldsErr reports that invoice sets how it is paid, which only
+// paying its payments may.
+func paymentFieldsErr(invoice models.Invoice) error {
+	if invoice.Payment_method != nil || invoice.Payment_status != nil {
+		return errors.New("payment_method and payment_status are read-only; pay the invoice's payments instead: POST /payments/:id/pay")
+	}
+	return nil
+}
+
+// createInvoice prices invoice.Order_id with the settings of the tenant,
+// records the bill on the invoice and stores it with one pending payment of
+// the whole bill, moving the served order to billed in the same write. On
+// failure it also returns the HTTP status to answer with.
+func createInvoice(ctx context.Context, store *repository.Store, prices pricing.Config, invoice models.Invoice, userId string) (models.Invoice, int, error) {
+	prices, err := tenantPrices(ctx, store, prices)
+	if err != nil {
+		return invoice, http.StatusInternalServerError, err
+	}
+	order, err := store.Orders.FindByID(ctx, *invoice.Order_id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return invoice, http.StatusNotFound, errors.New("order was not found")
+		}
+		return invoice, http.StatusInternalServerError, err
+	}
+	if status := helpers.OrderStatus(order); status != models.OrderStatusServed {
+		return invoice, http.StatusConflict, errors.New("order is " + status + "; only served orders can be invoiced")
+	}
+	if _, err := store.Invoices.FindByOrderID(ctx, *invoice.Order_id); err == nil {
+		return invoice, http.StatusConflict, errors.New("order has already been invoiced")
+	} else if err != repository.ErrNotFound {
+		return invoice, http.StatusInternalServerError, err
+	}
+
+	allOrderItems, err := store.OrderItems.ItemsByOrder(ctx, *invoice.Order_id)
+	if err != nil {
+		return invoice, billStatus(err), err
+	}
+	lines := []views.OrderItemLine{}
+	for _, group := range allOrderItems {
+		lines = append(lines, group.Order_items...)
+	}
+	bill, err := prices.Price(pricingItems(lines), order.Discounts)
+	if err != nil {
+		return invoice, billStatus(err), err
+	}
+	invoice.Pricing = &bill
+	invoice.Payment_due = bill.Total
+
+	status := models.PaymentStatusPending
+	invoice.Payment_status = &status
+	invoice.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	invoice.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	invoice.Payment_due_date = invoice.Created_at.AddDate(0, 0, 1)
+	invoice.ID = primitive.NewObjectID()
+	invoice.Invoice_id = invoice.ID.Hex()
+
+	payment := models.Payment{
+		ID:         primitive.NewObjectID(),
+		Invoice_id: invoice.Invoice_id,
+		Order_id:   *invoice.Order_id,
+		Label:      "Full bill",
+		Amount:     invoice.Payment_due,
+		Status:     models.PaymentStatusPending,
+		Created_at: invoice.Created_at,
+		Updated_at: invoice.Updated_at,
+	}
+	payment.Payment_id = payment.ID.Hex()
+
+	change := models.OrderStatusChange{
+		From:       models.OrderStatusServed,
+		To:         models.OrderStatusBilled,
+		Changed_by: userId,
+		Changed_at: invoice.Created_at,
+	}
+	if err := store.Invoices.Create(ctx, invoice, payment, change); err != nil {
+		if err == repository.ErrConflict {
+			return invoice, http.StatusConflict, errors.New("order changed or was invoiced meanwhile, reload and retry")
+		}
+		return invoice, http.StatusInternalServerError, err
+	}
+	return invoice, http.StatusOK, nil
+}
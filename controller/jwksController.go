@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"net/http"
+	"restaurant_management/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publishes the public keys tokens are verified with, so other
+// services can check our tokens without sharing a secret.
+func GetJWKS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, gin.H{"keys": helpers.SigningKeys.JWKS()})
+	}
+}
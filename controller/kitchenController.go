@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/kitchen"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// streamHeartbeat keeps idle kitchen feeds from being cut by proxies.
+const streamHeartbeat = 30 * time.Second
+
+const stationValidation = "omitempty,eq=kitchen|eq=grill|eq=bar|eq=cold"
+
+func GetTickets(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		station := c.Query("station")
+		if err := validate.Var(station, stationValidation); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown station " + station})
+			return
+		}
+		statuses := c.QueryArray("status")
+		if len(statuses) == 0 {
+			statuses = models.OpenTicketStatuses
+		}
+		for _, status := range statuses {
+			if !helpers.ValidTicketStatus(status) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown ticket status " + status})
+				return
+			}
+		}
+
+		tickets, err := store.Tickets.List(ctx, station, statuses)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing tickets"})
+			return
+		}
+		c.JSON(http.StatusOK, tickets)
+	}
+}
+
+func GetTicket(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		ticket, err := store.Tickets.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ticket was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, ticket)
+	}
+}
+
+// BumpTicket moves a ticket on to its next status.
+func BumpTicket(store *repository.Store, tickets *kitchen.Hub) gin.HandlerFunc {
+	return moveTicket(store, tickets, helpers.BumpTicketStatus)
+}
+
+// RecallTicket moves a ticket back to its previous status, e.g. when a dish
+// was bumped by mistake or sent back.
+func RecallTicket(store *repository.Store, tickets *kitchen.Hub) gin.HandlerFunc {
+	return moveTicket(store, tickets, helpers.RecallTicketStatus)
+}
+
+func moveTicket(store *repository.Store, tickets *kitchen.Hub, next func(string) (string, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		ticket, err := store.Tickets.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ticket was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		status, err := next(ticket.Status)
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		err = store.Tickets.UpdateStatus(ctx, ticket.Ticket_id, ticket.Status, status, now)
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "ticket was updated by someone else, reload and retry"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating ticket"})
+			return
+		}
+
+		ticket.Status = status
+		ticket.Updated_at = now
+		tickets.Publish(ticket)
+		c.JSON(http.StatusOK, ticket)
+	}
+}
+
+// StreamTickets pushes new and updated tickets of the caller's tenant at the
+// station in the query, or at every station, as Server-Sent Events named
+// "ticket".
+func StreamTickets(tickets *kitchen.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		station := c.Query("station")
+		if err := validate.Var(station, stationValidation); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown station " + station})
+			return
+		}
+		feed, unsubscribe := tickets.Subscribe(c.GetString("tenant"), station)
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		c.Header("Cache-Control", "no-cache")
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case ticket, ok := <-feed:
+				if !ok {
+					return false
+				}
+				c.SSEvent("ticket", ticket)
+				return true
+			case <-heartbeat.C:
+				c.SSEvent("heartbeat", time.Now().Unix())
+				return true
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	}
+}
+
+// kitchenTickets makes a queued ticket, sent at at, for every item of order
+// that has none yet. Each goes to the station of its food or else of its
+// food's menu.
+func kitchenTickets(ctx context.Context, store *repository.Store, order models.Order, at time.Time) ([]models.Ticket, error) {
+	items, err := store.OrderItems.ListByOrder(ctx, order.Order_id)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := store.Tickets.ListByOrder(ctx, order.Order_id)
+	if err != nil {
+		return nil, err
+	}
+	ticketed := map[string]bool{}
+	for _, ticket := range existing {
+		ticketed[ticket.Order_item_id] = true
+	}
+	var tableNumber *int
+	if order.Table_id != nil {
+		table, err := store.Tables.FindByID(ctx, *order.Table_id)
+		if err != nil && err != repository.ErrNotFound {
+			return nil, err
+		}
+		tableNumber = table.Table_number
+	}
+
+	newTickets := []models.Ticket{}
+	for _, item := range items {
+		if ticketed[item.Order_item_id] {
+			continue
+		}
+		var food models.Food
+		if item.Food_id != nil {
+			if food, err = store.Foods.FindByID(ctx, *item.Food_id); err != nil && err != repository.ErrNotFound {
+				return nil, err
+			}
+		}
+		var ticket models.Ticket
+		ticket.ID = primitive.NewObjectID()
+		ticket.Ticket_id = ticket.ID.Hex()
+		ticket.Tenant_id = repository.TenantID(ctx)
+		ticket.Order_id = order.Order_id
+		ticket.Order_item_id = item.Order_item_id
+		ticket.Table_number = tableNumber
+		ticket.Station = ticketStation(ctx, store, food)
+		ticket.Food_name = food.Name
+		ticket.Size = item.Size
+		ticket.Quantity = 1
+		if item.Quantity != nil {
+			ticket.Quantity = *item.Quantity
+		}
+		for _, modifier := range item.Modifiers {
+			ticket.Modifiers = append(ticket.Modifiers, modifier.Name)
+		}
+		ticket.Status = models.TicketStatusQueued
+		ticket.Created_at = at
+		ticket.Updated_at = at
+		newTickets = append(newTickets, ticket)
+	}
+	return newTickets, nil
+}
+
+// refreshTickets follows up on items moving onto the order orderId: items
+// without a ticket are sent to the kitchen if the order already was, and
+// the tickets of the order that changed at at are announced on the feed.
+// The move itself has happened, so failures are only logged.
+func refreshTickets(ctx context.Context, store *repository.Store, tickets *kitchen.Hub, orderId string, at time.Time) {
+	order, err := store.Orders.FindByID(ctx, orderId)
+	if err != nil {
+		log.Printf("refreshing the tickets of order %s: %v", orderId, err)
+		return
+	}
+	status := helpers.OrderStatus(order)
+	if status == models.OrderStatusSentToKitchen || status == models.OrderStatusServed {
+		newTickets, err := kitchenTickets(ctx, store, order, at)
+		if err == nil {
+			err = store.Tickets.CreateMany(ctx, newTickets)
+		}
+		if err != nil {
+			log.Printf("sending the items moved onto order %s to the kitchen: %v", orderId, err)
+		}
+	}
+	announceTickets(ctx, store, tickets, orderId, at)
+}
+
+// announceTickets publishes the tickets of the order orderId that changed
+// at at.
+func announceTickets(ctx context.Context, store *repository.Store, tickets *kitchen.Hub, orderId string, at time.Time) {
+	orderTickets, err := store.Tickets.ListByOrder(ctx, orderId)
+	if err != nil {
+		log.Printf("announcing the tickets of order %s: %v", orderId, err)
+		return
+	}
+	for _, ticket := range orderTickets {
+		if ticket.Updated_at.Equal(at) {
+			tickets.Publish(ticket)
+		}
+	}
+}
+
+func ticketStation(ctx context.Context, store *repository.Store, food models.Food) string {
+	if food.Station != nil && *food.Station != "" {
+		return *food.Station
+	}
+	if food.Menu_id != nil {
+		menu, err := store.Menus.FindByID(ctx, *food.Menu_id)
+		if err == nil && menu.Station != "" {
+			return menu.Station
+		}
+	}
+	return models.StationKitchen
+}
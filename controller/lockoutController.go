@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UnlockUser clears the failed-login counter and any lockout of a user's
+// account. The lockout of the IP addresses involved is left to expire.
+func UnlockUser(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		user, err := findTenantUser(ctx, store, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+
+		if err := store.LoginThrottles.Clear(ctx, helpers.AccountThrottleKey(*user.Email)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while unlocking user"})
+			return
+		}
+		recordAudit(ctx, store, models.AuditEvent{
+			Type:      models.AuditAccountUnlocked,
+			Tenant_id: user.Tenant_id,
+			User_id:   user.User_id,
+			Actor_id:  c.GetString("uid"),
+			Ip:        c.ClientIP(),
+		})
+		c.JSON(http.StatusOK, gin.H{"user_id": user.User_id, "locked": false})
+	}
+}
+
+// loginAllowed answers 429 with Retry-After and returns false if either key
+// is locked out or the account is still waiting out its delay.
+func loginAllowed(ctx context.Context, store *repository.Store, c *gin.Context, accountKey string, ipKey string) bool {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	for _, key := range []string{accountKey, ipKey} {
+		throttle, err := store.LoginThrottles.Find(ctx, key)
+		if err == repository.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking login attempts"})
+			return false
+		}
+		if wait := helpers.LoginRetryAfter(throttle, now, key == accountKey); wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed logins, try again later"})
+			return false
+		}
+	}
+	return true
+}
+
+// recordLoginFailure counts a failed login against key and locks the key
+// out once it reaches limit failures. user is empty for unknown emails.
+func recordLoginFailure(ctx context.Context, store *repository.Store, c *gin.Context, key string, limit int, lockEvent string, user models.User) error {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	throttle, err := store.LoginThrottles.Find(ctx, key)
+	if err != nil && err != repository.ErrNotFound {
+		return err
+	}
+	throttle, err = store.LoginThrottles.RecordFailure(ctx, key, now, helpers.LoginFailuresSince(throttle, now))
+	if err != nil {
+		return err
+	}
+	if throttle.Failures < limit {
+		return nil
+	}
+	if err := store.LoginThrottles.Lock(ctx, key, now.Add(helpers.LoginLockout)); err != nil {
+		return err
+	}
+	if throttle.Failures == limit {
+		recordAudit(ctx, store, models.AuditEvent{
+			Type:      lockEvent,
+			Tenant_id: user.Tenant_id,
+			User_id:   user.User_id,
+			Ip:        c.ClientIP(),
+			Detail:    key + " locked after " + strconv.Itoa(throttle.Failures) + " failed logins",
+		})
+	}
+	return nil
+}
+
+// recordAudit stores an audit event. A failure to record must not undo the
+// action being audited, so errors are only logged.
+func recordAudit(ctx context.Context, store *repository.Store, event models.AuditEvent) {
+	event.ID = primitive.NewObjectID()
+	event.Event_id = event.ID.Hex()
+	event.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	if err := store.AuditEvents.Create(ctx, event); err != nil {
+		log.Printf("recording audit event %s: %v", event.Type, err)
+	}
+}
@@ -2,45 +2,34 @@ package controller
 
 import (
 	"context"
-	"log"
 	"net/http"
-	"restaurant_management/database"
 	"restaurant_management/models"
+	"restaurant_management/repository"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var menuCollection *mongo.Collection = database.OpenCollection(database.Client, "menu_collection")
-
-func GetMenus() gin.HandlerFunc {
+func GetMenus(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		result, err := menuCollection.Find(context.TODO(), bson.M{})
+		allMenus, err := store.Menus.List(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while retriving menu"})
 			return
 		}
-		var allMenus []bson.M
-		if err = result.All(ctx, &allMenus); err != nil {
-			log.Fatal(err)
-		}
 		c.JSON(http.StatusOK, allMenus)
 	}
 }
 
-func GetMenu() gin.HandlerFunc {
+func GetMenu(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		menu_id := c.Param("id")
 		defer cancel()
-		var menu models.Menu
-		err := menuCollection.FindOne(ctx, bson.M{"menu_id": menu_id}).Decode(&menu)
+		menu, err := store.Menus.FindByID(ctx, menu_id)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while retriving menu."})
 			return
@@ -50,10 +39,10 @@ func GetMenu() gin.HandlerFunc {
 	}
 }
 
-func CreateMenu() gin.HandlerFunc {
+func CreateMenu(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var menu models.Menu
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		if err := c.BindJSON(&menu); err != nil {
 			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
@@ -69,19 +58,19 @@ func CreateMenu() gin.HandlerFunc {
 		menu.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		menu.Menu_id = menu.ID.Hex()
 
-		result, insertErr := menuCollection.InsertOne(ctx, menu)
+		insertErr := store.Menus.Create(ctx, menu)
 		if insertErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Menu item not created"})
 			return
 		}
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, gin.H{"InsertedID": menu.ID})
 
 	}
 }
 
-func UpdateMenu() gin.HandlerFunc {
+func UpdateMenu(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		var menu models.Menu
 		if err := c.BindJSON(&menu); err != nil {
@@ -89,9 +78,6 @@ func UpdateMenu() gin.HandlerFunc {
 			return
 		}
 		menuId := c.Param("id")
-		filter := bson.M{"menu_id": menuId}
-
-		var updateObj primitive.D
 
 		if menu.Start_Date != nil && menu.End_Date != nil {
 			if !inTimeSpan(*menu.Start_Date, *menu.End_Date) {
@@ -101,27 +87,35 @@ func UpdateMenu() gin.HandlerFunc {
 			}
 		}
 
-		updateObj = append(updateObj, bson.E{Key: "start_date", Value: menu.Start_Date})
-		updateObj = append(updateObj, bson.E{Key: "end_date", Value: menu.End_Date})
+		foundMenu, err := store.Menus.FindByID(ctx, menuId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while retriving menu."})
+			return
+		}
+
+		foundMenu.Start_Date = menu.Start_Date
+		foundMenu.End_Date = menu.End_Date
 
 		if menu.Name != "" {
-			updateObj = append(updateObj, bson.E{Key: "name", Value: menu.Name})
+			foundMenu.Name = menu.Name
 		}
 		if menu.Category != "" {
-			updateObj = append(updateObj, bson.E{Key: "category", Value: menu.Category})
+			foundMenu.Category = menu.Category
 		}
-		menu.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{Key: "update_at", Value: menu.Updated_at})
-		upsert := true
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		if menu.Station != "" {
+			if err := validate.Var(menu.Station, stationValidation); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown station " + menu.Station})
+				return
+			}
+			foundMenu.Station = menu.Station
 		}
-		result, resultErr := menuCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}, &opt)
+		foundMenu.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		resultErr := store.Menus.Update(ctx, foundMenu)
 		if resultErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
 			return
 		}
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, foundMenu)
 	}
 }
 
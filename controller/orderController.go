@@ -6,28 +6,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"restaurant_management/database"
+	"restaurant_management/helpers"
+	"restaurant_management/kitchen"
+	"restaurant_management/middleware"
 	"restaurant_management/models"
+	"restaurant_management/repository"
 )
 
-var orderCollection *mongo.Collection = database.OpenCollection(database.Client, "food")
-
-func GetOrders() gin.HandlerFunc {
+func GetOrders(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		result, err := orderCollection.Find(context.TODO(), bson.M{})
+		allOrders, err := store.Orders.List(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching orders"})
-		}
-		var allOrders []bson.M
-		if err := result.All(ctx, &allOrders); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the data"})
 			return
 		}
 		c.JSON(http.StatusOK, allOrders)
@@ -35,13 +29,12 @@ func GetOrders() gin.HandlerFunc {
 	}
 }
 
-func GetOrder() gin.HandlerFunc {
+func GetOrder(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		orderId := c.Param("id")
-		var order models.Order
-		err := orderCollection.FindOne(ctx, bson.M{"order_id": orderId}).Decode(&order)
+		order, err := store.Orders.FindByID(ctx, orderId)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -50,11 +43,10 @@ func GetOrder() gin.HandlerFunc {
 	}
 }
 
-func CreateOrder() gin.HandlerFunc {
+func CreateOrder(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		var table models.Table
 		var order models.Order
 
 		if err := c.BindJSON(&order); err != nil {
@@ -66,9 +58,12 @@ func CreateOrder() gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": validateErr.Error()})
 			return
 		}
+		if order.Discounts != nil && !middleware.Require(c, helpers.BillingRoles...) {
+			return
+		}
 
 		if order.Table_id != nil {
-			err := tableCollection.FindOne(ctx, bson.M{"table_id": order.Table_id}).Decode(&table)
+			_, err := store.Tables.FindByID(ctx, *order.Table_id)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Table id is missing"})
 				return
@@ -80,67 +75,188 @@ func CreateOrder() gin.HandlerFunc {
 
 		order.ID = primitive.NewObjectID()
 		order.Order_id = order.ID.Hex()
+		openOrder(&order, c.GetString("uid"))
 
-		insert, insertErr := orderCollection.InsertOne(ctx, order)
+		insertErr := store.Orders.Create(ctx, order)
 		if insertErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": insertErr.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, insert)
+		c.JSON(http.StatusOK, gin.H{"InsertedID": order.ID})
 
 	}
 }
 
-func UpdateOrder() gin.HandlerFunc {
+func UpdateOrder(store *repository.Store, tickets *kitchen.Hub) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		var table models.Table
 		var order models.Order
 
-		var updateObj primitive.D
-
 		orderId := c.Param("id")
 
 		if err := c.BindJSON(&order); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		if order.Discounts != nil && !middleware.Require(c, helpers.BillingRoles...) {
+			return
+		}
 
-		if order.Table_id != nil {
-			err := tableCollection.FindOne(ctx, bson.M{"table_id": order.Table_id}).Decode(&table)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error})
+		foundOrder, err := store.Orders.FindByID(ctx, orderId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if order.Table_id != nil && (foundOrder.Table_id == nil || *order.Table_id != *foundOrder.Table_id) {
+			if !helpers.OrderEditable(foundOrder) {
+				c.JSON(http.StatusConflict, gin.H{"error": "order is " + helpers.OrderStatus(foundOrder) + " and can no longer change table"})
+				return
+			}
+			if status, err := checkTransfer(ctx, store, foundOrder, *order.Table_id); err != nil {
+				c.JSON(status, gin.H{"error": err.Error()})
+				return
+			}
+			foundOrder.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			if !moveOrder(ctx, store, tickets, c, foundOrder, *order.Table_id, foundOrder.Updated_at) {
+				return
 			}
-			updateObj = append(updateObj, bson.E{Key: "table_id", Value: order.Table_id})
+			foundOrder.Table_id = order.Table_id
+		}
+		if order.Discounts != nil {
+			// the invoice snapshots the discounts it was billed with
+			if !helpers.OrderEditable(foundOrder) {
+				c.JSON(http.StatusConflict, gin.H{"error": "order is " + helpers.OrderStatus(foundOrder) + " and its discounts can no longer change"})
+				return
+			}
+			for _, discount := range order.Discounts {
+				if validationErr := validate.Struct(discount); validationErr != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+					return
+				}
+			}
+			foundOrder.Discounts = order.Discounts
+		}
+		foundOrder.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		err = store.Orders.Update(ctx, foundOrder)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, foundOrder)
+	}
+}
+
+// UpdateOrderStatus moves an order to the status in the body. Sending it to
+// the kitchen queues a ticket for each of its items; cancelling or voiding
+// it voids the tickets the kitchen has not served yet.
+func UpdateOrderStatus(store *repository.Store, tickets *kitchen.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Status *string `json:"status" validate:"required"`
+		}
+
+		orderId := c.Param("id")
+
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		order, err := store.Orders.FindByID(ctx, orderId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		from := helpers.OrderStatus(order)
+		if err := helpers.ValidateOrderTransition(from, *body.Status); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if roles := helpers.OrderStatusRoles(*body.Status); roles != nil && !middleware.Require(c, roles...) {
+			return
+		}
+		if *body.Status == models.OrderStatusBilled {
+			// the invoice and the billed status are written together
+			c.JSON(http.StatusConflict, gin.H{"error": "orders are billed by invoicing them: POST /orders/:id/invoice"})
+			return
+		}
+		if *body.Status == models.OrderStatusPaid {
+			// the order is paid with the last payment of its invoice
+			c.JSON(http.StatusConflict, gin.H{"error": "orders are paid by paying their invoice: POST /payments/:id/pay"})
+			return
 		}
-		order.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: order.Updated_at})
 
-		upsert := true
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		change := models.OrderStatusChange{
+			From:       from,
+			To:         *body.Status,
+			Changed_by: c.GetString("uid"),
 		}
-		filter := bson.M{"order_id": orderId}
-		result, err := orderCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}, &opt)
+		change.Changed_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if change.To == models.OrderStatusSentToKitchen {
+			newTickets, ticketErr := kitchenTickets(ctx, store, order, change.Changed_at)
+			if ticketErr != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while sending items to the kitchen"})
+				return
+			}
+			err = store.Orders.SendToKitchen(ctx, orderId, change, newTickets)
+		} else {
+			err = store.Orders.UpdateStatus(ctx, orderId, change)
+		}
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Order status changed meanwhile, reload and retry"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		announceTickets(ctx, store, tickets, orderId, change.Changed_at)
+
+		order, err = store.Orders.FindByID(ctx, orderId)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, order)
 	}
 }
 
-func OrderItemOrderCreator(order models.Order) string {
+// openOrder puts a new order in the open status and starts its history.
+func openOrder(order *models.Order, userId string) {
+	order.Status = models.OrderStatusOpen
+	order.Status_history = []models.OrderStatusChange{{
+		To:         models.OrderStatusOpen,
+		Changed_by: userId,
+		Changed_at: order.Created_at,
+	}}
+}
+
+func OrderItemOrderCreator(ctx context.Context, store *repository.Store, order models.Order, userId string) (string, error) {
+	order = newOrder(order, userId)
+	if err := store.Orders.Create(ctx, order); err != nil {
+		return "", err
+	}
+	return order.Order_id, nil
+
+}
+
+// newOrder gives order an id and its timestamps and opens it.
+func newOrder(order models.Order, userId string) models.Order {
 	order.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	order.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	order.ID = primitive.NewObjectID()
 	order.Order_id = order.ID.Hex()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-	orderCollection.InsertOne(ctx, order)
-	defer cancel()
-
-	return order.Order_id
-
+	openOrder(&order, userId)
+	return order
 }
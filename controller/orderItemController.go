@@ -2,16 +2,19 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"restaurant_management/database"
+	"restaurant_management/helpers"
 	"restaurant_management/models"
+	"restaurant_management/money"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type OrderItemPack struct {
@@ -19,34 +22,27 @@ type OrderItemPack struct {
 	Order_items []models.OrderItem `json:"order_items"`
 }
 
-var orderItemCollection *mongo.Collection = database.OpenCollection(database.Client, "orderItem")
-
-func GetOrderItems() gin.HandlerFunc {
+func GetOrderItems(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		var ctx, cancel = context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		result, err := orderItemCollection.Find(ctx, bson.M{})
+		allOrderItems, err := store.OrderItems.List(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while retriving orderItems"})
 			return
 		}
-		var allOrderItems []bson.M
-		if err := result.All(ctx, &allOrderItems); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
 		c.JSON(http.StatusOK, allOrderItems)
 	}
 }
 
-func GetOrderItem() gin.HandlerFunc {
+func GetOrderItem(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		orderItemId := c.Param("id")
-		var orderItem models.OrderItem
 
-		if err := orderItemCollection.FindOne(ctx, bson.M{"order_item_id": orderItemId}).Decode(&orderItem); err != nil {
+		orderItem, err := store.OrderItems.FindByID(ctx, orderItemId)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
 			return
 		}
@@ -54,13 +50,12 @@ func GetOrderItem() gin.HandlerFunc {
 	}
 }
 
-func CreateOrderItem() gin.HandlerFunc {
+func CreateOrderItem(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 
 		var orderItemPack OrderItemPack
-		var order models.Order
 
 		if err := c.BindJSON(&orderItemPack); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -73,22 +68,46 @@ func CreateOrderItem() gin.HandlerFunc {
 			return
 		}
 
-		order.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		table, err := store.Tables.FindByID(ctx, *orderItemPack.Table_id)
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "table was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if table.Status == models.TableOutOfService {
+			c.JSON(http.StatusConflict, gin.H{"error": "table is out of service"})
+			return
+		}
 
-		orderItemsToBeInserted := []any{}
-		order.Table_id = orderItemPack.Table_id
-		order_id := OrderItemOrderCreator(order)
+		// the order is only stored together with its items, once every
+		// item checks out
+		order := newOrder(models.Order{Table_id: orderItemPack.Table_id}, c.GetString("uid"))
 
+		orderItemsToBeInserted := []models.OrderItem{}
 		validationErrItem := []any{}
+		modifierErrs := []string{}
 		for _, item := range orderItemPack.Order_items {
-			item.Order_id = order_id
-
-			validationErr := validate.Struct(item)
+			item.Order_id = order.Order_id
 
-			if validationErr != nil {
+			if validationErr := validate.Struct(item); validationErr != nil {
 				validationErrItem = append(validationErrItem, item)
+			} else if checked, _, status, err := checkOrderItem(ctx, store, item, true); status == http.StatusBadRequest {
+				validationErrItem = append(validationErrItem, item)
+				modifierErrs = append(modifierErrs, err.Error())
+			} else if err != nil {
+				c.JSON(status, gin.H{"error": err.Error()})
+				return
+			} else {
+				item = checked
 			}
 
+			if item.Quantity == nil {
+				quantity := 1
+				item.Quantity = &quantity
+			}
 			item.ID = primitive.NewObjectID()
 			item.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 			item.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
@@ -96,21 +115,26 @@ func CreateOrderItem() gin.HandlerFunc {
 			orderItemsToBeInserted = append(orderItemsToBeInserted, item)
 		}
 		if len(validationErrItem) >= 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create order", "items": validationErrItem})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create order", "items": validationErrItem, "reasons": modifierErrs})
 			return
 		}
-		result, err := orderItemCollection.InsertMany(ctx, orderItemsToBeInserted)
+		err = store.Orders.Create(ctx, order, orderItemsToBeInserted...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while inserting records"})
 			return
 		}
-		c.JSON(http.StatusOK, result)
+
+		insertedIds := []any{}
+		for _, item := range orderItemsToBeInserted {
+			insertedIds = append(insertedIds, item.ID)
+		}
+		c.JSON(http.StatusOK, gin.H{"InsertedIDs": insertedIds})
 	}
 }
 
-func UpdateOrderItem() gin.HandlerFunc {
+func UpdateOrderItem(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		var orderItem models.OrderItem
 		orderItemId := c.Param("id")
@@ -120,206 +144,179 @@ func UpdateOrderItem() gin.HandlerFunc {
 			return
 		}
 
-		var updateObj bson.D
+		validationErr := validate.StructPartial(orderItem, "Size", "Quantity", "Seat")
+		if validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		foundOrderItem, err := store.OrderItems.FindByID(ctx, orderItemId)
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order item was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		order, err := store.Orders.FindByID(ctx, foundOrderItem.Order_id)
+		if err != nil && err != repository.ErrNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !slices.Contains(models.ItemEditableOrderStatuses, helpers.OrderStatus(order)) {
+			c.JSON(http.StatusConflict, gin.H{"error": "order is " + helpers.OrderStatus(order) + "; its items can no longer be edited"})
+			return
+		}
+
+		// the modifiers are only looked up again, at the food's current
+		// prices, when the food or the choice of modifiers changes; otherwise
+		// the item keeps what was ordered
+		reselected := orderItem.Food_id != nil && (foundOrderItem.Food_id == nil || *orderItem.Food_id != *foundOrderItem.Food_id) ||
+			orderItem.Modifiers != nil && !helpers.SameSelection(orderItem.Modifiers, foundOrderItem.Modifiers)
+		resized := orderItem.Size != nil && (foundOrderItem.Size == nil || *orderItem.Size != *foundOrderItem.Size)
+
+		if orderItem.Size != nil {
+			foundOrderItem.Size = orderItem.Size
+		}
 		if orderItem.Quantity != nil {
-			updateObj = append(updateObj, bson.E{Key: "quantity", Value: orderItem.Quantity})
+			foundOrderItem.Quantity = orderItem.Quantity
+		}
+		if orderItem.Seat != nil {
+			foundOrderItem.Seat = orderItem.Seat
 		}
 		if orderItem.Food_id != nil {
-			updateObj = append(updateObj, bson.E{Key: "food_id", Value: orderItem.Food_id})
+			foundOrderItem.Food_id = orderItem.Food_id
 		}
-		orderItem.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{Key: "update_at", Value: orderItem.Updated_at})
-
-		upsert := true
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		if reselected && orderItem.Modifiers != nil {
+			foundOrderItem.Modifiers = orderItem.Modifiers
 		}
-		filter := bson.M{"order_item_id": orderItemId}
+		// the food, size and modifiers are checked again as a whole, since
+		// a new food may not offer the size or modifiers kept from the old one
+		if reselected || resized {
+			var status int
+			foundOrderItem, _, status, err = checkOrderItem(ctx, store, foundOrderItem, reselected)
+			if err != nil {
+				c.JSON(status, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		foundOrderItem.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-		update, updateErr := orderItemCollection.UpdateOne(
-			ctx,
-			filter,
-			bson.D{
-				{Key: "$set", Value: updateObj},
-			},
-			&opt,
-		)
+		updateErr := store.OrderItems.Update(ctx, foundOrderItem)
+		if updateErr == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "order moved on meanwhile; its items can no longer be edited"})
+			return
+		}
 		if updateErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while update data"})
 			return
 		}
-		c.JSON(http.StatusOK, update)
+		c.JSON(http.StatusOK, foundOrderItem)
+	}
+}
+
+// checkOrderItem checks that the food of item exists and offers the chosen
+// size and, when reselect is set, modifiers. It returns item with the name
+// and price of its modifiers filled in, along with its food; without
+// reselect the modifiers are kept as they are. A 400 status means item is
+// at fault.
+func checkOrderItem(ctx context.Context, store *repository.Store, item models.OrderItem, reselect bool) (models.OrderItem, models.Food, int, error) {
+	if item.Food_id == nil {
+		return item, models.Food{}, http.StatusBadRequest, errors.New("food_id is required")
+	}
+	food, err := store.Foods.FindByID(ctx, *item.Food_id)
+	if err == repository.ErrNotFound {
+		return item, food, http.StatusBadRequest, errors.New("food " + *item.Food_id + " was not found")
+	}
+	if err != nil {
+		return item, food, http.StatusInternalServerError, err
 	}
+	if err := helpers.CheckSize(food, item.Size); err != nil {
+		return item, food, http.StatusBadRequest, err
+	}
+	if !reselect {
+		return item, food, http.StatusOK, nil
+	}
+	if item.Modifiers, err = helpers.SelectModifiers(food, item.Modifiers); err != nil {
+		return item, food, http.StatusBadRequest, err
+	}
+	return item, food, http.StatusOK, nil
 }
 
-func GetOrderItemsByOrderId() gin.HandlerFunc {
+func GetOrderItemsByOrderId(store *repository.Store, prices pricing.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
 		orderId := c.Param("id")
-		allOrderItems, err := ItemsByOrder(orderId)
+		allOrderItems, err := pricedItemsByOrder(ctx, store, prices, orderId)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching data"})
+			c.JSON(billStatus(err), gin.H{"error": "Error while fetching data: " + err.Error()})
 			return
 		}
 		c.JSON(http.StatusOK, allOrderItems)
 	}
 }
 
-func ItemsByOrder(id string) (orderItems []bson.M, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-	defer cancel()
-
-	// match
-	matchStage := bson.D{
-		{
-			Key: "$match", Value: bson.D{
-				{Key: "order_id", Value: id},
-			},
-		},
-	}
-
-	// food
-	lookupFoodStage := bson.D{
-		{
-			Key: "$lookup", Value: bson.D{
-				{Key: "from", Value: "food"},
-				{Key: "localField", Value: "food_id"},
-				{Key: "foreignField", Value: "food_id"},
-				{Key: "as", Value: "food"},
-			},
-		},
-	}
-	unwinFoodStage := bson.D{
-		{
-			Key: "$unwind", Value: bson.D{
-				{Key: "path", Value: "$food"},
-				{Key: "preserveNullAndEmptyArrays", Value: true},
-			},
-		},
-	}
-
-	// order
-	lookupOrderStage := bson.D{
-		{
-			Key: "$lookup", Value: bson.D{
-				{Key: "from", Value: "order"},
-				{Key: "localField", Value: "order_id"},
-				{Key: "foreignField", Value: "order_id"},
-				{Key: "as", Value: "order"},
-			},
-		},
-	}
-	unwindOrderStage := bson.D{
-		{
-			Key: "$unwind", Value: bson.D{
-				{Key: "path", Value: "$order"},
-				{Key: "preserveNullAndEmptyArrays", Value: true},
-			},
-		},
-	}
-
-	// table
-	lookupTableStage := bson.D{
-		{
-			Key: "$lookup", Value: bson.D{
-				{Key: "from", Value: "table"},
-				{Key: "localField", Value: "order.table_id"},
-				{Key: "foreignField", Value: "table_id"},
-				{Key: "as", Value: "table"},
-			},
-		},
-	}
-	unwindTableStage := bson.D{
-		{
-			Key: "$unwind", Value: bson.D{
-				{Key: "path", Value: "$table"},
-				{Key: "preserveNullAndEmptyArrays", Value: true},
-			},
-		},
+// pricedItemsByOrder runs ItemsByOrder and prices every group, replacing
+// the plain sum in Payment_due with the bill total.
+func pricedItemsByOrder(ctx context.Context, store *repository.Store, prices pricing.Config, orderId string) ([]views.OrderItemsView, error) {
+	prices, err := tenantPrices(ctx, store, prices)
+	if err != nil {
+		return nil, err
 	}
-
-	// project1
-	projectStage1 := bson.D{
-		{
-			Key: "$project", Value: bson.D{
-				{Key: "_id", Value: 0},
-				{Key: "amount", Value: "$food.price"},
-				{Key: "total_count", Value: 1},
-				{Key: "food_name", Value: "$food.name"},
-				{Key: "food_image", Value: "$food.food_image"},
-				{Key: "table_number", Value: "$table.table_number"},
-				{Key: "table_id", Value: "$table.table_id"},
-				{Key: "order_id", Value: "$order.order_id"},
-				{Key: "price", Value: "$food.price"},
-				{Key: "quantity", Value: 1},
-			},
-		},
+	allOrderItems, err := store.OrderItems.ItemsByOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
 	}
-
-	// group
-	groupStage := bson.D{
-		{Key: "$group", Value: bson.D{
-			{
-				Key: "_id", Value: bson.D{
-					{Key: "order_id", Value: "$order_id"},
-					{Key: "table_id", Value: "$table_id"},
-					{Key: "table_number", Value: "$table_number"},
-				},
-			},
-			{
-				Key: "payment_due", Value: bson.D{
-					{Key: "$sum", Value: "$amount"},
-				},
-			},
-			{
-				Key: "total_count", Value: bson.D{
-					{Key: "$sum", Value: 1},
-				},
-			},
-			{
-				Key: "order_items", Value: bson.D{
-					{Key: "$push", Value: "$$ROOT"},
-				},
-			},
-		},
-		},
+	order, err := store.Orders.FindByID(ctx, orderId)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, err
 	}
 
-	// project2
-	projectStage2 := bson.D{
-		{
-			Key: "$project", Value: bson.D{
-				{Key: "id", Value: 1},
-				{Key: "payment_due", Value: 1},
-				{Key: "total_count", Value: 1},
-				{Key: "table_number", Value: "$_id.table_number"},
-				{Key: "order_items", Value: 1},
-			},
-		},
+	for i := range allOrderItems {
+		bill, err := prices.Price(pricingItems(allOrderItems[i].Order_items), order.Discounts)
+		if err != nil {
+			return nil, err
+		}
+		allOrderItems[i].Pricing = &bill
+		allOrderItems[i].Payment_due = bill.Total
 	}
+	return allOrderItems, nil
+}
 
-	// Aggregate
-	result, err := orderItemCollection.Aggregate(
-		ctx,
-		mongo.Pipeline{
-			matchStage,
-			lookupFoodStage,
-			unwinFoodStage,
-			lookupOrderStage,
-			unwindOrderStage,
-			lookupTableStage,
-			unwindTableStage,
-			projectStage1,
-			groupStage,
-			projectStage2,
-		},
-	)
-
-	if err != nil {
-		panic(err)
+// billStatus is the status to answer with when an order cannot be priced.
+// Amounts in another currency than the bill conflict with the settings of
+// the tenant; anything else is a server error.
+func billStatus(err error) int {
+	if errors.Is(err, money.ErrCurrencyMismatch) {
+		return http.StatusConflict
 	}
+	return http.StatusInternalServerError
+}
 
-	if err := result.All(ctx, &orderItems); err != nil {
-		panic(err)
+func pricingItems(lines []views.OrderItemLine) []pricing.Item {
+	items := []pricing.Item{}
+	for _, line := range lines {
+		item := pricing.Item{
+			Order_item_id: line.Order_item_id,
+			Seat:          line.Seat,
+			Category:      line.Menu_category,
+			Quantity:      line.Quantity,
+		}
+		if line.Food_name != nil {
+			item.Name = *line.Food_name
+		}
+		if line.Size != nil {
+			item.Size = *line.Size
+		}
+		for _, modifier := range line.Modifiers {
+			item.Modifiers = append(item.Modifiers, modifier.Name)
+		}
+		if line.Price != nil {
+			item.Unit_price = *line.Price
+		}
+		items = append(items, item)
 	}
-	return
+	return items
 }
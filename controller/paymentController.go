@@ -0,0 +1,426 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"restaurant_management/money"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// share is one part of a bill being split, before it is priced. Its weight
+// only tells its part of the bill, so it is added up whatever the currency.
+type share struct {
+	label          string
+	seat           *int
+	order_item_ids []string
+	weight         money.Money
+}
+
+// GetInvoicePayments returns how far the bill of an invoice is settled.
+func GetInvoicePayments(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		invoice, status, err := findInvoice(ctx, store, c.Param("id"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		settled, err := settlement(ctx, store, invoice)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching payments"})
+			return
+		}
+		c.JSON(http.StatusOK, settled)
+	}
+}
+
+// SplitInvoice splits what is left to pay on an invoice into shares: "even"
+// in "ways" equal parts, by "seat" of the order items, or by "items", one
+// share per list of order item ids plus one for any items left out. Items
+// are weighted by their line total on the invoice, and items without a seat
+// are shared equally between the seats. Splitting again replaces the
+// pending shares; once a share is paid only the balance can be split, and
+// only evenly. Only invoices of billed orders can be split.
+func SplitInvoice(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Mode  string     `json:"mode" validate:"required,oneof=even seat items"`
+			Ways  int        `json:"ways" validate:"required_if=Mode even,omitempty,min=2,max=50"`
+			Items [][]string `json:"items" validate:"required_if=Mode items,omitempty,min=1,dive,min=1,dive,required"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		invoice, status, err := findInvoice(ctx, store, c.Param("id"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if _, status, err := billedOrder(ctx, store, invoice); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		settled, err := settlement(ctx, store, invoice)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching payments"})
+			return
+		}
+		if settled.Balance.Amount <= 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "invoice is already settled"})
+			return
+		}
+		paid := 0
+		for _, payment := range settled.Payments {
+			if payment.Status == models.PaymentStatusPaid {
+				paid++
+			}
+		}
+		if body.Mode != models.SplitEven && paid > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "part of the bill is paid; split the balance evenly"})
+			return
+		}
+
+		var shares []share
+		if body.Mode == models.SplitEven {
+			for i := 1; i <= body.Ways; i++ {
+				shares = append(shares, share{
+					label:  strconv.Itoa(i) + " of " + strconv.Itoa(body.Ways),
+					weight: money.New(1, ""),
+				})
+			}
+		} else {
+			lines, err := invoiceLines(invoice)
+			if err != nil {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if body.Mode == models.SplitSeat {
+				shares, err = seatShares(lines)
+			} else {
+				shares, err = itemShares(lines, body.Items)
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		weights := []money.Money{}
+		for _, share := range shares {
+			weights = append(weights, share.weight)
+		}
+		amounts, err := pricing.Split(settled.Balance, weights)
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		payments := []models.Payment{}
+		for i, amount := range amounts {
+			payment := models.Payment{
+				ID:             primitive.NewObjectID(),
+				Invoice_id:     invoice.Invoice_id,
+				Order_id:       *invoice.Order_id,
+				Label:          shares[i].label,
+				Seat:           shares[i].seat,
+				Order_item_ids: shares[i].order_item_ids,
+				Amount:         amount,
+				Status:         models.PaymentStatusPending,
+				Created_at:     now,
+				Updated_at:     now,
+			}
+			payment.Payment_id = payment.ID.Hex()
+			payments = append(payments, payment)
+		}
+
+		err = store.Payments.Split(ctx, invoice.Invoice_id, paid, payments, now)
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "A share was paid meanwhile, reload and retry"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while splitting the bill"})
+			return
+		}
+		settled, err = settlement(ctx, store, invoice)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching payments"})
+			return
+		}
+		c.JSON(http.StatusOK, settled)
+	}
+}
+
+// PayPayment records that a guest paid their share, or the whole bill when
+// it was not split. Only shares of billed orders can be paid. Once the
+// shares cover the invoice it is marked paid, and the order moves to paid in
+// the same write as the last payment.
+func PayPayment(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Payment_method string `json:"payment_method" validate:"required,eq=cash|eq=card|eq=other"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		payment, err := store.Payments.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching payment"})
+			return
+		}
+		invoice, status, err := findInvoice(ctx, store, payment.Invoice_id)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		order, status, err := billedOrder(ctx, store, invoice)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		err = store.Payments.Pay(ctx, payment.Payment_id, body.Payment_method, models.OrderStatusChange{
+			From:       models.OrderStatusBilled,
+			To:         models.OrderStatusPaid,
+			Changed_by: c.GetString("uid"),
+			Changed_at: now,
+		})
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "payment is no longer pending or its order is no longer billed"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording payment"})
+			return
+		}
+
+		settled, err := settlement(ctx, store, invoice)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching payments"})
+			return
+		}
+		if settled.Payment_status != stringValue(invoice.Payment_status) {
+			invoice.Payment_status = &settled.Payment_status
+			invoice.Updated_at = now
+			if err := store.Invoices.Update(ctx, invoice); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update invoice"})
+				return
+			}
+		}
+		if settled.Balance.Amount <= 0 {
+			markForCleaning(ctx, store, order, now)
+		}
+		c.JSON(http.StatusOK, settled)
+	}
+}
+
+func findInvoice(ctx context.Context, store *repository.Store, invoiceId string) (models.Invoice, int, error) {
+	invoice, err := store.Invoices.FindByID(ctx, invoiceId)
+	if err == repository.ErrNotFound {
+		return invoice, http.StatusNotFound, errors.New("invoice was not found")
+	}
+	if err != nil {
+		return invoice, http.StatusInternalServerError, err
+	}
+	return invoice, http.StatusOK, nil
+}
+
+// settlement adds up the shares of invoice. An invoice stored before
+// invoices came with a payment of the whole bill has no shares until it is
+// split; it is all paid or all due, as its payment status says.
+func settlement(ctx context.Context, store *repository.Store, invoice models.Invoice) (views.SettlementView, error) {
+	payments, err := store.Payments.ListByInvoice(ctx, invoice.Invoice_id)
+	if err != nil {
+		return views.SettlementView{}, err
+	}
+	settled := views.SettlementView{
+		Invoice_id:     invoice.Invoice_id,
+		Order_id:       *invoice.Order_id,
+		Payment_status: stringValue(invoice.Payment_status),
+		Payment_due:    invoice.Payment_due,
+		Paid:           money.New(0, invoice.Payment_due.Currency),
+		Payments:       []models.Payment{},
+	}
+	for _, payment := range payments {
+		if payment.Status == models.PaymentStatusVoided {
+			continue
+		}
+		if payment.Status == models.PaymentStatusPaid {
+			if settled.Paid, err = settled.Paid.Add(payment.Amount); err != nil {
+				return settled, err
+			}
+		}
+		settled.Payments = append(settled.Payments, payment)
+	}
+	if len(settled.Payments) == 0 {
+		if settled.Payment_status == models.PaymentStatusPaid {
+			settled.Paid = invoice.Payment_due
+		}
+	} else if settled.Payment_status != models.PaymentStatusRefunded {
+		switch {
+		case settled.Paid.Amount >= invoice.Payment_due.Amount:
+			settled.Payment_status = models.PaymentStatusPaid
+		case settled.Paid.Amount > 0:
+			settled.Payment_status = models.PaymentStatusPartiallyPaid
+		default:
+			settled.Payment_status = models.PaymentStatusPending
+		}
+	}
+	settled.Balance, err = invoice.Payment_due.Sub(settled.Paid)
+	return settled, err
+}
+
+// billedOrder returns the order of invoice, provided it is billed: the bill
+// of an order is settled while it is billed, and no longer once it is paid
+// or written off.
+func billedOrder(ctx context.Context, store *repository.Store, invoice models.Invoice) (models.Order, int, error) {
+	order, err := store.Orders.FindByID(ctx, *invoice.Order_id)
+	if err == repository.ErrNotFound {
+		return order, http.StatusNotFound, errors.New("order was not found")
+	}
+	if err != nil {
+		return order, http.StatusInternalServerError, err
+	}
+	if status := helpers.OrderStatus(order); status != models.OrderStatusBilled {
+		return order, http.StatusConflict, errors.New("order is " + status + "; only bills of billed orders can be split or paid")
+	}
+	return order, http.StatusOK, nil
+}
+
+// invoiceLines returns the lines of invoice as it was billed, so later
+// changes to the order or the menu do not move the split.
+func invoiceLines(invoice models.Invoice) ([]pricing.Line, error) {
+	if invoice.Pricing == nil || len(invoice.Pricing.Lines) == 0 {
+		return nil, errors.New("invoice has no items to split")
+	}
+	for _, line := range invoice.Pricing.Lines {
+		if line.Order_item_id == "" {
+			return nil, errors.New("invoice does not record its order items; split it evenly")
+		}
+	}
+	return invoice.Pricing.Lines, nil
+}
+
+// seatShares makes a share per seat. Items without a seat are shared
+// equally between the seats.
+func seatShares(lines []pricing.Line) ([]share, error) {
+	bySeat := map[int]*share{}
+	seats := []int{}
+	unseated := []pricing.Line{}
+	for _, line := range lines {
+		if line.Seat == nil {
+			unseated = append(unseated, line)
+			continue
+		}
+		seat := *line.Seat
+		if bySeat[seat] == nil {
+			bySeat[seat] = &share{label: "Seat " + strconv.Itoa(seat), seat: &seat, order_item_ids: []string{}}
+			seats = append(seats, seat)
+		}
+		bySeat[seat].order_item_ids = append(bySeat[seat].order_item_ids, line.Order_item_id)
+		bySeat[seat].weight.Amount += line.Total.Amount
+	}
+	if len(seats) == 0 {
+		return nil, errors.New("no item of the order has a seat")
+	}
+	sort.Ints(seats)
+
+	var shared money.Money
+	for _, line := range unseated {
+		shared.Amount += line.Total.Amount
+	}
+	equal := make([]money.Money, len(seats))
+	for i := range equal {
+		equal[i] = money.New(1, "")
+	}
+	parts, err := pricing.Split(shared, equal)
+	if err != nil {
+		return nil, err
+	}
+	shares := []share{}
+	for i, part := range parts {
+		share := *bySeat[seats[i]]
+		share.weight.Amount += part.Amount
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// itemShares makes a share per list of order item ids, and one more for the
+// items no list names.
+func itemShares(lines []pricing.Line, items [][]string) ([]share, error) {
+	byId := map[string]pricing.Line{}
+	for _, line := range lines {
+		byId[line.Order_item_id] = line
+	}
+	taken := map[string]bool{}
+	shares := []share{}
+	for i, ids := range items {
+		share := share{label: "Items " + strconv.Itoa(i+1), order_item_ids: ids}
+		for _, id := range ids {
+			line, ok := byId[id]
+			if !ok {
+				return nil, errors.New("order item " + id + " is not on the order")
+			}
+			if taken[id] {
+				return nil, errors.New("order item " + id + " is in more than one share")
+			}
+			taken[id] = true
+			share.weight.Amount += line.Total.Amount
+		}
+		shares = append(shares, share)
+	}
+
+	rest := share{label: "Remaining items", order_item_ids: []string{}}
+	for _, line := range lines {
+		if !taken[line.Order_item_id] {
+			rest.order_item_ids = append(rest.order_item_ids, line.Order_item_id)
+			rest.weight.Amount += line.Total.Amount
+		}
+	}
+	if len(rest.order_item_ids) > 0 {
+		shares = append(shares, rest)
+	}
+	return shares, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,363 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"restaurant_management/booking"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetReservations lists the reservations of the day in the "date" query
+// (YYYY-MM-DD in the restaurant's timezone, today if absent), cancelled ones
+// included.
+func GetReservations(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		loc, err := restaurantLocation(ctx, store, bookings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tenant"})
+			return
+		}
+		day, err := bookingDay(c.Query("date"), loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+			return
+		}
+		reservations, err := store.Reservations.List(ctx, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing reservations"})
+			return
+		}
+		c.JSON(http.StatusOK, reservations)
+	}
+}
+
+func GetReservation(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		reservation, err := store.Reservations.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservation"})
+			return
+		}
+		c.JSON(http.StatusOK, reservation)
+	}
+}
+
+// CreateReservation books a table for a party. Without a table_id the
+// smallest free table that seats the party is assigned.
+func CreateReservation(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var reservation models.Reservation
+
+		if err := c.BindJSON(&reservation); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(reservation); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		reservation.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		reservation.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		reservation.ID = primitive.NewObjectID()
+		reservation.Reservation_id = reservation.ID.Hex()
+		reservation.Status = models.ReservationBooked
+
+		if status, err := bookTable(ctx, store, bookings, &reservation, reservation.Table_id, store.Reservations.Create); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, reservation)
+	}
+}
+
+// UpdateReservation changes a booking; only the fields sent are changed. A
+// new party size or time keeps the table if it is still free and big
+// enough, and otherwise assigns another.
+func UpdateReservation(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Name        *string    `json:"name" validate:"omitempty,min=2,max=100"`
+			Phone       *string    `json:"phone"`
+			Email       *string    `json:"email" validate:"omitempty,email"`
+			Party_size  *int       `json:"party_size" validate:"omitempty,min=1"`
+			Reserved_at *time.Time `json:"reserved_at"`
+			Table_id    *string    `json:"table_id"`
+			Notes       *string    `json:"notes"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		reservation, err := store.Reservations.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservation"})
+			return
+		}
+		if reservation.Status != models.ReservationBooked {
+			c.JSON(http.StatusConflict, gin.H{"error": "only booked reservations can be changed"})
+			return
+		}
+
+		if body.Name != nil {
+			reservation.Name = body.Name
+		}
+		if body.Phone != nil {
+			reservation.Phone = body.Phone
+		}
+		if body.Email != nil {
+			reservation.Email = body.Email
+		}
+		if body.Notes != nil {
+			reservation.Notes = body.Notes
+		}
+		reservation.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if body.Party_size == nil && body.Reserved_at == nil && body.Table_id == nil {
+			if err := store.Reservations.Update(ctx, reservation); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+				return
+			}
+			c.JSON(http.StatusOK, reservation)
+			return
+		}
+
+		if body.Party_size != nil {
+			reservation.Party_size = body.Party_size
+		}
+		if body.Reserved_at != nil {
+			reservation.Reserved_at = body.Reserved_at
+		}
+		table := ""
+		if body.Table_id != nil {
+			table = *body.Table_id
+		}
+		if status, err := bookTable(ctx, store, bookings, &reservation, table, store.Reservations.Update); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, reservation)
+	}
+}
+
+// CancelReservation frees the table of a booking. Cancelled reservations
+// stay listed.
+func CancelReservation(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		reservation, err := store.Reservations.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservation"})
+			return
+		}
+		if reservation.Status == models.ReservationCancelled {
+			c.JSON(http.StatusOK, reservation)
+			return
+		}
+		if reservation.Status != models.ReservationBooked {
+			c.JSON(http.StatusConflict, gin.H{"error": "only booked reservations can be cancelled"})
+			return
+		}
+
+		reservation.Status = models.ReservationCancelled
+		reservation.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Reservations.Update(ctx, reservation); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, reservation)
+	}
+}
+
+// GetAvailability lists the start times on "date" at which a party of
+// "party" can still be seated, for the booking form of the website.
+func GetAvailability(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		party, err := strconv.Atoi(c.Query("party"))
+		if err != nil || party < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "party must be a number of at least 1"})
+			return
+		}
+		loc, err := restaurantLocation(ctx, store, bookings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tenant"})
+			return
+		}
+		day, err := bookingDay(c.Query("date"), loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+			return
+		}
+
+		turn := bookings.TurnTime(party)
+		slots := bookings.Slots(day, loc)
+		tables, err := store.Tables.List(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching tables"})
+			return
+		}
+		availability := views.AvailabilityView{
+			Date:         day.Format(time.DateOnly),
+			Party:        party,
+			Timezone:     loc.String(),
+			Turn_minutes: int(turn.Minutes()),
+			Slots:        []views.SlotView{},
+		}
+		if len(slots) == 0 {
+			c.JSON(http.StatusOK, availability)
+			return
+		}
+		reservations, err := store.Reservations.List(ctx, slots[0], slots[len(slots)-1].Add(turn))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing reservations"})
+			return
+		}
+
+		now := time.Now()
+		for _, start := range slots {
+			if start.Before(now) {
+				continue
+			}
+			free := booking.FreeTables(tables, reservations, party, start, start.Add(turn), "")
+			if len(free) == 0 {
+				continue
+			}
+			availability.Slots = append(availability.Slots, views.SlotView{Start: start, End: start.Add(turn), Free_tables: len(free)})
+		}
+		c.JSON(http.StatusOK, availability)
+	}
+}
+
+// bookTable fits reservation at its time, ends it after the turn time for
+// its party and saves it with save. A requested table is kept if free and
+// big enough; otherwise the current table is kept if it still fits, or the
+// smallest free table that seats the party is taken. On failure it also
+// returns the HTTP status to answer with.
+func bookTable(ctx context.Context, store *repository.Store, bookings booking.Config, reservation *models.Reservation, requested string, save func(context.Context, models.Reservation) error) (int, error) {
+	loc, err := restaurantLocation(ctx, store, bookings)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	start := reservation.Reserved_at.UTC()
+	if !start.After(time.Now()) {
+		return http.StatusBadRequest, errors.New("reserved_at must be in the future")
+	}
+	if !bookings.Bookable(start, loc) {
+		return http.StatusBadRequest, errors.New("reserved_at is outside seating hours (" + bookings.Opens + " to " + bookings.Last_seating + " " + loc.String() + ")")
+	}
+	end := start.Add(bookings.TurnTime(*reservation.Party_size))
+	reservation.Reserved_at = &start
+	reservation.Ends_at = end
+
+	tables, err := store.Tables.List(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	reservations, err := store.Reservations.List(ctx, start, end)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	free := booking.FreeTables(tables, reservations, *reservation.Party_size, start, end, reservation.Reservation_id)
+
+	if requested != "" {
+		table, err := store.Tables.FindByID(ctx, requested)
+		if err == repository.ErrNotFound {
+			return http.StatusNotFound, errors.New("table was not found")
+		}
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !booking.Seats(table, *reservation.Party_size) {
+			return http.StatusConflict, errors.New("table does not seat a party of " + strconv.Itoa(*reservation.Party_size))
+		}
+		if !containsTable(free, requested) {
+			return http.StatusConflict, errors.New("table is already booked at that time")
+		}
+		reservation.Table_id = requested
+	} else if !containsTable(free, reservation.Table_id) {
+		if len(free) == 0 {
+			return http.StatusConflict, errors.New("no table is free for a party of " + strconv.Itoa(*reservation.Party_size) + " at that time")
+		}
+		reservation.Table_id = free[0].Table_id
+	}
+
+	err = save(ctx, *reservation)
+	if err == repository.ErrConflict {
+		return http.StatusConflict, errors.New("table was booked at the same time, try again")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func containsTable(tables []models.Table, tableId string) bool {
+	for _, table := range tables {
+		if table.Table_id == tableId {
+			return true
+		}
+	}
+	return false
+}
+
+// restaurantLocation is the timezone of the tenant ctx acts for, or the
+// configured one if it sets none.
+func restaurantLocation(ctx context.Context, store *repository.Store, bookings booking.Config) (*time.Location, error) {
+	tenant, err := currentTenant(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Timezone != nil {
+		return time.LoadLocation(*tenant.Timezone)
+	}
+	return time.LoadLocation(bookings.Timezone)
+}
+
+// bookingDay is the start of date (YYYY-MM-DD) in loc, or of today when
+// date is empty.
+func bookingDay(date string, loc *time.Location) (time.Time, error) {
+	if date == "" {
+		year, month, day := time.Now().In(loc).Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, loc), nil
+	}
+	return time.ParseInLocation(time.DateOnly, date, loc)
+}
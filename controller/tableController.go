@@ -2,44 +2,40 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
-	"restaurant_management/database"
+	"restaurant_management/booking"
 	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var tableCollection *mongo.Collection = database.OpenCollection(database.Client, "table")
-
-func GetTables() gin.HandlerFunc {
+func GetTables(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
-		result, err := tableCollection.Find(context.TODO(), bson.M{})
+		allTables, err := store.Tables.List(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching tables"})
-		}
-		var allTables []bson.M
-		if err := result.All(ctx, &allTables); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the data"})
 			return
 		}
 		c.JSON(http.StatusOK, allTables)
 	}
 }
 
-func GetTable() gin.HandlerFunc {
+func GetTable(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		tableId := c.Param("id")
-		var table models.Table
-		err := tableCollection.FindOne(ctx, bson.M{"table_id": tableId}).Decode(&table)
+		table, err := store.Tables.FindByID(ctx, tableId)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while fetching the food item"})
 			return
@@ -48,9 +44,9 @@ func GetTable() gin.HandlerFunc {
 	}
 }
 
-func CreateTable() gin.HandlerFunc {
+func CreateTable(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 
 		var table models.Table
@@ -65,25 +61,30 @@ func CreateTable() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, validationErr)
 			return
 		}
+		if status, err := checkServer(ctx, store, table.Server_id); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		table.Status = ""
 
 		table.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		table.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
 		table.ID = primitive.NewObjectID()
 		table.Table_id = table.ID.Hex()
-		insert, insertErr := tableCollection.InsertOne(ctx, table)
+		insertErr := store.Tables.Create(ctx, table)
 		if insertErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while inserting records"})
 			return
 		}
-		c.JSON(http.StatusOK, insert)
+		c.JSON(http.StatusOK, gin.H{"InsertedID": table.ID})
 
 	}
 }
 
-func UpdateTable() gin.HandlerFunc {
+func UpdateTable(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		var table models.Table
 		tableId := c.Param("id")
@@ -93,37 +94,215 @@ func UpdateTable() gin.HandlerFunc {
 			return
 		}
 
-		var updateObj bson.D
+		foundTable, err := store.Tables.FindByID(ctx, tableId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while fetching the table"})
+			return
+		}
 
 		if table.Number_of_quests != nil {
-			updateObj = append(updateObj, bson.E{Key: "number_of_guests", Value: table.Number_of_quests})
+			foundTable.Number_of_quests = table.Number_of_quests
 		}
 		if table.Table_number != nil {
-			updateObj = append(updateObj, bson.E{Key: "table_number", Value: table.Table_number})
+			foundTable.Table_number = table.Table_number
+		}
+		if table.Section != nil {
+			foundTable.Section = table.Section
+		}
+		if table.X != nil {
+			foundTable.X = table.X
+		}
+		if table.Y != nil {
+			foundTable.Y = table.Y
+		}
+		if table.Shape != nil {
+			foundTable.Shape = table.Shape
+		}
+		if table.Server_id != nil {
+			foundTable.Server_id = table.Server_id
+		}
+		if validationErr := validate.StructPartial(foundTable, "Section", "Shape"); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if status, err := checkServer(ctx, store, table.Server_id); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
 		}
 
-		table.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{Key: "update_at", Value: table.Updated_at})
+		foundTable.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-		upsert := true
-		filter := bson.M{"table_id": tableId}
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		updateErr := store.Tables.Update(ctx, foundTable)
+		if updateErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
 		}
+		c.JSON(http.StatusOK, foundTable)
 
-		insert, insertErr := tableCollection.UpdateOne(
-			ctx,
-			filter,
-			bson.D{
-				{Key: "$set", Value: updateObj},
-			},
-			&opt)
+	}
+}
 
-		if insertErr != nil {
+// UpdateTableStatus sets what staff say about a table: it is being cleaned,
+// out of service, or free again, which clears either.
+func UpdateTableStatus(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Status string `json:"status" validate:"required,oneof=free cleaning out_of_service"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		status := body.Status
+		if status == models.TableFree {
+			status = ""
+		}
+
+		at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		err := store.Tables.UpdateStatus(ctx, c.Param("id"), status, at)
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "table was not found"})
+			return
+		}
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
-		c.JSON(http.StatusOK, insert)
+		table, err := store.Tables.FindByID(ctx, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while fetching the table"})
+			return
+		}
+		c.JSON(http.StatusOK, table)
+	}
+}
+
+// GetFloor returns the floor plan: every table, or those of one "section",
+// with its live status, the orders on it and their total so far.
+func GetFloor(store *repository.Store, prices pricing.Config, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		tables, err := store.Tables.List(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching tables"})
+			return
+		}
+		orders, err := store.Orders.List(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching orders"})
+			return
+		}
+		now := time.Now()
+		reservations, err := store.Reservations.List(ctx, now, now.Add(24*time.Hour))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching reservations"})
+			return
+		}
+
+		section := c.Query("section")
+		floor := []views.FloorTableView{}
+		for _, table := range tables {
+			if section != "" && (table.Section == nil || *table.Section != section) {
+				continue
+			}
+			view := views.FloorTableView{
+				Table_id:         table.Table_id,
+				Table_number:     table.Table_number,
+				Number_of_guests: table.Number_of_quests,
+				Section:          table.Section,
+				X:                table.X,
+				Y:                table.Y,
+				Shape:            table.Shape,
+				Server_id:        table.Server_id,
+				Status:           bookings.TableStatus(table, orders, reservations, now),
+				Order_ids:        []string{},
+			}
+			for _, order := range orders {
+				if order.Table_id == nil || *order.Table_id != table.Table_id || !booking.Occupies(order) {
+					continue
+				}
+				items, err := pricedItemsByOrder(ctx, store, prices, order.Order_id)
+				if err != nil {
+					c.JSON(billStatus(err), gin.H{"error": "Error while pricing orders: " + err.Error()})
+					return
+				}
+				view.Order_ids = append(view.Order_ids, order.Order_id)
+				for _, group := range items {
+					if view.Order_total, err = view.Order_total.Add(group.Payment_due); err != nil {
+						c.JSON(billStatus(err), gin.H{"error": "Error while pricing orders: " + err.Error()})
+						return
+					}
+					for _, line := range group.Order_items {
+						view.Item_count += line.Quantity
+					}
+				}
+			}
+			if next := booking.NextReservation(table, reservations, now); next != nil {
+				view.Next_reservation_at = next.Reserved_at
+			}
+			floor = append(floor, view)
+		}
+		sort.SliceStable(floor, func(i, j int) bool {
+			a, b := floor[i], floor[j]
+			if sectionName(a.Section) != sectionName(b.Section) {
+				return sectionName(a.Section) < sectionName(b.Section)
+			}
+			return tableNumber(a.Table_number) < tableNumber(b.Table_number)
+		})
+		c.JSON(http.StatusOK, floor)
+	}
+}
+
+// checkServer makes sure a waiter assigned to a table is a user of the
+// outlet.
+func checkServer(ctx context.Context, store *repository.Store, serverId *string) (int, error) {
+	if serverId == nil {
+		return http.StatusOK, nil
+	}
+	_, err := findTenantUser(ctx, store, *serverId)
+	if err == repository.ErrNotFound {
+		return http.StatusBadRequest, errors.New("server_id is not a user of this outlet")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// markForCleaning flags the table of a paid order as being cleaned, unless
+// staff took it out of service. It is best effort: the payment stands
+// either way.
+func markForCleaning(ctx context.Context, store *repository.Store, order models.Order, at time.Time) {
+	if order.Table_id == nil {
+		return
+	}
+	table, err := store.Tables.FindByID(ctx, *order.Table_id)
+	if err == nil && table.Status != models.TableOutOfService {
+		err = store.Tables.UpdateStatus(ctx, table.Table_id, models.TableCleaning, at)
+	}
+	if err != nil && err != repository.ErrNotFound {
+		log.Printf("marking table %s for cleaning: %v", *order.Table_id, err)
+	}
+}
+
+func sectionName(section *string) string {
+	if section == nil {
+		return ""
+	}
+	return *section
+}
 
+func tableNumber(number *int) int {
+	if number == nil {
+		return 0
 	}
+	return *number
 }
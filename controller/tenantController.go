@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// requestContext is what handlers query with: it acts for the tenant the
+// request was authenticated for, or the default tenant.
+func requestContext(c *gin.Context) context.Context {
+	return repository.WithTenant(context.Background(), c.GetString("tenant"))
+}
+
+func GetTenants(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		tenants, err := store.Tenants.List(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing tenants"})
+			return
+		}
+		c.JSON(http.StatusOK, tenants)
+	}
+}
+
+func GetTenant(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		tenant, err := store.Tenants.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tenant was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tenant"})
+			return
+		}
+		c.JSON(http.StatusOK, tenant)
+	}
+}
+
+// GetCurrentTenant returns the settings of the tenant the caller acts for.
+// The default tenant has no record; its settings come from the pricing
+// config.
+func GetCurrentTenant(store *repository.Store, prices pricing.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		tenant, err := currentTenant(ctx, store)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tenant"})
+			return
+		}
+		if tenant.Currency == nil {
+			tenant.Currency = &prices.Currency
+		}
+		if tenant.Default_tax_rate == nil {
+			tenant.Default_tax_rate = &prices.Default_tax_rate
+		}
+		if tenant.Tax_rates == nil {
+			tenant.Tax_rates = prices.Tax_rates
+		}
+		c.JSON(http.StatusOK, tenant)
+	}
+}
+
+func CreateTenant(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var tenant models.Tenant
+
+		if err := c.BindJSON(&tenant); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(tenant); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		tenant.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		tenant.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		tenant.ID = primitive.NewObjectID()
+		tenant.Tenant_id = tenant.ID.Hex()
+		if err := store.Tenants.Create(ctx, tenant); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating tenant"})
+			return
+		}
+		c.JSON(http.StatusOK, tenant)
+	}
+}
+
+// UpdateTenant changes the name or settings of a tenant; only the fields
+// sent are changed.
+func UpdateTenant(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body models.Tenant
+
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.StructExcept(body, "Name"); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if body.Name != nil {
+			if err := validate.Var(*body.Name, "min=2,max=100"); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		tenant, err := store.Tenants.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tenant was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tenant"})
+			return
+		}
+
+		if body.Name != nil {
+			tenant.Name = body.Name
+		}
+		if body.Currency != nil {
+			tenant.Currency = body.Currency
+		}
+		if body.Default_tax_rate != nil {
+			tenant.Default_tax_rate = body.Default_tax_rate
+		}
+		if body.Tax_rates != nil {
+			tenant.Tax_rates = body.Tax_rates
+		}
+		if body.Timezone != nil {
+			tenant.Timezone = body.Timezone
+		}
+		tenant.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Tenants.Update(ctx, tenant); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, tenant)
+	}
+}
+
+// UpdateUserTenant moves a user to another outlet, or back to the head
+// office with an empty tenant_id. It takes effect the next time the user's
+// tokens are issued.
+func UpdateUserTenant(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Tenant_id *string `json:"tenant_id" validate:"required"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		user, err := store.Users.FindByID(ctx, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		if user.User_id == c.GetString("uid") {
+			c.JSON(http.StatusConflict, gin.H{"error": "admins cannot move themselves"})
+			return
+		}
+		if *body.Tenant_id != repository.DefaultTenant {
+			if _, err := store.Tenants.FindByID(ctx, *body.Tenant_id); err == repository.ErrNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "tenant was not found"})
+				return
+			} else if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tenant"})
+				return
+			}
+		}
+
+		user.Tenant_id = *body.Tenant_id
+		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Users.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": user.User_id, "tenant_id": user.Tenant_id})
+	}
+}
+
+// currentTenant is the tenant ctx acts for. The default tenant, which has no
+// record, comes back empty.
+func currentTenant(ctx context.Context, store *repository.Store) (models.Tenant, error) {
+	tenantId := repository.TenantID(ctx)
+	if tenantId == repository.DefaultTenant {
+		return models.Tenant{}, nil
+	}
+	return store.Tenants.FindByID(ctx, tenantId)
+}
+
+// tenantPrices is prices as the tenant ctx acts for has set them.
+func tenantPrices(ctx context.Context, store *repository.Store, prices pricing.Config) (pricing.Config, error) {
+	tenant, err := currentTenant(ctx, store)
+	if err != nil {
+		return prices, err
+	}
+	return helpers.TenantPrices(prices, tenant), nil
+}
+
+// findTenantUser looks up a user of the tenant ctx acts for. Users of other
+// tenants are not found.
+func findTenantUser(ctx context.Context, store *repository.Store, userId string) (models.User, error) {
+	user, err := store.Users.FindByID(ctx, userId)
+	if err == nil && user.Tenant_id != repository.TenantID(ctx) {
+		return models.User{}, repository.ErrNotFound
+	}
+	return user, err
+}
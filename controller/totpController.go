@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnrollTOTP starts TOTP enrollment for the calling user: it stores a new
+// secret and returns it with the otpauth URI for an authenticator app. The
+// secret is not enforced until ConfirmTOTP accepts a code from it.
+func EnrollTOTP(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		user, err := store.Users.FindByID(ctx, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		if user.Totp_enabled {
+			c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+			return
+		}
+
+		secret, err := helpers.GenerateTOTPSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating secret"})
+			return
+		}
+		user.Totp_secret = &secret
+		user.Totp_last_step = 0
+		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Users.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"secret": secret, "otpauth_uri": helpers.TOTPURI(secret, *user.Email)})
+	}
+}
+
+// ConfirmTOTP enables TOTP once the user proves their app works, and returns
+// the recovery codes. They are shown this once; only hashes are kept.
+func ConfirmTOTP(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Code string `json:"code" validate:"required,len=6,numeric"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		user, err := store.Users.FindByID(ctx, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		if user.Totp_enabled {
+			c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+			return
+		}
+		if user.Totp_secret == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "start with POST /users/totp/enroll"})
+			return
+		}
+		step, ok := helpers.ValidateTOTP(*user.Totp_secret, body.Code, time.Now(), user.Totp_last_step)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "code is incorrect"})
+			return
+		}
+
+		codes, hashes, err := helpers.GenerateRecoveryCodes()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating recovery codes"})
+			return
+		}
+		user.Totp_enabled = true
+		user.Totp_last_step = step
+		user.Recovery_codes = hashes
+		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Users.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"totp_enabled": true, "recovery_codes": codes})
+	}
+}
+
+// LoginTOTP is the second login step for users with TOTP enabled. It takes
+// the mfa_token from Login and either a current code or a recovery code,
+// which is then spent. Wrong codes count as failed logins.
+func LoginTOTP(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Mfa_token     string `json:"mfa_token" validate:"required"`
+			Code          string `json:"code" validate:"omitempty,len=6,numeric"`
+			Recovery_code string `json:"recovery_code" validate:"required_without=Code"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		claims, err := helpers.ValidateMFAToken(body.Mfa_token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		user, err := store.Users.FindByID(ctx, claims.Uid)
+		if err != nil || !user.Totp_enabled || user.Totp_secret == nil || user.Deactivated_at != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": helpers.ErrTokenInvalid.Error()})
+			return
+		}
+
+		accountKey := helpers.AccountThrottleKey(*user.Email)
+		ipKey := helpers.IPThrottleKey(c.ClientIP())
+		if !loginAllowed(ctx, store, c, accountKey, ipKey) {
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		ok := false
+		if body.Code != "" {
+			var step int64
+			if step, ok = helpers.ValidateTOTP(*user.Totp_secret, body.Code, time.Now(), user.Totp_last_step); ok {
+				// the step is claimed in the database, so of two requests
+				// with the same code only one gets in
+				err := store.Users.UseTOTPStep(ctx, user.User_id, step, now)
+				if err != nil && err != repository.ErrConflict {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+					return
+				}
+				ok = err == nil
+			}
+		} else {
+			user.Recovery_codes, ok = helpers.UseRecoveryCode(user.Recovery_codes, body.Recovery_code)
+			if ok {
+				user.Updated_at = now
+				if err := store.Users.Update(ctx, user); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+					return
+				}
+			}
+		}
+		if !ok {
+			if err := recordLoginFailure(ctx, store, c, accountKey, helpers.MaxAccountLoginFailures, models.AuditAccountLocked, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+				return
+			}
+			if err := recordLoginFailure(ctx, store, c, ipKey, helpers.MaxIPLoginFailures, models.AuditIPLocked, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "code is incorrect"})
+			return
+		}
+		if err := store.LoginThrottles.Clear(ctx, accountKey); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+			return
+		}
+
+		token, refresh_token, err := issueTokens(ctx, store, user, "", true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, views.LoginView{UserView: views.NewUserView(user), Token: token, Refresh_token: refresh_token})
+	}
+}
@@ -0,0 +1,296 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/kitchen"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransferOrder moves an order, party and all, to another table. The table
+// must be free; to join a party already seated there, merge the tables.
+func TransferOrder(store *repository.Store, tickets *kitchen.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Table_id string `json:"table_id" validate:"required"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		order, status, err := findEditableOrder(ctx, store, c.Param("id"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if status, err := checkTransfer(ctx, store, order, body.Table_id); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if !moveOrder(ctx, store, tickets, c, order, body.Table_id, at) {
+			return
+		}
+		order, err = store.Orders.FindByID(ctx, order.Order_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	}
+}
+
+// MergeTables joins the party at the table in the body onto the table in
+// the path: its orders are merged into the order there, or moved over if
+// the table has none. The merge moves all items of those orders and closes
+// them as merged in one transaction.
+func MergeTables(store *repository.Store, tickets *kitchen.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Table_id string `json:"table_id" validate:"required"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		tableId := c.Param("id")
+		if body.Table_id == tableId {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a table cannot be merged with itself"})
+			return
+		}
+		for _, id := range []string{tableId, body.Table_id} {
+			if _, err := store.Tables.FindByID(ctx, id); err == repository.ErrNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "table was not found"})
+				return
+			} else if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while fetching the table"})
+				return
+			}
+		}
+
+		into, err := heldOrders(ctx, store, tableId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching orders"})
+			return
+		}
+		from, err := heldOrders(ctx, store, body.Table_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching orders"})
+			return
+		}
+		if len(from) == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "table has no open order to merge"})
+			return
+		}
+		for _, order := range append(into, from...) {
+			if !helpers.OrderEditable(order) {
+				c.JSON(http.StatusConflict, gin.H{"error": "billed orders cannot be merged"})
+				return
+			}
+		}
+		if len(into) == 0 {
+			// nobody sits at the table yet; the first order moves there
+			into, from = from[:1], from[1:]
+		}
+		merged := []string{}
+		for _, order := range from {
+			merged = append(merged, order.Order_id)
+		}
+
+		change := models.OrderStatusChange{
+			To:         models.OrderStatusMerged,
+			Changed_by: c.GetString("uid"),
+		}
+		change.Changed_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		err = store.Orders.Merge(ctx, merged, into[0].Order_id, tableId, change)
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Order changed meanwhile, reload and retry"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		vacate(ctx, store, body.Table_id, change.Changed_at)
+		refreshTickets(ctx, store, tickets, into[0].Order_id, change.Changed_at)
+
+		order, err := store.Orders.FindByID(ctx, into[0].Order_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	}
+}
+
+// MoveOrderItems moves items from one order to another, or splits them off
+// into a new order at the table in the body. Either all items move or none.
+func MoveOrderItems(store *repository.Store, tickets *kitchen.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Order_item_ids []string `json:"order_item_ids" validate:"required,min=1,dive,required"`
+			Order_id       string   `json:"order_id" validate:"required_without=Table_id,excluded_with=Table_id"`
+			Table_id       string   `json:"table_id"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		from, status, err := findEditableOrder(ctx, store, c.Param("id"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if body.Order_id == from.Order_id {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "items are already on that order"})
+			return
+		}
+		if body.Order_id != "" {
+			if _, status, err := findEditableOrder(ctx, store, body.Order_id); err != nil {
+				c.JSON(status, gin.H{"error": err.Error()})
+				return
+			}
+			err = store.Orders.MoveItems(ctx, from.Order_id, body.Order_id, body.Order_item_ids, at)
+		} else {
+			// splitting items off at the party's own table is fine
+			if status, err := checkTransfer(ctx, store, from, body.Table_id); err != nil && status != http.StatusBadRequest {
+				c.JSON(status, gin.H{"error": err.Error()})
+				return
+			}
+			to := newOrder(models.Order{Table_id: &body.Table_id}, c.GetString("uid"))
+			body.Order_id = to.Order_id
+			err = store.Orders.SplitOff(ctx, from.Order_id, to, body.Order_item_ids, at)
+		}
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "items are not all on the order, or an order changed meanwhile"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		refreshTickets(ctx, store, tickets, body.Order_id, at)
+
+		from, err = store.Orders.FindByID(ctx, from.Order_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		to, err := store.Orders.FindByID(ctx, body.Order_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"from": from, "to": to})
+	}
+}
+
+// findEditableOrder returns the order if it can still change table and
+// have items moved.
+func findEditableOrder(ctx context.Context, store *repository.Store, orderId string) (models.Order, int, error) {
+	order, err := store.Orders.FindByID(ctx, orderId)
+	if err == repository.ErrNotFound {
+		return order, http.StatusNotFound, errors.New("order was not found")
+	}
+	if err != nil {
+		return order, http.StatusInternalServerError, err
+	}
+	if !helpers.OrderEditable(order) {
+		return order, http.StatusConflict, errors.New("order is " + helpers.OrderStatus(order) + " and can no longer change")
+	}
+	return order, http.StatusOK, nil
+}
+
+// checkTransfer makes sure order can move to tableId: the table exists, is
+// in service and no other order holds it. It answers 400 when the order is
+// already there.
+func checkTransfer(ctx context.Context, store *repository.Store, order models.Order, tableId string) (int, error) {
+	table, err := store.Tables.FindByID(ctx, tableId)
+	if err == repository.ErrNotFound {
+		return http.StatusNotFound, errors.New("table was not found")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if table.Status == models.TableOutOfService {
+		return http.StatusConflict, errors.New("table is out of service")
+	}
+	orders, err := heldOrders(ctx, store, tableId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	for _, other := range orders {
+		if other.Order_id != order.Order_id {
+			return http.StatusConflict, errors.New("table is occupied; merge the tables instead")
+		}
+	}
+	if order.Table_id != nil && *order.Table_id == tableId {
+		return http.StatusBadRequest, errors.New("order is already at that table")
+	}
+	return http.StatusOK, nil
+}
+
+// moveOrder transfers order to tableId, tickets and all, and frees the
+// table it leaves, writing the error response itself when it fails.
+func moveOrder(ctx context.Context, store *repository.Store, tickets *kitchen.Hub, c *gin.Context, order models.Order, tableId string, at time.Time) bool {
+	err := store.Orders.Transfer(ctx, order.Order_id, tableId, at)
+	if err == repository.ErrConflict {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order changed meanwhile, reload and retry"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if order.Table_id != nil {
+		vacate(ctx, store, *order.Table_id, at)
+	}
+	announceTickets(ctx, store, tickets, order.Order_id, at)
+	return true
+}
+
+// vacate marks a table the party left for cleaning once no order holds it.
+func vacate(ctx context.Context, store *repository.Store, tableId string, at time.Time) {
+	orders, err := heldOrders(ctx, store, tableId)
+	if err != nil {
+		log.Printf("checking whether table %s is vacant: %v", tableId, err)
+		return
+	}
+	if len(orders) == 0 {
+		markForCleaning(ctx, store, models.Order{Table_id: &tableId}, at)
+	}
+}
+
+// heldOrders returns the orders that hold tableId, oldest first.
+func heldOrders(ctx context.Context, store *repository.Store, tableId string) ([]models.Order, error) {
+	return store.Orders.ListAtTable(ctx, tableId, models.OccupyingOrderStatuses)
+}
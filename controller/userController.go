@@ -4,25 +4,22 @@ import (
 	"context"
 	"log"
 	"net/http"
-	"restaurant_management/database"
 	"restaurant_management/helpers"
+	"restaurant_management/mail"
 	"restaurant_management/models"
+	"restaurant_management/repository"
+	"restaurant_management/views"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var userCollection *mongo.Collection = database.OpenCollection(database.Client, "user")
-
-func GetUsers() gin.HandlerFunc {
+func GetUsers(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 10*time.Second)
 		defer cancel()
 
 		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
@@ -37,48 +34,36 @@ func GetUsers() gin.HandlerFunc {
 
 		startIndex := int64((page - 1) * recordPerPage)
 
-		opts := options.Find().
-			SetSkip(startIndex).
-			SetLimit(int64(recordPerPage)).
-			SetProjection(bson.M{"password": 0})
-
-		cursor, err := userCollection.Find(ctx, bson.M{}, opts)
+		users, err := store.Users.List(ctx, repository.Page{Skip: startIndex, Limit: int64(recordPerPage)})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		defer cursor.Close(ctx)
-
-		var users []models.User
-		if err := cursor.All(ctx, &users); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
 		c.JSON(http.StatusOK, gin.H{
 			"page":  page,
 			"size":  recordPerPage,
-			"items": users,
+			"items": views.NewUserViews(users),
 		})
 	}
 }
 
-func GetUser() gin.HandlerFunc {
+func GetUser(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		userId := c.Param("id")
-		var user models.User
-		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&user); err != nil {
+		user, err := findTenantUser(ctx, store, userId)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
 			return
 		}
-		c.JSON(http.StatusOK, user)
+		c.JSON(http.StatusOK, views.NewUserView(user))
 	}
 }
 
-func SignUp() gin.HandlerFunc {
+func SignUp(store *repository.Store, mailer mail.Sender) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		var user models.User
 
@@ -92,7 +77,7 @@ func SignUp() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported data"})
 			return
 		}
-		count, err := userCollection.CountDocuments(ctx, bson.M{"email": user.Email})
+		count, err := store.Users.CountByEmail(ctx, *user.Email)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported data"})
 			return
@@ -101,10 +86,13 @@ func SignUp() gin.HandlerFunc {
 		password := HashPassword(*user.Password)
 		user.Password = &password
 
-		count, err = userCollection.CountDocuments(ctx, bson.M{"phone": user.Phone})
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported data"})
-			return
+		if user.Phone != nil {
+			phoneCount, err := store.Users.CountByPhone(ctx, *user.Phone)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported data"})
+				return
+			}
+			count += phoneCount
 		}
 
 		if count > 0 {
@@ -112,56 +100,513 @@ func SignUp() gin.HandlerFunc {
 			return
 		}
 
+		// Nobody picks their own role: everyone starts as a waiter, and
+		// the first admin is made with the make-admin command
+		user.Role = models.RoleWaiter
+		user.Email_verified = false
+		user.Deactivated_at = nil
+		user.Tenant_id = repository.DefaultTenant
+		user.Totp_enabled = false
+		user.Totp_secret = nil
+		user.Totp_last_step = 0
+		user.Recovery_codes = nil
+
 		user.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		user.ID = primitive.NewObjectID()
 		user.User_id = user.ID.Hex()
 
-		token, refresh_token, _ := helpers.GenerateAllTokens(*user.Email, *user.First_name, *user.Last_name, user.User_id)
+		refresh := helpers.NewRefreshToken(user.User_id, "")
+		token, refresh_token, err := helpers.GenerateAllTokens(*user.Email, *user.First_name, *user.Last_name, user.User_id, user.Role, user.Tenant_id, refresh)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while signing tokens"})
+			return
+		}
 		user.Token = &token
 		user.Refresh_token = &refresh_token
 
-		insert, insertErr := userCollection.InsertOne(ctx, user)
+		insertErr := store.Users.Create(ctx, user)
 		if insertErr != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported data"})
 			return
 		}
-		c.JSON(http.StatusOK, insert)
+		if err := store.RefreshTokens.Create(ctx, refresh); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while saving refresh token"})
+			return
+		}
+		logMailError(sendVerification(ctx, mailer, user))
+		c.JSON(http.StatusOK, gin.H{"InsertedID": user.ID})
 	}
 
 }
 
-func Login() gin.HandlerFunc {
+func Login(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
 		defer cancel()
 		var user models.User
-		var foundUser models.User
 
 		if err := c.BindJSON(&user); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-
-		if err := userCollection.FindOne(ctx, bson.M{"email": user.Email}).Decode(&foundUser); err != nil {
+		if user.Email == nil || user.Password == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported data"})
 			return
 		}
 
-		passwordIsValid, msg := VerifyPassword(*user.Password, *foundUser.Password)
-		if !passwordIsValid {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		accountKey := helpers.AccountThrottleKey(*user.Email)
+		ipKey := helpers.IPThrottleKey(c.ClientIP())
+		if !loginAllowed(ctx, store, c, accountKey, ipKey) {
+			return
+		}
+
+		// unknown emails count against the account key too, so probing
+		// for accounts is throttled like guessing passwords, and they are
+		// checked against a stand-in hash and answered the same way as a
+		// wrong password, so neither the response nor its timing tells
+		// whether an account exists
+		foundUser, err := store.Users.FindByEmail(ctx, *user.Email)
+		if err != nil && err != repository.ErrNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		hash := unknownAccountHash
+		if err == nil {
+			hash = *foundUser.Password
+		}
+		passwordIsValid, _ := VerifyPassword(*user.Password, hash)
+		if !passwordIsValid || err != nil {
+			if err := recordLoginFailure(ctx, store, c, accountKey, helpers.MaxAccountLoginFailures, models.AuditAccountLocked, foundUser); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+				return
+			}
+			if err := recordLoginFailure(ctx, store, c, ipKey, helpers.MaxIPLoginFailures, models.AuditIPLocked, foundUser); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "email or password is incorrect"})
+			return
+		}
+		if err := store.LoginThrottles.Clear(ctx, accountKey); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+			return
+		}
+		if foundUser.Deactivated_at != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated"})
 			return
 		}
 
-		token, refresh_token, _ := helpers.GenerateAllTokens(*user.Email, *user.First_name, *user.Last_name, user.User_id)
+		if foundUser.Totp_enabled {
+			mfaToken, err := helpers.GenerateMFAToken(foundUser)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"mfa_required": true, "mfa_token": mfaToken})
+			return
+		}
 
-		helpers.UpdateAllTokens(token, refresh_token, foundUser.User_id)
-		c.JSON(http.StatusOK, foundUser)
+		token, refresh_token, err := issueTokens(ctx, store, foundUser, "", false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, views.LoginView{UserView: views.NewUserView(foundUser), Token: token, Refresh_token: refresh_token})
 	}
 
 }
 
+// RefreshToken exchanges a refresh token for a new access and refresh token.
+// Each refresh token works once; presenting one that was already exchanged
+// means it leaked, so every token descended from the same login is revoked.
+func RefreshToken(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Refresh_token string `json:"refresh_token" validate:"required"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		claims, err := helpers.ValidateRefreshToken(body.Refresh_token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		refresh, err := store.RefreshTokens.FindByID(ctx, claims.Id)
+		if err == repository.ErrNotFound || (err == nil && refresh.User_id != claims.Uid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token is invalid or expired"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking refresh token"})
+			return
+		}
+		if refresh.Revoked_at != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token has been revoked"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		err = store.RefreshTokens.MarkUsed(ctx, refresh.Token_id, now)
+		if err == repository.ErrConflict {
+			if err := store.RefreshTokens.RevokeFamily(ctx, refresh.Family_id, now); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking refresh tokens"})
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token was already used, please log in again"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking refresh token"})
+			return
+		}
+
+		user, err := store.Users.FindByID(ctx, refresh.User_id)
+		if err != nil || user.Deactivated_at != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token is invalid or expired"})
+			return
+		}
+		token, refresh_token, err := issueTokens(ctx, store, user, refresh.Family_id, refresh.Mfa)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"token": token, "refresh_token": refresh_token})
+	}
+}
+
+// Logout revokes the refresh token and every token rotated from the same
+// login. Access tokens already handed out stay valid until they expire.
+func Logout(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Refresh_token string `json:"refresh_token" validate:"required"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		claims, err := helpers.ValidateRefreshToken(body.Refresh_token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		refresh, err := store.RefreshTokens.FindByID(ctx, claims.Id)
+		if err == repository.ErrNotFound || (err == nil && refresh.User_id != claims.Uid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token is invalid or expired"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking refresh token"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.RefreshTokens.RevokeFamily(ctx, refresh.Family_id, now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking refresh tokens"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+	}
+}
+
+// issueTokens signs a new token pair for the user, records the refresh token
+// in familyId (a new family when empty) and saves both on the user. mfa says
+// whether the login passed TOTP.
+func issueTokens(ctx context.Context, store *repository.Store, user models.User, familyId string, mfa bool) (string, string, error) {
+	refresh := helpers.NewRefreshToken(user.User_id, familyId)
+	refresh.Mfa = mfa
+	token, refresh_token, err := helpers.GenerateAllTokens(*user.Email, *user.First_name, *user.Last_name, user.User_id, helpers.UserRole(user), user.Tenant_id, refresh)
+	if err != nil {
+		return "", "", err
+	}
+	if err := store.RefreshTokens.Create(ctx, refresh); err != nil {
+		return "", "", err
+	}
+	if err := store.Users.UpdateTokens(ctx, user.User_id, token, refresh_token); err != nil {
+		return "", "", err
+	}
+	return token, refresh_token, nil
+}
+
+// UpdateUserRole assigns a role to a user. It takes effect the next time the
+// user logs in.
+func UpdateUserRole(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Role string `json:"role" validate:"required,eq=admin|eq=manager|eq=waiter|eq=kitchen|eq=cashier"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		user, err := findTenantUser(ctx, store, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		if user.User_id == c.GetString("uid") && body.Role != models.RoleAdmin {
+			c.JSON(http.StatusConflict, gin.H{"error": "admins cannot demote themselves"})
+			return
+		}
+
+		user.Role = body.Role
+		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Users.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": user.User_id, "role": user.Role})
+	}
+}
+
+// UpdateUser changes a user's name, phone or avatar. Users may edit their own
+// profile; admins may edit anyone's.
+func UpdateUser(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			First_name *string `json:"first_name" validate:"omitempty,min=2,max=100"`
+			Last_name  *string `json:"last_name" validate:"omitempty,min=2,max=100"`
+			Phone      *string `json:"phone"`
+			Avatar     *string `json:"avatar"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		var user models.User
+		var err error
+		if c.Param("id") == c.GetString("uid") {
+			user, err = store.Users.FindByID(ctx, c.Param("id"))
+		} else {
+			user, err = findTenantUser(ctx, store, c.Param("id"))
+		}
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+
+		if body.Phone != nil && (user.Phone == nil || *user.Phone != *body.Phone) {
+			count, err := store.Users.CountByPhone(ctx, *body.Phone)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking phone number"})
+				return
+			}
+			if count > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "This phone number already exists"})
+				return
+			}
+			user.Phone = body.Phone
+		}
+		if body.First_name != nil {
+			user.First_name = body.First_name
+		}
+		if body.Last_name != nil {
+			user.Last_name = body.Last_name
+		}
+		if body.Avatar != nil {
+			user.Avatar = body.Avatar
+		}
+
+		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Users.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		c.JSON(http.StatusOK, views.NewUserView(user))
+	}
+}
+
+// ChangePassword replaces the caller's password after checking the current
+// one, then revokes their refresh tokens so other sessions must log in again.
+// Wrong current passwords are throttled like failed logins.
+func ChangePassword(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Current_password string `json:"current_password" validate:"required"`
+			New_password     string `json:"new_password" validate:"required,min=6,max=12"`
+		}
+		if err := c.BindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(body); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if c.Param("id") != c.GetString("uid") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "users can only change their own password"})
+			return
+		}
+
+		user, err := store.Users.FindByID(ctx, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		// a stolen access token must not turn into unlimited guesses at the
+		// password, so the check counts against the same keys as login
+		accountKey := helpers.AccountThrottleKey(*user.Email)
+		ipKey := helpers.IPThrottleKey(c.ClientIP())
+		if !loginAllowed(ctx, store, c, accountKey, ipKey) {
+			return
+		}
+		if passwordIsValid, _ := VerifyPassword(body.Current_password, *user.Password); !passwordIsValid {
+			if err := recordLoginFailure(ctx, store, c, accountKey, helpers.MaxAccountLoginFailures, models.AuditAccountLocked, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+				return
+			}
+			if err := recordLoginFailure(ctx, store, c, ipKey, helpers.MaxIPLoginFailures, models.AuditIPLocked, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+				return
+			}
+			c.JSON(http.StatusForbidden, gin.H{"error": "current password is incorrect"})
+			return
+		}
+		if err := store.LoginThrottles.Clear(ctx, accountKey); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while recording login attempt"})
+			return
+		}
+
+		password := HashPassword(body.New_password)
+		user.Password = &password
+		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := store.Users.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+			return
+		}
+		if err := store.RefreshTokens.RevokeUser(ctx, user.User_id, user.Updated_at); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking sessions"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": user.User_id, "password_changed": true})
+	}
+}
+
+// DeactivateUser stops a user from logging in and revokes their refresh
+// tokens. Access tokens already issued stay valid until they expire.
+func DeactivateUser(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		user, err := findTenantUser(ctx, store, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+		if user.User_id == c.GetString("uid") {
+			c.JSON(http.StatusConflict, gin.H{"error": "admins cannot deactivate themselves"})
+			return
+		}
+
+		if user.Deactivated_at == nil {
+			now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			user.Deactivated_at = &now
+			user.Updated_at = now
+			if err := store.Users.Update(ctx, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+				return
+			}
+			if err := store.RefreshTokens.RevokeUser(ctx, user.User_id, now); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking sessions"})
+				return
+			}
+			recordAudit(ctx, store, models.AuditEvent{
+				Type:      models.AuditUserDeactivated,
+				Tenant_id: user.Tenant_id,
+				User_id:   user.User_id,
+				Actor_id:  c.GetString("uid"),
+				Ip:        c.ClientIP(),
+			})
+		}
+		c.JSON(http.StatusOK, views.NewUserView(user))
+	}
+}
+
+// ReactivateUser lets a deactivated user log in again.
+func ReactivateUser(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		user, err := findTenantUser(ctx, store, c.Param("id"))
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+			return
+		}
+
+		if user.Deactivated_at != nil {
+			user.Deactivated_at = nil
+			user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			if err := store.Users.Update(ctx, user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+				return
+			}
+			recordAudit(ctx, store, models.AuditEvent{
+				Type:      models.AuditUserReactivated,
+				Tenant_id: user.Tenant_id,
+				User_id:   user.User_id,
+				Actor_id:  c.GetString("uid"),
+				Ip:        c.ClientIP(),
+			})
+		}
+		c.JSON(http.StatusOK, views.NewUserView(user))
+	}
+}
+
+// unknownAccountHash is a bcrypt hash at the cost HashPassword uses. Login
+// checks the passwords sent for unknown emails against it, and rejects them
+// even when they match.
+const unknownAccountHash = "$2a$14$BuLXkPwaIp4yiZTnUB8AHOIdjNsLN31AWpGNVNp0g.cTW585bQ8m6"
+
 func HashPassword(password string) string {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	if err != nil {
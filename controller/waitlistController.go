@@ -0,0 +1,364 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"restaurant_management/booking"
+	"restaurant_management/models"
+	"restaurant_management/notify"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// waitingStatuses are the statuses of parties still in the queue.
+var waitingStatuses = []string{models.WaitlistWaiting, models.WaitlistNotified}
+
+// GetWaitlist lists the parties still waiting, first in first, with their
+// current estimated wait.
+func GetWaitlist(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		entries, err := store.Waitlist.List(ctx, waitingStatuses)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing waitlist"})
+			return
+		}
+		estimator, err := newWaitEstimator(ctx, store, bookings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while estimating waits"})
+			return
+		}
+		waitlist := []views.WaitlistEntryView{}
+		for i, entry := range entries {
+			waitlist = append(waitlist, views.WaitlistEntryView{
+				WaitlistEntry:          entry,
+				Position:               i + 1,
+				Estimated_wait_minutes: estimator.minutes(entries[:i], *entry.Party_size),
+			})
+		}
+		c.JSON(http.StatusOK, waitlist)
+	}
+}
+
+// QuoteWait estimates the wait of a party of "party" joining now, without
+// adding it.
+func QuoteWait(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		party, err := strconv.Atoi(c.Query("party"))
+		if err != nil || party < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "party must be a number of at least 1"})
+			return
+		}
+		entries, err := store.Waitlist.List(ctx, waitingStatuses)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing waitlist"})
+			return
+		}
+		estimator, err := newWaitEstimator(ctx, store, bookings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while estimating waits"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"party":                  party,
+			"parties_ahead":          len(entries),
+			"estimated_wait_minutes": estimator.minutes(entries, party),
+		})
+	}
+}
+
+// AddToWaitlist puts a walk-in party at the end of the queue and quotes its
+// wait.
+func AddToWaitlist(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var entry models.WaitlistEntry
+
+		if err := c.BindJSON(&entry); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(entry); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		entries, err := store.Waitlist.List(ctx, waitingStatuses)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing waitlist"})
+			return
+		}
+		estimator, err := newWaitEstimator(ctx, store, bookings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while estimating waits"})
+			return
+		}
+		quote := estimator.minutes(entries, *entry.Party_size)
+		if quote == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "no table seats a party of " + strconv.Itoa(*entry.Party_size)})
+			return
+		}
+
+		entry.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		entry.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		entry.ID = primitive.NewObjectID()
+		entry.Entry_id = entry.ID.Hex()
+		entry.Status = models.WaitlistWaiting
+		entry.Quoted_wait_minutes = *quote
+		entry.Notified_at = nil
+		entry.Seated_at = nil
+		entry.Table_id = nil
+		entry.Order_id = nil
+		if err := store.Waitlist.Create(ctx, entry); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while adding to waitlist"})
+			return
+		}
+		c.JSON(http.StatusOK, views.WaitlistEntryView{
+			WaitlistEntry:          entry,
+			Position:               len(entries) + 1,
+			Estimated_wait_minutes: quote,
+		})
+	}
+}
+
+// NotifyWaitlistEntry tells a waiting party their table is ready. It may be
+// sent again if they do not show up.
+func NotifyWaitlistEntry(store *repository.Store, notifier notify.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		entry, status, err := findWaitingEntry(ctx, store, c.Param("id"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		message := notify.Message{
+			To:   *entry.Phone,
+			Body: *entry.Name + ", your table for " + strconv.Itoa(*entry.Party_size) + " is ready. Please come to the host stand.",
+		}
+		if err := notifier.Notify(ctx, message); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Could not notify the party: " + err.Error()})
+			return
+		}
+
+		from := entry.Status
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		entry.Status = models.WaitlistNotified
+		entry.Notified_at = &now
+		entry.Updated_at = now
+		if err := updateWaitlistEntry(ctx, store, c, entry, from); err != nil {
+			return
+		}
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// SeatWaitlistEntry seats a waiting party at the table in the body, or at
+// the smallest free table that seats them, and opens their order.
+func SeatWaitlistEntry(store *repository.Store, bookings booking.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+		var body struct {
+			Table_id string `json:"table_id"`
+		}
+		if c.Request.ContentLength != 0 {
+			if err := c.BindJSON(&body); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		entry, status, err := findWaitingEntry(ctx, store, c.Param("id"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		table, status, err := seatingTable(ctx, store, bookings, *entry.Party_size, body.Table_id)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		orderId, err := OrderItemOrderCreator(ctx, store, models.Order{Table_id: &table.Table_id}, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while opening order"})
+			return
+		}
+
+		from := entry.Status
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		entry.Status = models.WaitlistSeated
+		entry.Seated_at = &now
+		entry.Table_id = &table.Table_id
+		entry.Order_id = &orderId
+		entry.Updated_at = now
+		if err := updateWaitlistEntry(ctx, store, c, entry, from); err != nil {
+			// the party was seated or removed meanwhile; drop the order
+			// opened for them
+			store.Orders.UpdateStatus(ctx, orderId, models.OrderStatusChange{
+				From:       models.OrderStatusOpen,
+				To:         models.OrderStatusCancelled,
+				Changed_by: c.GetString("uid"),
+				Changed_at: now,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// CancelWaitlistEntry takes a party that left off the waitlist.
+func CancelWaitlistEntry(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(requestContext(c), 100*time.Second)
+		defer cancel()
+
+		entry, status, err := findWaitingEntry(ctx, store, c.Param("id"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		from := entry.Status
+		entry.Status = models.WaitlistCancelled
+		entry.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if err := updateWaitlistEntry(ctx, store, c, entry, from); err != nil {
+			return
+		}
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// findWaitingEntry looks up a party that is still in the queue. On failure
+// it also returns the HTTP status to answer with.
+func findWaitingEntry(ctx context.Context, store *repository.Store, entryId string) (models.WaitlistEntry, int, error) {
+	entry, err := store.Waitlist.FindByID(ctx, entryId)
+	if err == repository.ErrNotFound {
+		return entry, http.StatusNotFound, errors.New("waitlist entry was not found")
+	}
+	if err != nil {
+		return entry, http.StatusInternalServerError, errors.New("Error fetching waitlist entry")
+	}
+	if entry.Status != models.WaitlistWaiting && entry.Status != models.WaitlistNotified {
+		return entry, http.StatusConflict, errors.New("party is no longer waiting")
+	}
+	return entry, http.StatusOK, nil
+}
+
+// updateWaitlistEntry saves entry if it is still at from, answering the
+// request itself when that fails.
+func updateWaitlistEntry(ctx context.Context, store *repository.Store, c *gin.Context, entry models.WaitlistEntry, from string) error {
+	err := store.Waitlist.Update(ctx, entry, from)
+	if err == repository.ErrConflict {
+		c.JSON(http.StatusConflict, gin.H{"error": "waitlist entry was updated by someone else, reload and retry"})
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
+	}
+	return err
+}
+
+// seatingTable picks where a party of party sits now: the requested table
+// if it seats them and is neither occupied nor about to be taken by a
+// reservation, or else the smallest such table. On failure it also returns
+// the HTTP status to answer with.
+func seatingTable(ctx context.Context, store *repository.Store, bookings booking.Config, party int, requested string) (models.Table, int, error) {
+	tables, err := store.Tables.List(ctx)
+	if err != nil {
+		return models.Table{}, http.StatusInternalServerError, err
+	}
+	orders, err := store.Orders.List(ctx)
+	if err != nil {
+		return models.Table{}, http.StatusInternalServerError, err
+	}
+	now := time.Now()
+	end := now.Add(bookings.TurnTime(party))
+	reservations, err := store.Reservations.List(ctx, now, end)
+	if err != nil {
+		return models.Table{}, http.StatusInternalServerError, err
+	}
+
+	occupied := map[string]bool{}
+	for _, order := range orders {
+		if order.Table_id != nil && booking.Occupies(order) {
+			occupied[*order.Table_id] = true
+		}
+	}
+	free := []models.Table{}
+	for _, table := range booking.FreeTables(tables, reservations, party, now, end, "") {
+		if !occupied[table.Table_id] && table.Status != models.TableCleaning {
+			free = append(free, table)
+		}
+	}
+
+	if requested == "" {
+		if len(free) == 0 {
+			return models.Table{}, http.StatusConflict, errors.New("no table is free for a party of " + strconv.Itoa(party))
+		}
+		return free[0], http.StatusOK, nil
+	}
+	table, err := store.Tables.FindByID(ctx, requested)
+	if err == repository.ErrNotFound {
+		return table, http.StatusNotFound, errors.New("table was not found")
+	}
+	if err != nil {
+		return table, http.StatusInternalServerError, err
+	}
+	switch {
+	case !booking.Seats(table, party):
+		return table, http.StatusConflict, errors.New("table does not seat a party of " + strconv.Itoa(party))
+	case occupied[table.Table_id]:
+		return table, http.StatusConflict, errors.New("table is occupied")
+	case table.Status == models.TableCleaning:
+		return table, http.StatusConflict, errors.New("table is being cleaned")
+	case table.Status == models.TableOutOfService:
+		return table, http.StatusConflict, errors.New("table is out of service")
+	case !containsTable(free, table.Table_id):
+		return table, http.StatusConflict, errors.New("table is reserved soon")
+	}
+	return table, http.StatusOK, nil
+}
+
+// waitEstimator quotes waits against one snapshot of the tables and orders.
+type waitEstimator struct {
+	bookings booking.Config
+	tables   []models.Table
+	orders   []models.Order
+	now      time.Time
+}
+
+func newWaitEstimator(ctx context.Context, store *repository.Store, bookings booking.Config) (waitEstimator, error) {
+	tables, err := store.Tables.List(ctx)
+	if err != nil {
+		return waitEstimator{}, err
+	}
+	orders, err := store.Orders.List(ctx)
+	if err != nil {
+		return waitEstimator{}, err
+	}
+	return waitEstimator{bookings: bookings, tables: tables, orders: orders, now: time.Now()}, nil
+}
+
+// minutes is the wait of a party of party behind ahead, rounded up to five
+// minutes the way hosts quote it, or nil if no table seats the party.
+func (e waitEstimator) minutes(ahead []models.WaitlistEntry, party int) *int {
+	wait, ok := e.bookings.EstimateWait(e.tables, e.orders, ahead, party, e.now)
+	if !ok {
+		return nil
+	}
+	minutes := int((wait + 5*time.Minute - time.Nanosecond) / (5 * time.Minute) * 5)
+	return &minutes
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type apiKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) FindByID(ctx context.Context, keyId string) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"key_id": keyId})).Decode(&key)
+	return key, notFound(err)
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"key_hash": keyHash}).Decode(&key)
+	return key, notFound(err)
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key models.APIKey) error {
+	key.Tenant_id = repository.TenantID(ctx)
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, keyId string, at time.Time) error {
+	if _, err := r.FindByID(ctx, keyId); err != nil {
+		return err
+	}
+	_, err := r.collection.UpdateOne(ctx, owned(ctx, bson.M{"key_id": keyId, "revoked_at": nil}),
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revoked_at", Value: at}}}})
+	return err
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, keyId string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"key_id": keyId},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "last_used_at", Value: at}}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type auditEventRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *auditEventRepository) List(ctx context.Context, page repository.Page) ([]models.AuditEvent, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(page.Skip)
+	if page.Limit > 0 {
+		opts.SetLimit(page.Limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []models.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *auditEventRepository) Create(ctx context.Context, event models.AuditEvent) error {
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
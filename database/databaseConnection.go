@@ -2,15 +2,21 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// databaseName is the database of a single-outlet deployment, and of the
+// default tenant.
+const databaseName = "restaurant"
+
 func DBInsance() *mongo.Client {
 	MongoDb := os.Getenv("MONGOURI")
 	fmt.Println("Mongo URI:", MongoDb)
@@ -29,12 +35,37 @@ func DBInsance() *mongo.Client {
 		panic(err)
 	}
 	fmt.Println("Connected to MongoDB...")
+
+	if err := requireTransactions(ctx, client); err != nil {
+		panic(err)
+	}
+	err = Migrate(ctx, client.Database(databaseName))
+	if err != nil {
+		panic(err)
+	}
 	return client
 }
 
-var Client *mongo.Client = DBInsance()
+// requireTransactions fails unless the server can run multi-document
+// transactions, which orders, invoices, payments, table merges and
+// reservations are written in. A standalone mongod cannot; a replica set,
+// even one of a single node, or a sharded cluster can.
+func requireTransactions(ctx context.Context, client *mongo.Client) error {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return err
+	}
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return errors.New("MongoDB at MONGOURI is a standalone server, which cannot run the transactions orders, invoices, payments, table merges and reservations are written in; run it as a replica set (mongod --replSet rs0, then rs.initiate() once)")
+	}
+	return nil
+}
 
 func OpenCollection(client *mongo.Client, collectionName string) *mongo.Collection {
-	var collection *mongo.Collection = client.Database("restaurant").Collection(collectionName)
+	var collection *mongo.Collection = client.Database(databaseName).Collection(collectionName)
 	return collection
 }
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type foodRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *foodRepository) List(ctx context.Context, page repository.Page) ([]models.Food, int64, error) {
+	var limit any = page.Limit
+	if page.Limit < 1 {
+		limit = bson.D{{Key: "$size", Value: "$data"}}
+	}
+
+	matchStage := bson.D{{Key: "$match", Value: owned(ctx, bson.M{})}}
+	groupStage := bson.D{{
+		Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "total_count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "data", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
+		},
+	}}
+	projectStage := bson.D{
+		{
+			Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "total_count", Value: 1},
+				{Key: "food_items", Value: bson.D{
+					{Key: "$slice", Value: []any{"$data", page.Skip, limit}},
+				},
+				},
+			},
+		},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{matchStage, groupStage, projectStage})
+	if err != nil {
+		return nil, 0, err
+	}
+	var result []struct {
+		Total_count int64         `bson:"total_count"`
+		Food_items  []models.Food `bson:"food_items"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, 0, err
+	}
+	if len(result) == 0 {
+		return []models.Food{}, 0, nil
+	}
+	return result[0].Food_items, result[0].Total_count, nil
+}
+
+func (r *foodRepository) FindByID(ctx context.Context, foodId string) (models.Food, error) {
+	var food models.Food
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"food_id": foodId})).Decode(&food)
+	return food, notFound(err)
+}
+
+func (r *foodRepository) Create(ctx context.Context, food models.Food) error {
+	food.Tenant_id = repository.TenantID(ctx)
+	_, err := r.collection.InsertOne(ctx, food)
+	return err
+}
+
+func (r *foodRepository) Update(ctx context.Context, food models.Food) error {
+	food.Tenant_id = repository.TenantID(ctx)
+	result, err := r.collection.ReplaceOne(ctx, owned(ctx, bson.M{"food_id": food.Food_id}), food)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
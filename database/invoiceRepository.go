@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type invoiceRepository struct {
+	collection *mongo.Collection
+	payments   *mongo.Collection
+	orders     *orderRepository
+}
+
+func (r *invoiceRepository) List(ctx context.Context) ([]models.Invoice, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	invoices := []models.Invoice{}
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) FindByID(ctx context.Context, invoiceId string) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"invoice_id": invoiceId})).Decode(&invoice)
+	return invoice, notFound(err)
+}
+
+// Create moves the order first, so a concurrent invoice of the same order
+// conflicts instead of both being stored.
+func (r *invoiceRepository) Create(ctx context.Context, invoice models.Invoice, payment models.Payment, change models.OrderStatusChange) error {
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		if err := r.orders.UpdateStatus(sc, *invoice.Order_id, change); err != nil {
+			return err
+		}
+		if _, err := r.FindByOrderID(sc, *invoice.Order_id); err == nil {
+			return repository.ErrConflict
+		} else if err != repository.ErrNotFound {
+			return err
+		}
+		invoice.Tenant_id = repository.TenantID(sc)
+		if _, err := r.collection.InsertOne(sc, invoice); err != nil {
+			return err
+		}
+		payment.Tenant_id = repository.TenantID(sc)
+		_, err := r.payments.InsertOne(sc, payment)
+		return err
+	})
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, invoice models.Invoice) error {
+	invoice.Tenant_id = repository.TenantID(ctx)
+	result, err := r.collection.ReplaceOne(ctx, owned(ctx, bson.M{"invoice_id": invoice.Invoice_id}), invoice)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *invoiceRepository) FindByOrderID(ctx context.Context, orderId string) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"order_id": orderId})).Decode(&invoice)
+	return invoice, notFound(err)
+}
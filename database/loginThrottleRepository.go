@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type loginThrottleRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *loginThrottleRepository) Find(ctx context.Context, key string) (models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.collection.FindOne(ctx, bson.M{"key": key}).Decode(&throttle)
+	return throttle, notFound(err)
+}
+
+// RecordFailure uses an update pipeline so the reset and the increment happen
+// in one atomic upsert, whichever instance gets there first.
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, at time.Time, since time.Time) (models.LoginThrottle, error) {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "key", Value: key},
+			{Key: "failures", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$lt", Value: bson.A{"$last_failure_at", since}}},
+				1,
+				bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$failures", 0}}}, 1}}},
+			}}}},
+			{Key: "last_failure_at", Value: at},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var throttle models.LoginThrottle
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"key": key}, update, opts).Decode(&throttle)
+	return throttle, err
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"key": key},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "locked_until", Value: until}}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *loginThrottleRepository) Clear(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"key": key})
+	return err
+}
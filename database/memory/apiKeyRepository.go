@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type apiKeyRepository struct {
+	keys *collection[models.APIKey]
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	return r.keys.owned(repository.TenantID(ctx)), nil
+}
+
+func (r *apiKeyRepository) FindByID(ctx context.Context, keyId string) (models.APIKey, error) {
+	key, ok := r.keys.findOwned(repository.TenantID(ctx), keyId)
+	if !ok {
+		return key, repository.ErrNotFound
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	for _, key := range r.keys.all() {
+		if key.Key_hash == keyHash {
+			return key, nil
+		}
+	}
+	return models.APIKey{}, repository.ErrNotFound
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key models.APIKey) error {
+	key.Tenant_id = repository.TenantID(ctx)
+	return r.keys.insert(key.Key_id, key)
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, keyId string, at time.Time) error {
+	r.keys.mu.Lock()
+	defer r.keys.mu.Unlock()
+	key, ok := r.keys.items[keyId]
+	if !ok || key.Tenant_id != repository.TenantID(ctx) {
+		return repository.ErrNotFound
+	}
+	if key.Revoked_at == nil {
+		key.Revoked_at = &at
+		r.keys.items[keyId] = key
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, keyId string, at time.Time) error {
+	r.keys.mu.Lock()
+	defer r.keys.mu.Unlock()
+	key, ok := r.keys.items[keyId]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	key.Last_used_at = &at
+	r.keys.items[keyId] = key
+	return nil
+}
@@ -0,0 +1,22 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"slices"
+)
+
+type auditEventRepository struct {
+	events *collection[models.AuditEvent]
+}
+
+func (r *auditEventRepository) List(ctx context.Context, page repository.Page) ([]models.AuditEvent, error) {
+	events := r.events.owned(repository.TenantID(ctx))
+	slices.Reverse(events)
+	return window(events, page.Skip, page.Limit), nil
+}
+
+func (r *auditEventRepository) Create(ctx context.Context, event models.AuditEvent) error {
+	return r.events.insert(event.Event_id, event)
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+)
+
+var errDuplicateKey = errors.New("duplicate key")
+
+// collection keeps records in insertion order, the same order a Mongo
+// collection scan returns them in. Collections of tenant owned records know
+// how to read the owner of a record.
+type collection[T any] struct {
+	mu     sync.RWMutex
+	ids    []string
+	items  map[string]T
+	tenant func(T) string
+}
+
+func newCollection[T any]() *collection[T] {
+	return &collection[T]{items: map[string]T{}}
+}
+
+func newTenantCollection[T any](tenant func(T) string) *collection[T] {
+	return &collection[T]{items: map[string]T{}, tenant: tenant}
+}
+
+func (c *collection[T]) all() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	result := make([]T, 0, len(c.ids))
+	for _, id := range c.ids {
+		result = append(result, c.items[id])
+	}
+	return result
+}
+
+func (c *collection[T]) find(id string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, ok := c.items[id]
+	return item, ok
+}
+
+// owned is all limited to the records of tenantId.
+func (c *collection[T]) owned(tenantId string) []T {
+	result := []T{}
+	for _, item := range c.all() {
+		if c.tenant(item) == tenantId {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// findOwned is find limited to the records of tenantId.
+func (c *collection[T]) findOwned(tenantId string, id string) (T, bool) {
+	item, ok := c.find(id)
+	if !ok || c.tenant(item) != tenantId {
+		var zero T
+		return zero, false
+	}
+	return item, true
+}
+
+func (c *collection[T]) insert(id string, item T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[id]; ok {
+		return errDuplicateKey
+	}
+	c.ids = append(c.ids, id)
+	c.items[id] = item
+	return nil
+}
+
+func (c *collection[T]) replace(id string, item T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[id]; !ok {
+		return false
+	}
+	c.items[id] = item
+	return true
+}
+
+// replaceOwned is replace limited to the records of tenantId.
+func (c *collection[T]) replaceOwned(tenantId string, id string, item T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stored, ok := c.items[id]; !ok || c.tenant(stored) != tenantId {
+		return false
+	}
+	c.items[id] = item
+	return true
+}
+
+func (c *collection[T]) remove(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[id]; !ok {
+		return false
+	}
+	delete(c.items, id)
+	for i, existing := range c.ids {
+		if existing == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// window applies a skip/limit page to items. A limit below one keeps
+// everything after skip.
+func window[T any](items []T, skip int64, limit int64) []T {
+	if skip >= int64(len(items)) {
+		return []T{}
+	}
+	items = items[skip:]
+	if limit > 0 && limit < int64(len(items)) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type foodRepository struct {
+	foods *collection[models.Food]
+}
+
+func (r *foodRepository) List(ctx context.Context, page repository.Page) ([]models.Food, int64, error) {
+	all := r.foods.owned(repository.TenantID(ctx))
+	return window(all, page.Skip, page.Limit), int64(len(all)), nil
+}
+
+func (r *foodRepository) FindByID(ctx context.Context, foodId string) (models.Food, error) {
+	food, ok := r.foods.findOwned(repository.TenantID(ctx), foodId)
+	if !ok {
+		return food, repository.ErrNotFound
+	}
+	return food, nil
+}
+
+func (r *foodRepository) Create(ctx context.Context, food models.Food) error {
+	food.Tenant_id = repository.TenantID(ctx)
+	return r.foods.insert(food.Food_id, food)
+}
+
+func (r *foodRepository) Update(ctx context.Context, food models.Food) error {
+	food.Tenant_id = repository.TenantID(ctx)
+	if !r.foods.replaceOwned(food.Tenant_id, food.Food_id, food) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type invoiceRepository struct {
+	invoices *collection[models.Invoice]
+	payments *collection[models.Payment]
+	orders   *collection[models.Order]
+}
+
+func (r *invoiceRepository) List(ctx context.Context) ([]models.Invoice, error) {
+	return r.invoices.owned(repository.TenantID(ctx)), nil
+}
+
+func (r *invoiceRepository) FindByID(ctx context.Context, invoiceId string) (models.Invoice, error) {
+	invoice, ok := r.invoices.findOwned(repository.TenantID(ctx), invoiceId)
+	if !ok {
+		return invoice, repository.ErrNotFound
+	}
+	return invoice, nil
+}
+
+// Create locks orders before invoices and invoices before payments, as
+// orderRepository locks orders before order items.
+func (r *invoiceRepository) Create(ctx context.Context, invoice models.Invoice, payment models.Payment, change models.OrderStatusChange) error {
+	tenantId := repository.TenantID(ctx)
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	r.invoices.mu.Lock()
+	defer r.invoices.mu.Unlock()
+	r.payments.mu.Lock()
+	defer r.payments.mu.Unlock()
+	order, ok := r.orders.items[*invoice.Order_id]
+	if !ok || order.Tenant_id != tenantId {
+		return repository.ErrNotFound
+	}
+	if order.Status != change.From {
+		return repository.ErrConflict
+	}
+	if _, ok := r.invoices.items[invoice.Invoice_id]; ok {
+		return errDuplicateKey
+	}
+	for _, stored := range r.invoices.items {
+		if stored.Tenant_id == tenantId && stored.Order_id != nil && *stored.Order_id == *invoice.Order_id {
+			return repository.ErrConflict
+		}
+	}
+	invoice.Tenant_id = tenantId
+	r.invoices.ids = append(r.invoices.ids, invoice.Invoice_id)
+	r.invoices.items[invoice.Invoice_id] = invoice
+	payment.Tenant_id = tenantId
+	r.payments.ids = append(r.payments.ids, payment.Payment_id)
+	r.payments.items[payment.Payment_id] = payment
+	order.Status = change.To
+	order.Updated_at = change.Changed_at
+	order.Status_history = append(append([]models.OrderStatusChange{}, order.Status_history...), change)
+	r.orders.items[order.Order_id] = order
+	return nil
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, invoice models.Invoice) error {
+	invoice.Tenant_id = repository.TenantID(ctx)
+	if !r.invoices.replaceOwned(invoice.Tenant_id, invoice.Invoice_id, invoice) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *invoiceRepository) FindByOrderID(ctx context.Context, orderId string) (models.Invoice, error) {
+	for _, invoice := range r.invoices.owned(repository.TenantID(ctx)) {
+		if invoice.Order_id != nil && *invoice.Order_id == orderId {
+			return invoice, nil
+		}
+	}
+	return models.Invoice{}, repository.ErrNotFound
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type loginThrottleRepository struct {
+	throttles *collection[models.LoginThrottle]
+}
+
+func (r *loginThrottleRepository) Find(ctx context.Context, key string) (models.LoginThrottle, error) {
+	throttle, ok := r.throttles.find(key)
+	if !ok {
+		return throttle, repository.ErrNotFound
+	}
+	return throttle, nil
+}
+
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, at time.Time, since time.Time) (models.LoginThrottle, error) {
+	r.throttles.mu.Lock()
+	defer r.throttles.mu.Unlock()
+	throttle, ok := r.throttles.items[key]
+	if !ok {
+		r.throttles.ids = append(r.throttles.ids, key)
+		throttle.Key = key
+	}
+	if throttle.Last_failure_at.Before(since) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	throttle.Last_failure_at = at
+	r.throttles.items[key] = throttle
+	return throttle, nil
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.throttles.mu.Lock()
+	defer r.throttles.mu.Unlock()
+	throttle, ok := r.throttles.items[key]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	throttle.Locked_until = &until
+	r.throttles.items[key] = throttle
+	return nil
+}
+
+func (r *loginThrottleRepository) Clear(ctx context.Context, key string) error {
+	r.throttles.remove(key)
+	return nil
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type menuRepository struct {
+	menus *collection[models.Menu]
+}
+
+func (r *menuRepository) List(ctx context.Context) ([]models.Menu, error) {
+	return r.menus.owned(repository.TenantID(ctx)), nil
+}
+
+func (r *menuRepository) FindByID(ctx context.Context, menuId string) (models.Menu, error) {
+	menu, ok := r.menus.findOwned(repository.TenantID(ctx), menuId)
+	if !ok {
+		return menu, repository.ErrNotFound
+	}
+	return menu, nil
+}
+
+func (r *menuRepository) Create(ctx context.Context, menu models.Menu) error {
+	menu.Tenant_id = repository.TenantID(ctx)
+	return r.menus.insert(menu.Menu_id, menu)
+}
+
+func (r *menuRepository) Update(ctx context.Context, menu models.Menu) error {
+	menu.Tenant_id = repository.TenantID(ctx)
+	if !r.menus.replaceOwned(menu.Tenant_id, menu.Menu_id, menu) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type noteRepository struct {
+	notes *collection[models.Note]
+}
+
+func (r *noteRepository) List(ctx context.Context) ([]models.Note, error) {
+	return r.notes.owned(repository.TenantID(ctx)), nil
+}
+
+func (r *noteRepository) FindByID(ctx context.Context, noteId string) (models.Note, error) {
+	note, ok := r.notes.findOwned(repository.TenantID(ctx), noteId)
+	if !ok {
+		return note, repository.ErrNotFound
+	}
+	return note, nil
+}
+
+func (r *noteRepository) Create(ctx context.Context, note models.Note) error {
+	note.Tenant_id = repository.TenantID(ctx)
+	return r.notes.insert(note.Note_id, note)
+}
+
+func (r *noteRepository) Update(ctx context.Context, note models.Note) error {
+	note.Tenant_id = repository.TenantID(ctx)
+	if !r.notes.replaceOwned(note.Tenant_id, note.Note_id, note) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+	"slices"
+)
+
+type orderItemRepository struct {
+	orderItems *collection[models.OrderItem]
+	foods      *foodRepository
+	menus      *menuRepository
+	orders     *orderRepository
+	tables     *tableRepository
+}
+
+func (r *orderItemRepository) List(ctx context.Context) ([]models.OrderItem, error) {
+	return r.orderItems.owned(repository.TenantID(ctx)), nil
+}
+
+func (r *orderItemRepository) FindByID(ctx context.Context, orderItemId string) (models.OrderItem, error) {
+	orderItem, ok := r.orderItems.findOwned(repository.TenantID(ctx), orderItemId)
+	if !ok {
+		return orderItem, repository.ErrNotFound
+	}
+	return orderItem, nil
+}
+
+func (r *orderItemRepository) ListByOrder(ctx context.Context, orderId string) ([]models.OrderItem, error) {
+	items := []models.OrderItem{}
+	for _, item := range r.orderItems.owned(repository.TenantID(ctx)) {
+		if item.Order_id == orderId {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *orderItemRepository) CreateMany(ctx context.Context, orderItems []models.OrderItem) error {
+	for _, item := range orderItems {
+		item.Tenant_id = repository.TenantID(ctx)
+		if err := r.orderItems.insert(item.Order_item_id, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update locks orders before order items, like the order repository does.
+func (r *orderItemRepository) Update(ctx context.Context, orderItem models.OrderItem) error {
+	r.orders.orders.mu.Lock()
+	defer r.orders.orders.mu.Unlock()
+	r.orderItems.mu.Lock()
+	defer r.orderItems.mu.Unlock()
+	orderItem.Tenant_id = repository.TenantID(ctx)
+	stored, ok := r.orderItems.items[orderItem.Order_item_id]
+	if !ok || stored.Tenant_id != orderItem.Tenant_id {
+		return repository.ErrNotFound
+	}
+	order, ok := r.orders.orders.items[stored.Order_id]
+	if !ok || !slices.Contains(models.ItemEditableOrderStatuses, order.Status) {
+		return repository.ErrConflict
+	}
+	orderItem.Order_id = stored.Order_id
+	r.orderItems.items[orderItem.Order_item_id] = orderItem
+	return nil
+}
+
+// ItemsByOrder mirrors the Mongo pipeline: items are left-joined with their
+// food, order and table, then grouped by order, table id and table number.
+func (r *orderItemRepository) ItemsByOrder(ctx context.Context, id string) ([]views.OrderItemsView, error) {
+	tenantId := repository.TenantID(ctx)
+	lines := []views.OrderItemLine{}
+	for _, item := range r.orderItems.owned(tenantId) {
+		if item.Order_id != id {
+			continue
+		}
+
+		var line views.OrderItemLine
+		line.Total_count = 1
+		line.Order_item_id = item.Order_item_id
+		line.Seat = item.Seat
+		line.Size = item.Size
+		line.Modifiers = item.Modifiers
+		line.Quantity = 1
+		if item.Quantity != nil {
+			line.Quantity = *item.Quantity
+		}
+		if item.Food_id != nil {
+			if food, ok := r.foods.foods.findOwned(tenantId, *item.Food_id); ok {
+				line.Food_id = food.Food_id
+				price, err := item.UnitPrice(food)
+				if err != nil {
+					return nil, err
+				}
+				if price != nil {
+					amount, err := price.Mul(int64(line.Quantity))
+					if err != nil {
+						return nil, err
+					}
+					line.Price, line.Amount = price, &amount
+				}
+				line.Food_name = food.Name
+				line.Food_image = food.Food_image
+				if food.Menu_id != nil {
+					if menu, ok := r.menus.menus.findOwned(tenantId, *food.Menu_id); ok {
+						line.Menu_category = menu.Category
+					}
+				}
+			}
+		}
+		if order, ok := r.orders.orders.findOwned(tenantId, item.Order_id); ok {
+			line.Order_id = order.Order_id
+			if order.Table_id != nil {
+				if table, ok := r.tables.tables.findOwned(tenantId, *order.Table_id); ok {
+					line.Table_id = table.Table_id
+					line.Table_number = table.Table_number
+				}
+			}
+		}
+		lines = append(lines, line)
+	}
+	return views.GroupOrderItems(lines)
+}
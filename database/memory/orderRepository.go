@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"slices"
+	"time"
+)
+
+type orderRepository struct {
+	orders     *collection[models.Order]
+	orderItems *collection[models.OrderItem]
+	tickets    *collection[models.Ticket]
+	tables     *collection[models.Table]
+}
+
+func (r *orderRepository) List(ctx context.Context) ([]models.Order, error) {
+	return r.orders.owned(repository.TenantID(ctx)), nil
+}
+
+func (r *orderRepository) FindByID(ctx context.Context, orderId string) (models.Order, error) {
+	order, ok := r.orders.findOwned(repository.TenantID(ctx), orderId)
+	if !ok {
+		return order, repository.ErrNotFound
+	}
+	return order, nil
+}
+
+func (r *orderRepository) ListAtTable(ctx context.Context, tableId string, statuses []string) ([]models.Order, error) {
+	orders := []models.Order{}
+	for _, order := range r.orders.owned(repository.TenantID(ctx)) {
+		status := order.Status
+		if status == "" {
+			status = models.OrderStatusOpen
+		}
+		if order.Table_id != nil && *order.Table_id == tableId && slices.Contains(statuses, status) {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) Create(ctx context.Context, order models.Order, orderItems ...models.OrderItem) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	r.orderItems.mu.Lock()
+	defer r.orderItems.mu.Unlock()
+	if _, ok := r.orders.items[order.Order_id]; ok {
+		return errDuplicateKey
+	}
+	for _, item := range orderItems {
+		if _, ok := r.orderItems.items[item.Order_item_id]; ok {
+			return errDuplicateKey
+		}
+	}
+	order.Tenant_id = repository.TenantID(ctx)
+	r.orders.ids = append(r.orders.ids, order.Order_id)
+	r.orders.items[order.Order_id] = order
+	for _, item := range orderItems {
+		item.Tenant_id = order.Tenant_id
+		r.orderItems.ids = append(r.orderItems.ids, item.Order_item_id)
+		r.orderItems.items[item.Order_item_id] = item
+	}
+	return nil
+}
+
+func (r *orderRepository) Update(ctx context.Context, order models.Order) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	stored, ok := r.orders.items[order.Order_id]
+	if !ok || stored.Tenant_id != repository.TenantID(ctx) {
+		return repository.ErrNotFound
+	}
+	order.Status = stored.Status
+	order.Status_history = stored.Status_history
+	order.Tenant_id = stored.Tenant_id
+	r.orders.items[order.Order_id] = order
+	return nil
+}
+
+func (r *orderRepository) UpdateStatus(ctx context.Context, orderId string, change models.OrderStatusChange) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	order, ok := r.orders.items[orderId]
+	if !ok || order.Tenant_id != repository.TenantID(ctx) {
+		return repository.ErrNotFound
+	}
+	if order.Status != change.From {
+		return repository.ErrConflict
+	}
+	order.Status = change.To
+	order.Updated_at = change.Changed_at
+	order.Status_history = append(append([]models.OrderStatusChange{}, order.Status_history...), change)
+	r.orders.items[orderId] = order
+	if change.To == models.OrderStatusCancelled || change.To == models.OrderStatusVoided {
+		r.voidTickets(orderId, change.Changed_at)
+	}
+	return nil
+}
+
+func (r *orderRepository) SendToKitchen(ctx context.Context, orderId string, change models.OrderStatusChange, tickets []models.Ticket) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	r.tickets.mu.Lock()
+	defer r.tickets.mu.Unlock()
+	order, ok := r.orders.items[orderId]
+	if !ok || order.Tenant_id != repository.TenantID(ctx) {
+		return repository.ErrNotFound
+	}
+	if order.Status != change.From {
+		return repository.ErrConflict
+	}
+	for _, ticket := range tickets {
+		if _, ok := r.tickets.items[ticket.Ticket_id]; ok {
+			return errDuplicateKey
+		}
+	}
+	order.Status = change.To
+	order.Updated_at = change.Changed_at
+	order.Status_history = append(append([]models.OrderStatusChange{}, order.Status_history...), change)
+	r.orders.items[orderId] = order
+	for _, ticket := range tickets {
+		ticket.Tenant_id = order.Tenant_id
+		r.tickets.ids = append(r.tickets.ids, ticket.Ticket_id)
+		r.tickets.items[ticket.Ticket_id] = ticket
+	}
+	return nil
+}
+
+func (r *orderRepository) Transfer(ctx context.Context, orderId string, tableId string, at time.Time) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	order, err := r.editable(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	order.Table_id = &tableId
+	order.Updated_at = at
+	r.orders.items[orderId] = order
+	r.orderItems.mu.RLock()
+	defer r.orderItems.mu.RUnlock()
+	r.pointTickets(orderId, at)
+	return nil
+}
+
+// Create, MoveItems, SplitOff and Merge lock orders before order items, and
+// both before tickets, so they cannot deadlock each other.
+func (r *orderRepository) MoveItems(ctx context.Context, from string, to string, orderItemIds []string, at time.Time) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	r.orderItems.mu.Lock()
+	defer r.orderItems.mu.Unlock()
+	for _, orderId := range []string{from, to} {
+		if _, err := r.editable(ctx, orderId); err != nil {
+			return err
+		}
+	}
+	for _, orderItemId := range orderItemIds {
+		if item, ok := r.orderItems.items[orderItemId]; !ok || item.Order_id != from {
+			return repository.ErrConflict
+		}
+	}
+	for _, orderItemId := range orderItemIds {
+		item := r.orderItems.items[orderItemId]
+		item.Order_id = to
+		item.Updated_at = at
+		r.orderItems.items[orderItemId] = item
+	}
+	r.touch(at, from, to)
+	r.pointTickets(to, at)
+	return nil
+}
+
+func (r *orderRepository) SplitOff(ctx context.Context, from string, to models.Order, orderItemIds []string, at time.Time) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	r.orderItems.mu.Lock()
+	defer r.orderItems.mu.Unlock()
+	if _, err := r.editable(ctx, from); err != nil {
+		return err
+	}
+	if _, ok := r.orders.items[to.Order_id]; ok {
+		return errDuplicateKey
+	}
+	for _, orderItemId := range orderItemIds {
+		if item, ok := r.orderItems.items[orderItemId]; !ok || item.Order_id != from {
+			return repository.ErrConflict
+		}
+	}
+	to.Tenant_id = repository.TenantID(ctx)
+	r.orders.ids = append(r.orders.ids, to.Order_id)
+	r.orders.items[to.Order_id] = to
+	for _, orderItemId := range orderItemIds {
+		item := r.orderItems.items[orderItemId]
+		item.Order_id = to.Order_id
+		item.Updated_at = at
+		r.orderItems.items[orderItemId] = item
+	}
+	r.touch(at, from)
+	r.pointTickets(to.Order_id, at)
+	return nil
+}
+
+func (r *orderRepository) Merge(ctx context.Context, from []string, into string, tableId string, change models.OrderStatusChange) error {
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	r.orderItems.mu.Lock()
+	defer r.orderItems.mu.Unlock()
+	for _, orderId := range append([]string{into}, from...) {
+		if _, err := r.editable(ctx, orderId); err != nil {
+			return err
+		}
+	}
+	merged := map[string]bool{}
+	for _, orderId := range from {
+		order := r.orders.items[orderId]
+		orderChange := change
+		orderChange.From = order.Status
+		order.Status = change.To
+		order.Updated_at = change.Changed_at
+		order.Status_history = append(append([]models.OrderStatusChange{}, order.Status_history...), orderChange)
+		r.orders.items[orderId] = order
+		merged[orderId] = true
+	}
+	for id, item := range r.orderItems.items {
+		if merged[item.Order_id] {
+			item.Order_id = into
+			item.Updated_at = change.Changed_at
+			r.orderItems.items[id] = item
+		}
+	}
+	order := r.orders.items[into]
+	order.Table_id = &tableId
+	order.Updated_at = change.Changed_at
+	r.orders.items[into] = order
+	r.pointTickets(into, change.Changed_at)
+	return nil
+}
+
+// editable returns the order if it belongs to the tenant of ctx and can
+// still change. The caller holds the lock.
+func (r *orderRepository) editable(ctx context.Context, orderId string) (models.Order, error) {
+	order, ok := r.orders.items[orderId]
+	if !ok || order.Tenant_id != repository.TenantID(ctx) {
+		return order, repository.ErrNotFound
+	}
+	if !slices.Contains(models.EditableOrderStatuses, order.Status) {
+		return order, repository.ErrConflict
+	}
+	return order, nil
+}
+
+// voidTickets voids the open tickets of the order orderId. The caller holds
+// the lock on orders.
+func (r *orderRepository) voidTickets(orderId string, at time.Time) {
+	r.tickets.mu.Lock()
+	defer r.tickets.mu.Unlock()
+	for id, ticket := range r.tickets.items {
+		if ticket.Order_id == orderId && slices.Contains(models.OpenTicketStatuses, ticket.Status) {
+			ticket.Status = models.TicketStatusVoided
+			ticket.Updated_at = at
+			r.tickets.items[id] = ticket
+		}
+	}
+}
+
+// pointTickets points the tickets of the items on the order orderId at that
+// order and the number of its table. The caller holds the locks on orders
+// and order items.
+func (r *orderRepository) pointTickets(orderId string, at time.Time) {
+	order := r.orders.items[orderId]
+	var tableNumber *int
+	if order.Table_id != nil {
+		if table, ok := r.tables.find(*order.Table_id); ok {
+			tableNumber = table.Table_number
+		}
+	}
+	r.tickets.mu.Lock()
+	defer r.tickets.mu.Unlock()
+	for id, ticket := range r.tickets.items {
+		item, ok := r.orderItems.items[ticket.Order_item_id]
+		if !ok || item.Order_id != orderId {
+			continue
+		}
+		ticket.Order_id = orderId
+		ticket.Table_number = tableNumber
+		ticket.Updated_at = at
+		r.tickets.items[id] = ticket
+	}
+}
+
+// touch sets Updated_at of orders. The caller holds the lock.
+func (r *orderRepository) touch(at time.Time, orderIds ...string) {
+	for _, orderId := range orderIds {
+		order := r.orders.items[orderId]
+		order.Updated_at = at
+		r.orders.items[orderId] = order
+	}
+}
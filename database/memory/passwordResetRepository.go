@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type passwordResetRepository struct {
+	resets *collection[models.PasswordReset]
+}
+
+func (r *passwordResetRepository) FindByTokenHash(ctx context.Context, tokenHash string) (models.PasswordReset, error) {
+	for _, reset := range r.resets.all() {
+		if reset.Token_hash == tokenHash {
+			return reset, nil
+		}
+	}
+	return models.PasswordReset{}, repository.ErrNotFound
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, reset models.PasswordReset) error {
+	return r.resets.insert(reset.Reset_id, reset)
+}
+
+func (r *passwordResetRepository) MarkUsed(ctx context.Context, resetId string, at time.Time) error {
+	r.resets.mu.Lock()
+	defer r.resets.mu.Unlock()
+	reset, ok := r.resets.items[resetId]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if reset.Used_at != nil {
+		return repository.ErrConflict
+	}
+	reset.Used_at = &at
+	r.resets.items[resetId] = reset
+	return nil
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type paymentRepository struct {
+	payments *collection[models.Payment]
+	orders   *collection[models.Order]
+}
+
+func (r *paymentRepository) ListByInvoice(ctx context.Context, invoiceId string) ([]models.Payment, error) {
+	payments := []models.Payment{}
+	for _, payment := range r.payments.owned(repository.TenantID(ctx)) {
+		if payment.Invoice_id == invoiceId {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) FindByID(ctx context.Context, paymentId string) (models.Payment, error) {
+	payment, ok := r.payments.findOwned(repository.TenantID(ctx), paymentId)
+	if !ok {
+		return payment, repository.ErrNotFound
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) Split(ctx context.Context, invoiceId string, paid int, payments []models.Payment, at time.Time) error {
+	tenantId := repository.TenantID(ctx)
+	r.payments.mu.Lock()
+	defer r.payments.mu.Unlock()
+	pending := []string{}
+	for _, id := range r.payments.ids {
+		payment := r.payments.items[id]
+		if payment.Tenant_id != tenantId || payment.Invoice_id != invoiceId {
+			continue
+		}
+		switch payment.Status {
+		case models.PaymentStatusPaid:
+			paid--
+		case models.PaymentStatusPending:
+			pending = append(pending, id)
+		}
+	}
+	if paid != 0 {
+		return repository.ErrConflict
+	}
+	for _, id := range pending {
+		payment := r.payments.items[id]
+		payment.Status = models.PaymentStatusVoided
+		payment.Updated_at = at
+		r.payments.items[id] = payment
+	}
+	for _, payment := range payments {
+		payment.Tenant_id = tenantId
+		r.payments.ids = append(r.payments.ids, payment.Payment_id)
+		r.payments.items[payment.Payment_id] = payment
+	}
+	return nil
+}
+
+// Pay locks orders before payments, as invoicing locks orders before
+// invoices.
+func (r *paymentRepository) Pay(ctx context.Context, paymentId string, method string, change models.OrderStatusChange) error {
+	tenantId := repository.TenantID(ctx)
+	r.orders.mu.Lock()
+	defer r.orders.mu.Unlock()
+	r.payments.mu.Lock()
+	defer r.payments.mu.Unlock()
+	payment, ok := r.payments.items[paymentId]
+	if !ok || payment.Tenant_id != tenantId {
+		return repository.ErrNotFound
+	}
+	if payment.Status != models.PaymentStatusPending {
+		return repository.ErrConflict
+	}
+	order, ok := r.orders.items[payment.Order_id]
+	if !ok || order.Tenant_id != tenantId || order.Status != change.From {
+		return repository.ErrConflict
+	}
+	payment.Status = models.PaymentStatusPaid
+	payment.Payment_method = &method
+	payment.Paid_at = &change.Changed_at
+	payment.Updated_at = change.Changed_at
+	r.payments.items[paymentId] = payment
+
+	for _, id := range r.payments.ids {
+		other := r.payments.items[id]
+		if other.Tenant_id == tenantId && other.Invoice_id == payment.Invoice_id && other.Status == models.PaymentStatusPending {
+			return nil
+		}
+	}
+	order.Status = change.To
+	order.Updated_at = change.Changed_at
+	order.Status_history = append(append([]models.OrderStatusChange{}, order.Status_history...), change)
+	r.orders.items[order.Order_id] = order
+	return nil
+}
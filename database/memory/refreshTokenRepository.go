@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type refreshTokenRepository struct {
+	tokens *collection[models.RefreshToken]
+}
+
+func (r *refreshTokenRepository) FindByID(ctx context.Context, tokenId string) (models.RefreshToken, error) {
+	token, ok := r.tokens.find(tokenId)
+	if !ok {
+		return token, repository.ErrNotFound
+	}
+	return token, nil
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token models.RefreshToken) error {
+	return r.tokens.insert(token.Token_id, token)
+}
+
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, tokenId string, at time.Time) error {
+	r.tokens.mu.Lock()
+	defer r.tokens.mu.Unlock()
+	token, ok := r.tokens.items[tokenId]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if token.Used_at != nil {
+		return repository.ErrConflict
+	}
+	token.Used_at = &at
+	r.tokens.items[tokenId] = token
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyId string, at time.Time) error {
+	r.tokens.mu.Lock()
+	defer r.tokens.mu.Unlock()
+	for id, token := range r.tokens.items {
+		if token.Family_id == familyId && token.Revoked_at == nil {
+			token.Revoked_at = &at
+			r.tokens.items[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeUser(ctx context.Context, userId string, at time.Time) error {
+	r.tokens.mu.Lock()
+	defer r.tokens.mu.Unlock()
+	for id, token := range r.tokens.items {
+		if token.User_id == userId && token.Revoked_at == nil {
+			token.Revoked_at = &at
+			r.tokens.items[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"sort"
+	"time"
+)
+
+type reservationRepository struct {
+	reservations *collection[models.Reservation]
+}
+
+func (r *reservationRepository) List(ctx context.Context, from time.Time, to time.Time) ([]models.Reservation, error) {
+	reservations := []models.Reservation{}
+	for _, reservation := range r.reservations.owned(repository.TenantID(ctx)) {
+		if reservation.Reserved_at.Before(to) && reservation.Ends_at.After(from) {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].Reserved_at.Before(*reservations[j].Reserved_at)
+	})
+	return reservations, nil
+}
+
+func (r *reservationRepository) FindByID(ctx context.Context, reservationId string) (models.Reservation, error) {
+	reservation, ok := r.reservations.findOwned(repository.TenantID(ctx), reservationId)
+	if !ok {
+		return reservation, repository.ErrNotFound
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) Create(ctx context.Context, reservation models.Reservation) error {
+	reservation.Tenant_id = repository.TenantID(ctx)
+	r.reservations.mu.Lock()
+	defer r.reservations.mu.Unlock()
+	if _, ok := r.reservations.items[reservation.Reservation_id]; ok {
+		return errDuplicateKey
+	}
+	if r.clashes(reservation) {
+		return repository.ErrConflict
+	}
+	r.reservations.ids = append(r.reservations.ids, reservation.Reservation_id)
+	r.reservations.items[reservation.Reservation_id] = reservation
+	return nil
+}
+
+func (r *reservationRepository) Update(ctx context.Context, reservation models.Reservation) error {
+	reservation.Tenant_id = repository.TenantID(ctx)
+	r.reservations.mu.Lock()
+	defer r.reservations.mu.Unlock()
+	stored, ok := r.reservations.items[reservation.Reservation_id]
+	if !ok || stored.Tenant_id != reservation.Tenant_id {
+		return repository.ErrNotFound
+	}
+	if r.clashes(reservation) {
+		return repository.ErrConflict
+	}
+	r.reservations.items[reservation.Reservation_id] = reservation
+	return nil
+}
+
+// clashes reports whether another booked reservation holds the table of
+// reservation while it would. The caller holds the lock.
+func (r *reservationRepository) clashes(reservation models.Reservation) bool {
+	if reservation.Status != models.ReservationBooked {
+		return false
+	}
+	for _, other := range r.reservations.items {
+		if other.Reservation_id == reservation.Reservation_id || other.Tenant_id != reservation.Tenant_id ||
+			other.Table_id != reservation.Table_id || other.Status != models.ReservationBooked {
+			continue
+		}
+		if other.Reserved_at.Before(reservation.Ends_at) && other.Ends_at.After(*reservation.Reserved_at) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+// NewStore returns empty in-memory repositories. Nothing is persisted
+// between runs; it exists for tests and local demos.
+func NewStore() *repository.Store {
+	foods := &foodRepository{foods: newTenantCollection(func(food models.Food) string { return food.Tenant_id })}
+	menus := &menuRepository{menus: newTenantCollection(func(menu models.Menu) string { return menu.Tenant_id })}
+	orderItems := newTenantCollection(func(item models.OrderItem) string { return item.Tenant_id })
+	tables := &tableRepository{tables: newTenantCollection(func(table models.Table) string { return table.Tenant_id })}
+	tickets := &ticketRepository{tickets: newTenantCollection(func(ticket models.Ticket) string { return ticket.Tenant_id })}
+	payments := newTenantCollection(func(payment models.Payment) string { return payment.Tenant_id })
+	orders := &orderRepository{
+		orders:     newTenantCollection(func(order models.Order) string { return order.Tenant_id }),
+		orderItems: orderItems,
+		tickets:    tickets.tickets,
+		tables:     tables.tables,
+	}
+	return &repository.Store{
+		Foods:  foods,
+		Menus:  menus,
+		Orders: orders,
+		OrderItems: &orderItemRepository{
+			orderItems: orderItems,
+			foods:      foods,
+			menus:      menus,
+			orders:     orders,
+			tables:     tables,
+		},
+		Tables:         tables,
+		Users:          &userRepository{users: newTenantCollection(func(user models.User) string { return user.Tenant_id })},
+		Notes:          &noteRepository{notes: newTenantCollection(func(note models.Note) string { return note.Tenant_id })},
+		Invoices:       &invoiceRepository{invoices: newTenantCollection(func(invoice models.Invoice) string { return invoice.Tenant_id }), payments: payments, orders: orders.orders},
+		Tickets:        tickets,
+		RefreshTokens:  &refreshTokenRepository{tokens: newCollection[models.RefreshToken]()},
+		PasswordResets: &passwordResetRepository{resets: newCollection[models.PasswordReset]()},
+		LoginThrottles: &loginThrottleRepository{throttles: newCollection[models.LoginThrottle]()},
+		AuditEvents:    &auditEventRepository{events: newTenantCollection(func(event models.AuditEvent) string { return event.Tenant_id })},
+		APIKeys:        &apiKeyRepository{keys: newTenantCollection(func(key models.APIKey) string { return key.Tenant_id })},
+		Tenants:        &tenantRepository{tenants: newCollection[models.Tenant]()},
+		Reservations:   &reservationRepository{reservations: newTenantCollection(func(reservation models.Reservation) string { return reservation.Tenant_id })},
+		Waitlist:       &waitlistRepository{entries: newTenantCollection(func(entry models.WaitlistEntry) string { return entry.Tenant_id })},
+		Payments:       &paymentRepository{payments: payments, orders: orders.orders},
+	}
+}
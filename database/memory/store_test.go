@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"restaurant_management/repository"
+	"restaurant_management/repository/repositorytest"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repository.Store {
+		return NewStore()
+	})
+}
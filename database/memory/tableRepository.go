@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type tableRepository struct {
+	tables *collection[models.Table]
+}
+
+func (r *tableRepository) List(ctx context.Context) ([]models.Table, error) {
+	return r.tables.owned(repository.TenantID(ctx)), nil
+}
+
+func (r *tableRepository) FindByID(ctx context.Context, tableId string) (models.Table, error) {
+	table, ok := r.tables.findOwned(repository.TenantID(ctx), tableId)
+	if !ok {
+		return table, repository.ErrNotFound
+	}
+	return table, nil
+}
+
+func (r *tableRepository) Create(ctx context.Context, table models.Table) error {
+	table.Tenant_id = repository.TenantID(ctx)
+	return r.tables.insert(table.Table_id, table)
+}
+
+func (r *tableRepository) Update(ctx context.Context, table models.Table) error {
+	table.Tenant_id = repository.TenantID(ctx)
+	r.tables.mu.Lock()
+	defer r.tables.mu.Unlock()
+	stored, ok := r.tables.items[table.Table_id]
+	if !ok || stored.Tenant_id != table.Tenant_id {
+		return repository.ErrNotFound
+	}
+	table.Status = stored.Status
+	r.tables.items[table.Table_id] = table
+	return nil
+}
+
+func (r *tableRepository) UpdateStatus(ctx context.Context, tableId string, status string, at time.Time) error {
+	r.tables.mu.Lock()
+	defer r.tables.mu.Unlock()
+	table, ok := r.tables.items[tableId]
+	if !ok || table.Tenant_id != repository.TenantID(ctx) {
+		return repository.ErrNotFound
+	}
+	table.Status = status
+	table.Updated_at = at
+	r.tables.items[tableId] = table
+	return nil
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type tenantRepository struct {
+	tenants *collection[models.Tenant]
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]models.Tenant, error) {
+	return r.tenants.all(), nil
+}
+
+func (r *tenantRepository) FindByID(ctx context.Context, tenantId string) (models.Tenant, error) {
+	tenant, ok := r.tenants.find(tenantId)
+	if !ok {
+		return tenant, repository.ErrNotFound
+	}
+	return tenant, nil
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant models.Tenant) error {
+	return r.tenants.insert(tenant.Tenant_id, tenant)
+}
+
+func (r *tenantRepository) Update(ctx context.Context, tenant models.Tenant) error {
+	if !r.tenants.replace(tenant.Tenant_id, tenant) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
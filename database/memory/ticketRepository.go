@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"slices"
+	"time"
+)
+
+type ticketRepository struct {
+	tickets *collection[models.Ticket]
+}
+
+func (r *ticketRepository) List(ctx context.Context, station string, statuses []string) ([]models.Ticket, error) {
+	tickets := []models.Ticket{}
+	for _, ticket := range r.tickets.owned(repository.TenantID(ctx)) {
+		if station != "" && ticket.Station != station {
+			continue
+		}
+		if !slices.Contains(statuses, ticket.Status) {
+			continue
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, nil
+}
+
+func (r *ticketRepository) FindByID(ctx context.Context, ticketId string) (models.Ticket, error) {
+	ticket, ok := r.tickets.findOwned(repository.TenantID(ctx), ticketId)
+	if !ok {
+		return ticket, repository.ErrNotFound
+	}
+	return ticket, nil
+}
+
+func (r *ticketRepository) ListByOrder(ctx context.Context, orderId string) ([]models.Ticket, error) {
+	tickets := []models.Ticket{}
+	for _, ticket := range r.tickets.owned(repository.TenantID(ctx)) {
+		if ticket.Order_id == orderId {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+func (r *ticketRepository) CreateMany(ctx context.Context, tickets []models.Ticket) error {
+	for _, ticket := range tickets {
+		ticket.Tenant_id = repository.TenantID(ctx)
+		if err := r.tickets.insert(ticket.Ticket_id, ticket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ticketRepository) UpdateStatus(ctx context.Context, ticketId string, from string, to string, at time.Time) error {
+	r.tickets.mu.Lock()
+	defer r.tickets.mu.Unlock()
+	ticket, ok := r.tickets.items[ticketId]
+	if !ok || ticket.Tenant_id != repository.TenantID(ctx) {
+		return repository.ErrNotFound
+	}
+	if ticket.Status != from {
+		return repository.ErrConflict
+	}
+	ticket.Status = to
+	ticket.Updated_at = at
+	r.tickets.items[ticketId] = ticket
+	return nil
+}
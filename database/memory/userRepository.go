@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type userRepository struct {
+	users *collection[models.User]
+}
+
+func (r *userRepository) List(ctx context.Context, page repository.Page) ([]models.User, error) {
+	users := window(r.users.owned(repository.TenantID(ctx)), page.Skip, page.Limit)
+	for i := range users {
+		users[i].Password = nil
+	}
+	return users, nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, userId string) (models.User, error) {
+	user, ok := r.users.find(userId)
+	if !ok {
+		return user, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	for _, user := range r.users.all() {
+		if user.Email != nil && *user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+func (r *userRepository) CountByEmail(ctx context.Context, email string) (int64, error) {
+	var count int64
+	for _, user := range r.users.all() {
+		if user.Email != nil && *user.Email == email {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *userRepository) CountByPhone(ctx context.Context, phone string) (int64, error) {
+	var count int64
+	for _, user := range r.users.all() {
+		if user.Phone != nil && *user.Phone == phone {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user models.User) error {
+	return r.users.insert(user.User_id, user)
+}
+
+func (r *userRepository) Update(ctx context.Context, user models.User) error {
+	if !r.users.replace(user.User_id, user) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UseTOTPStep(ctx context.Context, userId string, step int64, at time.Time) error {
+	r.users.mu.Lock()
+	defer r.users.mu.Unlock()
+	user, ok := r.users.items[userId]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if user.Totp_last_step >= step {
+		return repository.ErrConflict
+	}
+	user.Totp_last_step = step
+	user.Updated_at = at
+	r.users.items[userId] = user
+	return nil
+}
+
+func (r *userRepository) UpdateTokens(ctx context.Context, userId string, token string, refreshToken string) error {
+	user, ok := r.users.find(userId)
+	if !ok {
+		return repository.ErrNotFound
+	}
+	user.Token = &token
+	user.Refresh_token = &refreshToken
+	user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	r.users.replace(userId, user)
+	return nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"slices"
+)
+
+type waitlistRepository struct {
+	entries *collection[models.WaitlistEntry]
+}
+
+func (r *waitlistRepository) List(ctx context.Context, statuses []string) ([]models.WaitlistEntry, error) {
+	entries := []models.WaitlistEntry{}
+	for _, entry := range r.entries.owned(repository.TenantID(ctx)) {
+		if slices.Contains(statuses, entry.Status) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) FindByID(ctx context.Context, entryId string) (models.WaitlistEntry, error) {
+	entry, ok := r.entries.findOwned(repository.TenantID(ctx), entryId)
+	if !ok {
+		return entry, repository.ErrNotFound
+	}
+	return entry, nil
+}
+
+func (r *waitlistRepository) Create(ctx context.Context, entry models.WaitlistEntry) error {
+	entry.Tenant_id = repository.TenantID(ctx)
+	return r.entries.insert(entry.Entry_id, entry)
+}
+
+func (r *waitlistRepository) Update(ctx context.Context, entry models.WaitlistEntry, from string) error {
+	entry.Tenant_id = repository.TenantID(ctx)
+	r.entries.mu.Lock()
+	defer r.entries.mu.Unlock()
+	stored, ok := r.entries.items[entry.Entry_id]
+	if !ok || stored.Tenant_id != entry.Tenant_id {
+		return repository.ErrNotFound
+	}
+	if stored.Status != from {
+		return repository.ErrConflict
+	}
+	r.entries.items[entry.Entry_id] = entry
+	return nil
+}
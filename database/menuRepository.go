@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type menuRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *menuRepository) List(ctx context.Context) ([]models.Menu, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	menus := []models.Menu{}
+	if err := cursor.All(ctx, &menus); err != nil {
+		return nil, err
+	}
+	return menus, nil
+}
+
+func (r *menuRepository) FindByID(ctx context.Context, menuId string) (models.Menu, error) {
+	var menu models.Menu
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"menu_id": menuId})).Decode(&menu)
+	return menu, notFound(err)
+}
+
+func (r *menuRepository) Create(ctx context.Context, menu models.Menu) error {
+	menu.Tenant_id = repository.TenantID(ctx)
+	_, err := r.collection.InsertOne(ctx, menu)
+	return err
+}
+
+func (r *menuRepository) Update(ctx context.Context, menu models.Menu) error {
+	menu.Tenant_id = repository.TenantID(ctx)
+	result, err := r.collection.ReplaceOne(ctx, owned(ctx, bson.M{"menu_id": menu.Menu_id}), menu)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
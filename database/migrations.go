@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const migrationCollectionName = "schema_migrations"
+
+// migration rewrites existing documents whose shape has changed.
+type migration struct {
+	version int
+	name    string
+	apply   func(ctx context.Context, db *mongo.Database) error
+}
+
+var migrations = []migration{
+	{
+		// quantity used to hold the portion size; it moves to size and
+		// quantity becomes a count.
+		version: 1,
+		name:    "split order item size and quantity",
+		apply: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(orderItemCollectionName).UpdateMany(ctx,
+				bson.M{"quantity": bson.M{"$type": "string"}},
+				mongo.Pipeline{{{Key: "$set", Value: bson.D{
+					{Key: "size", Value: "$quantity"},
+					{Key: "quantity", Value: 1},
+				}}}})
+			return err
+		},
+	},
+	{
+		// everything stored before tenants existed belongs to the default
+		// tenant
+		version: 2,
+		name:    "add tenant to records",
+		apply: func(ctx context.Context, db *mongo.Database) error {
+			for _, name := range []string{
+				foodCollectionName, menuCollectionName, orderCollectionName, orderItemCollectionName,
+				tableCollectionName, noteCollectionName, invoiceCollectionName, ticketCollectionName,
+				userCollectionName, auditEventCollectionName, apiKeyCollectionName,
+			} {
+				_, err := db.Collection(name).UpdateMany(ctx,
+					bson.M{"tenant_id": bson.M{"$exists": false}},
+					bson.D{{Key: "$set", Value: bson.D{{Key: "tenant_id", Value: ""}}}})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// Migrate applies every migration newer than the highest version recorded in
+// the schema_migrations collection.
+func Migrate(ctx context.Context, db *mongo.Database) error {
+	var last struct {
+		Version int `bson:"version"`
+	}
+	err := db.Collection(migrationCollectionName).FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.M{"version": -1})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= last.Version {
+			continue
+		}
+		if err := m.apply(ctx, db); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+		_, err := db.Collection(migrationCollectionName).InsertOne(ctx, bson.M{
+			"version":    m.version,
+			"name":       m.name,
+			"applied_at": time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type noteRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *noteRepository) List(ctx context.Context) ([]models.Note, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	notes := []models.Note{}
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (r *noteRepository) FindByID(ctx context.Context, noteId string) (models.Note, error) {
+	var note models.Note
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"note_id": noteId})).Decode(&note)
+	return note, notFound(err)
+}
+
+func (r *noteRepository) Create(ctx context.Context, note models.Note) error {
+	note.Tenant_id = repository.TenantID(ctx)
+	_, err := r.collection.InsertOne(ctx, note)
+	return err
+}
+
+func (r *noteRepository) Update(ctx context.Context, note models.Note) error {
+	note.Tenant_id = repository.TenantID(ctx)
+	result, err := r.collection.ReplaceOne(ctx, owned(ctx, bson.M{"note_id": note.Note_id}), note)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
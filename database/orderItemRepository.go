@@ -0,0 +1,324 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type orderItemRepository struct {
+	collection *mongo.Collection
+	orders     *orderRepository
+}
+
+func (r *orderItemRepository) List(ctx context.Context) ([]models.OrderItem, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	orderItems := []models.OrderItem{}
+	if err := cursor.All(ctx, &orderItems); err != nil {
+		return nil, err
+	}
+	return orderItems, nil
+}
+
+func (r *orderItemRepository) ListByOrder(ctx context.Context, orderId string) ([]models.OrderItem, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{"order_id": orderId}), options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	orderItems := []models.OrderItem{}
+	if err := cursor.All(ctx, &orderItems); err != nil {
+		return nil, err
+	}
+	return orderItems, nil
+}
+
+func (r *orderItemRepository) FindByID(ctx context.Context, orderItemId string) (models.OrderItem, error) {
+	var orderItem models.OrderItem
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"order_item_id": orderItemId})).Decode(&orderItem)
+	return orderItem, notFound(err)
+}
+
+func (r *orderItemRepository) CreateMany(ctx context.Context, orderItems []models.OrderItem) error {
+	if len(orderItems) == 0 {
+		return nil
+	}
+	documents := make([]any, 0, len(orderItems))
+	for _, item := range orderItems {
+		item.Tenant_id = repository.TenantID(ctx)
+		documents = append(documents, item)
+	}
+	_, err := r.collection.InsertMany(ctx, documents)
+	return err
+}
+
+// Update runs in a transaction that first touches the order of the item,
+// so it conflicts with a concurrent status change of the order.
+func (r *orderItemRepository) Update(ctx context.Context, orderItem models.OrderItem) error {
+	orderItem.Tenant_id = repository.TenantID(ctx)
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		stored, err := r.FindByID(sc, orderItem.Order_item_id)
+		if err != nil {
+			return err
+		}
+		if err := r.orders.touchInStatus(sc, stored.Order_id, orderItem.Updated_at, models.ItemEditableOrderStatuses); err != nil {
+			if err == repository.ErrNotFound {
+				return repository.ErrConflict
+			}
+			return err
+		}
+		orderItem.Order_id = stored.Order_id
+		result, err := r.collection.ReplaceOne(sc, owned(sc, bson.M{"order_item_id": orderItem.Order_item_id}), orderItem)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return repository.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *orderItemRepository) ItemsByOrder(ctx context.Context, id string) ([]views.OrderItemsView, error) {
+	// match
+	matchStage := bson.D{
+		{
+			Key: "$match", Value: bson.D{
+				{Key: "order_id", Value: id},
+				{Key: "tenant_id", Value: repository.TenantID(ctx)},
+			},
+		},
+	}
+
+	// food
+	lookupFoodStage := bson.D{
+		{
+			Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: foodCollectionName},
+				{Key: "localField", Value: "food_id"},
+				{Key: "foreignField", Value: "food_id"},
+				{Key: "as", Value: "food"},
+			},
+		},
+	}
+	unwinFoodStage := bson.D{
+		{
+			Key: "$unwind", Value: bson.D{
+				{Key: "path", Value: "$food"},
+				{Key: "preserveNullAndEmptyArrays", Value: true},
+			},
+		},
+	}
+
+	// menu
+	lookupMenuStage := bson.D{
+		{
+			Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: menuCollectionName},
+				{Key: "localField", Value: "food.menu_id"},
+				{Key: "foreignField", Value: "menu_id"},
+				{Key: "as", Value: "menu"},
+			},
+		},
+	}
+	unwindMenuStage := bson.D{
+		{
+			Key: "$unwind", Value: bson.D{
+				{Key: "path", Value: "$menu"},
+				{Key: "preserveNullAndEmptyArrays", Value: true},
+			},
+		},
+	}
+
+	// order
+	lookupOrderStage := bson.D{
+		{
+			Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: orderCollectionName},
+				{Key: "localField", Value: "order_id"},
+				{Key: "foreignField", Value: "order_id"},
+				{Key: "as", Value: "order"},
+			},
+		},
+	}
+	unwindOrderStage := bson.D{
+		{
+			Key: "$unwind", Value: bson.D{
+				{Key: "path", Value: "$order"},
+				{Key: "preserveNullAndEmptyArrays", Value: true},
+			},
+		},
+	}
+
+	// table
+	lookupTableStage := bson.D{
+		{
+			Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: tableCollectionName},
+				{Key: "localField", Value: "order.table_id"},
+				{Key: "foreignField", Value: "table_id"},
+				{Key: "as", Value: "table"},
+			},
+		},
+	}
+	unwindTableStage := bson.D{
+		{
+			Key: "$unwind", Value: bson.D{
+				{Key: "path", Value: "$table"},
+				{Key: "preserveNullAndEmptyArrays", Value: true},
+			},
+		},
+	}
+
+	// unit price: the food's price plus the delta of the ordered size and
+	// of every chosen modifier. The pipeline adds up bare amounts; a line
+	// takes the currency of its food's price.
+	sizeDelta := bson.D{
+		{Key: "$arrayElemAt", Value: bson.A{
+			bson.D{{Key: "$map", Value: bson.D{
+				{Key: "input", Value: bson.D{{Key: "$filter", Value: bson.D{
+					{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$food.sizes", bson.A{}}}}},
+					{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$this.size", "$size"}}}},
+				}}}},
+				{Key: "in", Value: amountOf("$$this.price_delta")},
+			}}},
+			0,
+		}},
+	}
+	priceStage := bson.D{
+		{
+			Key: "$addFields", Value: bson.D{
+				{Key: "unit_price", Value: bson.D{
+					{Key: "$add", Value: bson.A{
+						amountOf("$food.price"),
+						bson.D{{Key: "$ifNull", Value: bson.A{sizeDelta, 0}}},
+						bson.D{{Key: "$sum", Value: bson.D{{Key: "$map", Value: bson.D{
+							{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$modifiers", bson.A{}}}}},
+							{Key: "in", Value: amountOf("$$this.price_delta")},
+						}}}}},
+					}},
+				}},
+				{Key: "quantity", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$quantity", 1}}}},
+				{Key: "currency", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$food.price.currency", ""}}}},
+			},
+		},
+	}
+
+	// project1
+	projectStage1 := bson.D{
+		{
+			Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "amount", Value: inCurrency(bson.D{{Key: "$multiply", Value: bson.A{"$unit_price", "$quantity"}}})},
+				{Key: "total_count", Value: 1},
+				{Key: "food_id", Value: "$food.food_id"},
+				{Key: "food_name", Value: "$food.name"},
+				{Key: "food_image", Value: "$food.food_image"},
+				{Key: "table_number", Value: "$table.table_number"},
+				{Key: "table_id", Value: "$table.table_id"},
+				{Key: "order_id", Value: "$order.order_id"},
+				{Key: "price", Value: inCurrency("$unit_price")},
+				{Key: "menu_category", Value: "$menu.category"},
+				{Key: "size", Value: 1},
+				{Key: "modifiers", Value: 1},
+				{Key: "quantity", Value: 1},
+				{Key: "order_item_id", Value: 1},
+				{Key: "seat", Value: 1},
+			},
+		},
+	}
+
+	// group
+	groupStage := bson.D{
+		{Key: "$group", Value: bson.D{
+			{
+				Key: "_id", Value: bson.D{
+					{Key: "order_id", Value: "$order_id"},
+					{Key: "table_id", Value: "$table_id"},
+					{Key: "table_number", Value: "$table_number"},
+				},
+			},
+			{
+				Key: "payment_due", Value: bson.D{
+					{Key: "$sum", Value: "$amount.amount"},
+				},
+			},
+			{
+				Key: "total_count", Value: bson.D{
+					{Key: "$sum", Value: 1},
+				},
+			},
+			{
+				Key: "order_items", Value: bson.D{
+					{Key: "$push", Value: "$$ROOT"},
+				},
+			},
+		},
+		},
+	}
+
+	// project2
+	projectStage2 := bson.D{
+		{
+			Key: "$project", Value: bson.D{
+				{Key: "id", Value: 1},
+				{Key: "payment_due", Value: 1},
+				{Key: "total_count", Value: 1},
+				{Key: "table_number", Value: "$_id.table_number"},
+				{Key: "order_items", Value: 1},
+			},
+		},
+	}
+
+	// Aggregate
+	result, err := r.collection.Aggregate(
+		ctx,
+		mongo.Pipeline{
+			matchStage,
+			lookupFoodStage,
+			unwinFoodStage,
+			lookupMenuStage,
+			unwindMenuStage,
+			lookupOrderStage,
+			unwindOrderStage,
+			lookupTableStage,
+			unwindTableStage,
+			priceStage,
+			projectStage1,
+			groupStage,
+			projectStage2,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	orderItems := []views.OrderItemsView{}
+	if err := result.All(ctx, &orderItems); err != nil {
+		return nil, err
+	}
+	return orderItems, nil
+}
+
+// amountOf reads an amount that may be stored with its currency, as
+// {amount, currency}.
+func amountOf(field string) bson.D {
+	return bson.D{{Key: "$ifNull", Value: bson.A{field + ".amount", field}}}
+}
+
+// inCurrency writes amount in the currency of the line, the way
+// money.Money is stored. A missing amount stays null.
+func inCurrency(amount any) bson.D {
+	return bson.D{{Key: "$cond", Value: bson.A{
+		bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{amount, nil}}}, nil}}},
+		nil,
+		bson.D{{Key: "amount", Value: amount}, {Key: "currency", Value: "$currency"}},
+	}}}
+}
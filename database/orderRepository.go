@@ -0,0 +1,344 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type orderRepository struct {
+	collection *mongo.Collection
+	items      *mongo.Collection
+	tickets    *mongo.Collection
+	tables     *mongo.Collection
+}
+
+func (r *orderRepository) List(ctx context.Context) ([]models.Order, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	orders := []models.Order{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) FindByID(ctx context.Context, orderId string) (models.Order, error) {
+	var order models.Order
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"order_id": orderId})).Decode(&order)
+	return order, notFound(err)
+}
+
+func (r *orderRepository) ListAtTable(ctx context.Context, tableId string, statuses []string) ([]models.Order, error) {
+	cursor, err := r.collection.Find(ctx,
+		owned(ctx, bson.M{"table_id": tableId, "status": bson.M{"$in": statusValues(statuses)}}),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	orders := []models.Order{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// Create only runs in a transaction when the order comes with items.
+func (r *orderRepository) Create(ctx context.Context, order models.Order, orderItems ...models.OrderItem) error {
+	order.Tenant_id = repository.TenantID(ctx)
+	if len(orderItems) == 0 {
+		_, err := r.collection.InsertOne(ctx, order)
+		return err
+	}
+	documents := make([]any, 0, len(orderItems))
+	for _, item := range orderItems {
+		item.Tenant_id = order.Tenant_id
+		documents = append(documents, item)
+	}
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		if _, err := r.collection.InsertOne(sc, order); err != nil {
+			return err
+		}
+		_, err := r.items.InsertMany(sc, documents)
+		return err
+	})
+}
+
+func (r *orderRepository) Update(ctx context.Context, order models.Order) error {
+	var updateObj bson.D
+	updateObj = append(updateObj, bson.E{Key: "table_id", Value: order.Table_id})
+	updateObj = append(updateObj, bson.E{Key: "order_date", Value: order.Order_date})
+	updateObj = append(updateObj, bson.E{Key: "discounts", Value: order.Discounts})
+	updateObj = append(updateObj, bson.E{Key: "updated_at", Value: order.Updated_at})
+
+	result, err := r.collection.UpdateOne(ctx, owned(ctx, bson.M{"order_id": order.Order_id}), bson.D{{Key: "$set", Value: updateObj}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateStatus only runs in a transaction when it voids tickets, so the
+// repositories that move orders in their own transactions can call it.
+func (r *orderRepository) UpdateStatus(ctx context.Context, orderId string, change models.OrderStatusChange) error {
+	if change.To != models.OrderStatusCancelled && change.To != models.OrderStatusVoided {
+		return r.updateStatus(ctx, orderId, change)
+	}
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		if err := r.updateStatus(sc, orderId, change); err != nil {
+			return err
+		}
+		open := bson.M{"order_id": orderId, "status": bson.M{"$in": models.OpenTicketStatuses}}
+		_, err := r.tickets.UpdateMany(sc, owned(sc, open),
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "status", Value: models.TicketStatusVoided},
+				{Key: "updated_at", Value: change.Changed_at},
+			}}})
+		return err
+	})
+}
+
+func (r *orderRepository) SendToKitchen(ctx context.Context, orderId string, change models.OrderStatusChange, tickets []models.Ticket) error {
+	documents := make([]any, 0, len(tickets))
+	for _, ticket := range tickets {
+		ticket.Tenant_id = repository.TenantID(ctx)
+		documents = append(documents, ticket)
+	}
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		if err := r.updateStatus(sc, orderId, change); err != nil {
+			return err
+		}
+		if len(documents) == 0 {
+			return nil
+		}
+		_, err := r.tickets.InsertMany(sc, documents)
+		return err
+	})
+}
+
+func (r *orderRepository) updateStatus(ctx context.Context, orderId string, change models.OrderStatusChange) error {
+	var status any = change.From
+	if change.From == models.OrderStatusOpen {
+		// orders created before statuses existed have no status field
+		status = bson.M{"$in": bson.A{change.From, "", nil}}
+	}
+	filter := owned(ctx, bson.M{"order_id": orderId, "status": status})
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: change.To},
+			{Key: "updated_at", Value: change.Changed_at},
+		}},
+		{Key: "$push", Value: bson.D{{Key: "status_history", Value: change}}},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, orderId); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return nil
+}
+
+func (r *orderRepository) Transfer(ctx context.Context, orderId string, tableId string, at time.Time) error {
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		result, err := r.collection.UpdateOne(sc, editable(sc, orderId),
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "table_id", Value: tableId},
+				{Key: "updated_at", Value: at},
+			}}})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return r.conflict(sc, orderId)
+		}
+		return r.pointTickets(sc, orderId, at)
+	})
+}
+
+// MoveItems, SplitOff and Merge run in a transaction. They write the orders
+// they depend on first, so concurrent moves of the same orders conflict
+// instead of interleaving.
+func (r *orderRepository) MoveItems(ctx context.Context, from string, to string, orderItemIds []string, at time.Time) error {
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		for _, orderId := range []string{from, to} {
+			if err := r.touchEditable(sc, orderId, at); err != nil {
+				return err
+			}
+		}
+		if err := r.moveItems(sc, from, to, orderItemIds, at); err != nil {
+			return err
+		}
+		return r.pointTickets(sc, to, at)
+	})
+}
+
+func (r *orderRepository) SplitOff(ctx context.Context, from string, to models.Order, orderItemIds []string, at time.Time) error {
+	to.Tenant_id = repository.TenantID(ctx)
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		if err := r.touchEditable(sc, from, at); err != nil {
+			return err
+		}
+		if _, err := r.collection.InsertOne(sc, to); err != nil {
+			return err
+		}
+		if err := r.moveItems(sc, from, to.Order_id, orderItemIds, at); err != nil {
+			return err
+		}
+		return r.pointTickets(sc, to.Order_id, at)
+	})
+}
+
+// moveItems moves the order items orderItemIds from order from to order to.
+// It returns ErrConflict if an item is not on from.
+func (r *orderRepository) moveItems(ctx context.Context, from string, to string, orderItemIds []string, at time.Time) error {
+	result, err := r.items.UpdateMany(ctx,
+		owned(ctx, bson.M{"order_id": from, "order_item_id": bson.M{"$in": orderItemIds}}),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "order_id", Value: to},
+			{Key: "updated_at", Value: at},
+		}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount != int64(len(orderItemIds)) {
+		return repository.ErrConflict
+	}
+	return nil
+}
+
+func (r *orderRepository) Merge(ctx context.Context, from []string, into string, tableId string, change models.OrderStatusChange) error {
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		result, err := r.collection.UpdateOne(sc, editable(sc, into),
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "table_id", Value: tableId},
+				{Key: "updated_at", Value: change.Changed_at},
+			}}})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return r.conflict(sc, into)
+		}
+		for _, orderId := range from {
+			order, err := r.FindByID(sc, orderId)
+			if err != nil {
+				return err
+			}
+			orderChange := change
+			orderChange.From = order.Status
+			if orderChange.From == "" {
+				orderChange.From = models.OrderStatusOpen
+			}
+			if !slices.Contains(models.EditableOrderStatuses, orderChange.From) {
+				return repository.ErrConflict
+			}
+			if err := r.UpdateStatus(sc, orderId, orderChange); err != nil {
+				return err
+			}
+			_, err = r.items.UpdateMany(sc, owned(sc, bson.M{"order_id": orderId}),
+				bson.D{{Key: "$set", Value: bson.D{
+					{Key: "order_id", Value: into},
+					{Key: "updated_at", Value: change.Changed_at},
+				}}})
+			if err != nil {
+				return err
+			}
+		}
+		return r.pointTickets(sc, into, change.Changed_at)
+	})
+}
+
+// pointTickets points the tickets of the items on the order orderId at that
+// order and the number of its table.
+func (r *orderRepository) pointTickets(ctx context.Context, orderId string, at time.Time) error {
+	order, err := r.FindByID(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	var table models.Table
+	if order.Table_id != nil {
+		err := r.tables.FindOne(ctx, owned(ctx, bson.M{"table_id": *order.Table_id})).Decode(&table)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+	}
+	orderItemIds, err := r.items.Distinct(ctx, "order_item_id", owned(ctx, bson.M{"order_id": orderId}))
+	if err != nil {
+		return err
+	}
+	_, err = r.tickets.UpdateMany(ctx, owned(ctx, bson.M{"order_item_id": bson.M{"$in": orderItemIds}}),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "order_id", Value: orderId},
+			{Key: "table_number", Value: table.Table_number},
+			{Key: "updated_at", Value: at},
+		}}})
+	return err
+}
+
+// touchEditable sets updated_at of an order that can still change. It
+// returns ErrNotFound or ErrConflict otherwise.
+func (r *orderRepository) touchEditable(ctx context.Context, orderId string, at time.Time) error {
+	return r.touchInStatus(ctx, orderId, at, models.EditableOrderStatuses)
+}
+
+// touchInStatus sets updated_at of an order in one of statuses. It returns
+// ErrNotFound or ErrConflict otherwise.
+func (r *orderRepository) touchInStatus(ctx context.Context, orderId string, at time.Time, statuses []string) error {
+	result, err := r.collection.UpdateOne(ctx, inStatus(ctx, orderId, statuses),
+		bson.D{{Key: "$set", Value: bson.D{{Key: "updated_at", Value: at}}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.conflict(ctx, orderId)
+	}
+	return nil
+}
+
+// conflict tells an order that is missing from one that changed meanwhile.
+func (r *orderRepository) conflict(ctx context.Context, orderId string) error {
+	if _, err := r.FindByID(ctx, orderId); err != nil {
+		return err
+	}
+	return repository.ErrConflict
+}
+
+// editable matches the order if it can still change.
+func editable(ctx context.Context, orderId string) bson.M {
+	return inStatus(ctx, orderId, models.EditableOrderStatuses)
+}
+
+// inStatus matches the order if it is in one of statuses.
+func inStatus(ctx context.Context, orderId string, statuses []string) bson.M {
+	return owned(ctx, bson.M{"order_id": orderId, "status": bson.M{"$in": statusValues(statuses)}})
+}
+
+// statusValues are the stored values of statuses. Orders created before
+// statuses existed have no status field and count as open.
+func statusValues(statuses []string) bson.A {
+	values := bson.A{}
+	for _, status := range statuses {
+		values = append(values, status)
+		if status == models.OrderStatusOpen {
+			values = append(values, "", nil)
+		}
+	}
+	return values
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type passwordResetRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *passwordResetRepository) FindByTokenHash(ctx context.Context, tokenHash string) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&reset)
+	return reset, notFound(err)
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, reset models.PasswordReset) error {
+	_, err := r.collection.InsertOne(ctx, reset)
+	return err
+}
+
+func (r *passwordResetRepository) MarkUsed(ctx context.Context, resetId string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"reset_id": resetId, "used_at": nil},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "used_at", Value: at}}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if err := r.collection.FindOne(ctx, bson.M{"reset_id": resetId}).Err(); err != nil {
+			return notFound(err)
+		}
+		return repository.ErrConflict
+	}
+	return nil
+}
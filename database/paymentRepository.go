@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type paymentRepository struct {
+	collection *mongo.Collection
+	invoices   *mongo.Collection
+	orders     *orderRepository
+}
+
+func (r *paymentRepository) ListByInvoice(ctx context.Context, invoiceId string) ([]models.Payment, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{"invoice_id": invoiceId}),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	payments := []models.Payment{}
+	if err := cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) FindByID(ctx context.Context, paymentId string) (models.Payment, error) {
+	var payment models.Payment
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"payment_id": paymentId})).Decode(&payment)
+	return payment, notFound(err)
+}
+
+// Split writes the invoice first, so concurrent splits of the same invoice
+// conflict instead of interleaving.
+func (r *paymentRepository) Split(ctx context.Context, invoiceId string, paid int, payments []models.Payment, at time.Time) error {
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		result, err := r.invoices.UpdateOne(sc, owned(sc, bson.M{"invoice_id": invoiceId}),
+			bson.D{{Key: "$set", Value: bson.D{{Key: "updated_at", Value: at}}}})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return repository.ErrNotFound
+		}
+		count, err := r.collection.CountDocuments(sc, owned(sc, bson.M{"invoice_id": invoiceId, "status": models.PaymentStatusPaid}))
+		if err != nil {
+			return err
+		}
+		if count != int64(paid) {
+			return repository.ErrConflict
+		}
+		_, err = r.collection.UpdateMany(sc, owned(sc, bson.M{"invoice_id": invoiceId, "status": models.PaymentStatusPending}),
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "status", Value: models.PaymentStatusVoided},
+				{Key: "updated_at", Value: at},
+			}}})
+		if err != nil || len(payments) == 0 {
+			return err
+		}
+		documents := make([]any, 0, len(payments))
+		for _, payment := range payments {
+			payment.Tenant_id = repository.TenantID(sc)
+			documents = append(documents, payment)
+		}
+		_, err = r.collection.InsertMany(sc, documents)
+		return err
+	})
+}
+
+func (r *paymentRepository) Pay(ctx context.Context, paymentId string, method string, change models.OrderStatusChange) error {
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		payment, err := r.FindByID(sc, paymentId)
+		if err != nil {
+			return err
+		}
+		err = r.orders.touchInStatus(sc, payment.Order_id, change.Changed_at, []string{change.From})
+		if err == repository.ErrNotFound {
+			return repository.ErrConflict
+		}
+		if err != nil {
+			return err
+		}
+		result, err := r.collection.UpdateOne(sc,
+			owned(sc, bson.M{"payment_id": paymentId, "status": models.PaymentStatusPending}),
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "status", Value: models.PaymentStatusPaid},
+				{Key: "payment_method", Value: method},
+				{Key: "paid_at", Value: change.Changed_at},
+				{Key: "updated_at", Value: change.Changed_at},
+			}}})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return repository.ErrConflict
+		}
+		pending, err := r.collection.CountDocuments(sc,
+			owned(sc, bson.M{"invoice_id": payment.Invoice_id, "status": models.PaymentStatusPending}))
+		if err != nil || pending > 0 {
+			return err
+		}
+		return r.orders.UpdateStatus(sc, payment.Order_id, change)
+	})
+}
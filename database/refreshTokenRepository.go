@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type refreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *refreshTokenRepository) FindByID(ctx context.Context, tokenId string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.collection.FindOne(ctx, bson.M{"token_id": tokenId}).Decode(&token)
+	return token, notFound(err)
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token models.RefreshToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, tokenId string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"token_id": tokenId, "used_at": nil},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "used_at", Value: at}}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, tokenId); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyId string, at time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"family_id": familyId, "revoked_at": nil},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revoked_at", Value: at}}}})
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeUser(ctx context.Context, userId string, at time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userId, "revoked_at": nil},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revoked_at", Value: at}}}})
+	return err
+}
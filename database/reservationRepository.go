@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reservationRepository struct {
+	collection *mongo.Collection
+	tables     *mongo.Collection
+}
+
+func (r *reservationRepository) List(ctx context.Context, from time.Time, to time.Time) ([]models.Reservation, error) {
+	cursor, err := r.collection.Find(ctx,
+		owned(ctx, bson.M{"reserved_at": bson.M{"$lt": to}, "ends_at": bson.M{"$gt": from}}),
+		options.Find().SetSort(bson.D{{Key: "reserved_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	reservations := []models.Reservation{}
+	if err := cursor.All(ctx, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+func (r *reservationRepository) FindByID(ctx context.Context, reservationId string) (models.Reservation, error) {
+	var reservation models.Reservation
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"reservation_id": reservationId})).Decode(&reservation)
+	return reservation, notFound(err)
+}
+
+// Create and Update run in a transaction that first bumps a counter on the
+// table of the reservation. Snapshot isolation alone would let two bookings
+// racing for the same table both pass the overlap check; writing the same
+// table document makes one of them conflict and retry, so it sees the other.
+func (r *reservationRepository) Create(ctx context.Context, reservation models.Reservation) error {
+	reservation.Tenant_id = repository.TenantID(ctx)
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		if err := r.lockTable(sc, reservation); err != nil {
+			return err
+		}
+		_, err := r.collection.InsertOne(sc, reservation)
+		return err
+	})
+}
+
+func (r *reservationRepository) Update(ctx context.Context, reservation models.Reservation) error {
+	reservation.Tenant_id = repository.TenantID(ctx)
+	return transaction(ctx, r.collection.Database(), func(sc mongo.SessionContext) error {
+		if err := r.collection.FindOne(sc, owned(sc, bson.M{"reservation_id": reservation.Reservation_id})).Err(); err != nil {
+			return notFound(err)
+		}
+		if err := r.lockTable(sc, reservation); err != nil {
+			return err
+		}
+		result, err := r.collection.ReplaceOne(sc, owned(sc, bson.M{"reservation_id": reservation.Reservation_id}), reservation)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return repository.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// lockTable writes the table of reservation, then checks it for clashes.
+func (r *reservationRepository) lockTable(ctx context.Context, reservation models.Reservation) error {
+	_, err := r.tables.UpdateOne(ctx, owned(ctx, bson.M{"table_id": reservation.Table_id}),
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "reservation_writes", Value: 1}}}})
+	if err != nil {
+		return err
+	}
+	return r.checkClash(ctx, reservation)
+}
+
+// checkClash returns ErrConflict if another booked reservation holds the
+// table of reservation while it would.
+func (r *reservationRepository) checkClash(ctx context.Context, reservation models.Reservation) error {
+	if reservation.Status != models.ReservationBooked {
+		return nil
+	}
+	count, err := r.collection.CountDocuments(ctx, owned(ctx, bson.M{
+		"reservation_id": bson.M{"$ne": reservation.Reservation_id},
+		"table_id":       reservation.Table_id,
+		"status":         models.ReservationBooked,
+		"reserved_at":    bson.M{"$lt": reservation.Ends_at},
+		"ends_at":        bson.M{"$gt": reservation.Reserved_at},
+	}))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return repository.ErrConflict
+	}
+	return nil
+}
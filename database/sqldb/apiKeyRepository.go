@@ -0,0 +1,71 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+const apiKeyColumns = `key_id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at, tenant_id`
+
+func scanAPIKey(row scanner) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(&key.Key_id, &key.Name, &key.Prefix, &key.Key_hash, jsonColumn{&key.Scopes}, &key.Created_by,
+		&key.Expires_at, &key.Last_used_at, &key.Revoked_at, &key.Created_at, &key.Tenant_id)
+	key.ID = objectID(key.Key_id)
+	return key, err
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = $1 ORDER BY created_at, key_id`, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepository) FindByID(ctx context.Context, keyId string) (models.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_id = $1 AND tenant_id = $2`, keyId, repository.TenantID(ctx)))
+	return key, notFound(err)
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash))
+	return key, notFound(err)
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key models.APIKey) error {
+	scopes, err := jsonValue(key.Scopes)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO api_keys (`+apiKeyColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		key.Key_id, key.Name, key.Prefix, key.Key_hash, scopes, key.Created_by, key.Expires_at, key.Last_used_at, key.Revoked_at, key.Created_at, repository.TenantID(ctx))
+	return err
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, keyId string, at time.Time) error {
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE key_id = $1 AND tenant_id = $3`, keyId, at, repository.TenantID(ctx)))
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, keyId string, at time.Time) error {
+	return updated(r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE key_id = $1`, keyId, at))
+}
@@ -0,0 +1,53 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type auditEventRepository struct {
+	db *sql.DB
+}
+
+const auditEventColumns = `event_id, type, user_id, actor_id, ip, detail, created_at, tenant_id`
+
+func scanAuditEvent(row scanner) (models.AuditEvent, error) {
+	var event models.AuditEvent
+	err := row.Scan(&event.Event_id, &event.Type, &event.User_id, &event.Actor_id, &event.Ip, &event.Detail, &event.Created_at, &event.Tenant_id)
+	event.ID = objectID(event.Event_id)
+	return event, err
+}
+
+func (r *auditEventRepository) List(ctx context.Context, page repository.Page) ([]models.AuditEvent, error) {
+	limit := page.Limit
+	if limit < 1 {
+		limit = math.MaxInt64
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+auditEventColumns+` FROM audit_events WHERE tenant_id = $3 ORDER BY created_at DESC, event_id DESC LIMIT $1 OFFSET $2`,
+		limit, page.Skip, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *auditEventRepository) Create(ctx context.Context, event models.AuditEvent) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO audit_events (`+auditEventColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		event.Event_id, event.Type, event.User_id, event.Actor_id, event.Ip, event.Detail, event.Created_at, event.Tenant_id)
+	return err
+}
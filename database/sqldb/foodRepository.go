@@ -0,0 +1,84 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type foodRepository struct {
+	db *sql.DB
+}
+
+const foodColumns = `food_id, name, price, sizes, modifier_groups, station, food_image, menu_id, created_at, updated_at, tenant_id`
+
+func scanFood(row scanner) (models.Food, error) {
+	var food models.Food
+	err := row.Scan(&food.Food_id, &food.Name, &food.Price, jsonColumn{&food.Sizes}, jsonColumn{&food.Modifier_groups}, &food.Station, &food.Food_image, &food.Menu_id, &food.Created_at, &food.Update_at, &food.Tenant_id)
+	food.ID = objectID(food.Food_id)
+	return food, err
+}
+
+func (r *foodRepository) List(ctx context.Context, page repository.Page) ([]models.Food, int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM foods WHERE tenant_id = $1`, repository.TenantID(ctx)).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := page.Limit
+	if limit < 1 {
+		limit = total
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+foodColumns+` FROM foods WHERE tenant_id = $3 ORDER BY created_at, food_id LIMIT $1 OFFSET $2`,
+		limit, page.Skip, repository.TenantID(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	foods := []models.Food{}
+	for rows.Next() {
+		food, err := scanFood(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		foods = append(foods, food)
+	}
+	return foods, total, rows.Err()
+}
+
+func (r *foodRepository) FindByID(ctx context.Context, foodId string) (models.Food, error) {
+	food, err := scanFood(r.db.QueryRowContext(ctx, `SELECT `+foodColumns+` FROM foods WHERE food_id = $1 AND tenant_id = $2`, foodId, repository.TenantID(ctx)))
+	return food, notFound(err)
+}
+
+func (r *foodRepository) Create(ctx context.Context, food models.Food) error {
+	sizes, err := jsonValue(food.Sizes)
+	if err != nil {
+		return err
+	}
+	modifierGroups, err := jsonValue(food.Modifier_groups)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO foods (`+foodColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		food.Food_id, food.Name, food.Price, sizes, modifierGroups, food.Station, food.Food_image, food.Menu_id, food.Created_at, food.Update_at, repository.TenantID(ctx))
+	return err
+}
+
+func (r *foodRepository) Update(ctx context.Context, food models.Food) error {
+	sizes, err := jsonValue(food.Sizes)
+	if err != nil {
+		return err
+	}
+	modifierGroups, err := jsonValue(food.Modifier_groups)
+	if err != nil {
+		return err
+	}
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE foods SET name = $2, price = $3, sizes = $4, modifier_groups = $5, station = $6, food_image = $7, menu_id = $8, created_at = $9, updated_at = $10 WHERE food_id = $1 AND tenant_id = $11`,
+		food.Food_id, food.Name, food.Price, sizes, modifierGroups, food.Station, food.Food_image, food.Menu_id, food.Created_at, food.Update_at, repository.TenantID(ctx)))
+}
@@ -0,0 +1,101 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type invoiceRepository struct {
+	db *sql.DB
+}
+
+const invoiceColumns = `invoice_id, order_id, payment_method, payment_status, payment_due, payment_due_date, created_at, updated_at, pricing, tenant_id`
+
+func scanInvoice(row scanner) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := row.Scan(&invoice.Invoice_id, &invoice.Order_id, &invoice.Payment_method, &invoice.Payment_status,
+		&invoice.Payment_due, &invoice.Payment_due_date, &invoice.Created_at, &invoice.Updated_at, jsonColumn{&invoice.Pricing}, &invoice.Tenant_id)
+	invoice.ID = objectID(invoice.Invoice_id)
+	return invoice, err
+}
+
+func (r *invoiceRepository) List(ctx context.Context) ([]models.Invoice, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE tenant_id = $1 ORDER BY created_at, invoice_id`, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []models.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
+
+func (r *invoiceRepository) FindByID(ctx context.Context, invoiceId string) (models.Invoice, error) {
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE invoice_id = $1 AND tenant_id = $2`, invoiceId, repository.TenantID(ctx)))
+	return invoice, notFound(err)
+}
+
+func (r *invoiceRepository) FindByOrderID(ctx context.Context, orderId string) (models.Invoice, error) {
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE order_id = $1 AND tenant_id = $2`, orderId, repository.TenantID(ctx)))
+	return invoice, notFound(err)
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, invoice models.Invoice, payment models.Payment, change models.OrderStatusChange) error {
+	pricing, err := jsonValue(invoice.Pricing)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// moving the order first locks it, so a second invoice waits and then
+	// finds it billed
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE orders SET status = $3, updated_at = $4 WHERE order_id = $1 AND status = $2 AND tenant_id = $5`,
+		*invoice.Order_id, change.From, change.To, change.Changed_at, repository.TenantID(ctx)))
+	if err == repository.ErrNotFound {
+		tx.Rollback()
+		return (&orderRepository{db: r.db}).conflict(ctx, *invoice.Order_id)
+	}
+	if err != nil {
+		return err
+	}
+	if err := insertStatusChange(ctx, tx, *invoice.Order_id, change); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO invoices (`+invoiceColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		invoice.Invoice_id, invoice.Order_id, invoice.Payment_method, invoice.Payment_status,
+		invoice.Payment_due, invoice.Payment_due_date, invoice.Created_at, invoice.Updated_at, pricing, repository.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	if err := insertPayment(ctx, tx, payment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, invoice models.Invoice) error {
+	pricing, err := jsonValue(invoice.Pricing)
+	if err != nil {
+		return err
+	}
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE invoices SET order_id = $2, payment_method = $3, payment_status = $4, payment_due = $5,
+			payment_due_date = $6, created_at = $7, updated_at = $8, pricing = $9 WHERE invoice_id = $1 AND tenant_id = $10`,
+		invoice.Invoice_id, invoice.Order_id, invoice.Payment_method, invoice.Payment_status,
+		invoice.Payment_due, invoice.Payment_due_date, invoice.Created_at, invoice.Updated_at, pricing, repository.TenantID(ctx)))
+}
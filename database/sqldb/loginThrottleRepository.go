@@ -0,0 +1,43 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"time"
+)
+
+type loginThrottleRepository struct {
+	db *sql.DB
+}
+
+const loginThrottleColumns = `key, failures, last_failure_at, locked_until`
+
+func scanLoginThrottle(row scanner) (models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := row.Scan(&throttle.Key, &throttle.Failures, &throttle.Last_failure_at, &throttle.Locked_until)
+	return throttle, err
+}
+
+func (r *loginThrottleRepository) Find(ctx context.Context, key string) (models.LoginThrottle, error) {
+	throttle, err := scanLoginThrottle(r.db.QueryRowContext(ctx, `SELECT `+loginThrottleColumns+` FROM login_throttles WHERE key = $1`, key))
+	return throttle, notFound(err)
+}
+
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, at time.Time, since time.Time) (models.LoginThrottle, error) {
+	return scanLoginThrottle(r.db.QueryRowContext(ctx, `
+		INSERT INTO login_throttles (key, failures, last_failure_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < $3 THEN 1 ELSE login_throttles.failures + 1 END,
+			last_failure_at = excluded.last_failure_at
+		RETURNING `+loginThrottleColumns, key, at, since))
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	return updated(r.db.ExecContext(ctx, `UPDATE login_throttles SET locked_until = $2 WHERE key = $1`, key, until))
+}
+
+func (r *loginThrottleRepository) Clear(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_throttles WHERE key = $1`, key)
+	return err
+}
@@ -0,0 +1,57 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type menuRepository struct {
+	db *sql.DB
+}
+
+const menuColumns = `menu_id, name, category, station, start_date, end_date, created_at, updated_at, tenant_id`
+
+func scanMenu(row scanner) (models.Menu, error) {
+	var menu models.Menu
+	err := row.Scan(&menu.Menu_id, &menu.Name, &menu.Category, &menu.Station, &menu.Start_Date, &menu.End_Date, &menu.Created_at, &menu.Updated_at, &menu.Tenant_id)
+	menu.ID = objectID(menu.Menu_id)
+	return menu, err
+}
+
+func (r *menuRepository) List(ctx context.Context) ([]models.Menu, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+menuColumns+` FROM menus WHERE tenant_id = $1 ORDER BY created_at, menu_id`, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	menus := []models.Menu{}
+	for rows.Next() {
+		menu, err := scanMenu(rows)
+		if err != nil {
+			return nil, err
+		}
+		menus = append(menus, menu)
+	}
+	return menus, rows.Err()
+}
+
+func (r *menuRepository) FindByID(ctx context.Context, menuId string) (models.Menu, error) {
+	menu, err := scanMenu(r.db.QueryRowContext(ctx, `SELECT `+menuColumns+` FROM menus WHERE menu_id = $1 AND tenant_id = $2`, menuId, repository.TenantID(ctx)))
+	return menu, notFound(err)
+}
+
+func (r *menuRepository) Create(ctx context.Context, menu models.Menu) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO menus (`+menuColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		menu.Menu_id, menu.Name, menu.Category, menu.Station, menu.Start_Date, menu.End_Date, menu.Created_at, menu.Updated_at, repository.TenantID(ctx))
+	return err
+}
+
+func (r *menuRepository) Update(ctx context.Context, menu models.Menu) error {
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE menus SET name = $2, category = $3, station = $4, start_date = $5, end_date = $6, created_at = $7, updated_at = $8 WHERE menu_id = $1 AND tenant_id = $9`,
+		menu.Menu_id, menu.Name, menu.Category, menu.Station, menu.Start_Date, menu.End_Date, menu.Created_at, menu.Updated_at, repository.TenantID(ctx)))
+}
@@ -0,0 +1,456 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// migrations are applied in order and recorded in schema_migrations. Never
+// edit a released migration; append a new one instead. The DDL is kept to the
+// subset SQLite and Postgres share.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create core tables",
+		statements: []string{
+			`CREATE TABLE menus (
+				menu_id    TEXT PRIMARY KEY,
+				name       TEXT NOT NULL,
+				category   TEXT NOT NULL,
+				start_date TIMESTAMP NULL,
+				end_date   TIMESTAMP NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE foods (
+				food_id    TEXT PRIMARY KEY,
+				name       TEXT,
+				price      DOUBLE PRECISION,
+				food_image TEXT,
+				menu_id    TEXT REFERENCES menus (menu_id),
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE tables (
+				table_id         TEXT PRIMARY KEY,
+				number_of_guests INTEGER,
+				table_number     INTEGER,
+				created_at       TIMESTAMP NOT NULL,
+				updated_at       TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE orders (
+				order_id   TEXT PRIMARY KEY,
+				table_id   TEXT REFERENCES tables (table_id),
+				order_date TIMESTAMP NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE order_items (
+				order_item_id TEXT PRIMARY KEY,
+				order_id      TEXT NOT NULL REFERENCES orders (order_id),
+				food_id       TEXT REFERENCES foods (food_id),
+				quantity      TEXT,
+				created_at    TIMESTAMP NOT NULL,
+				updated_at    TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE users (
+				user_id       TEXT PRIMARY KEY,
+				first_name    TEXT,
+				last_name     TEXT,
+				password      TEXT,
+				email         TEXT,
+				avatar        TEXT,
+				phone         TEXT,
+				token         TEXT,
+				refresh_token TEXT,
+				created_at    TIMESTAMP NOT NULL,
+				updated_at    TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE notes (
+				note_id    TEXT PRIMARY KEY,
+				title      TEXT NOT NULL,
+				text       TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX idx_foods_menu_id ON foods (menu_id)`,
+			`CREATE INDEX idx_orders_table_id ON orders (table_id)`,
+			`CREATE INDEX idx_order_items_order_id ON order_items (order_id)`,
+			`CREATE INDEX idx_order_items_food_id ON order_items (food_id)`,
+			`CREATE INDEX idx_users_email ON users (email)`,
+			`CREATE INDEX idx_users_phone ON users (phone)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "add order status and history",
+		statements: []string{
+			`ALTER TABLE orders ADD COLUMN status TEXT NOT NULL DEFAULT 'open'`,
+			`CREATE TABLE order_status_history (
+				order_status_change_id TEXT PRIMARY KEY,
+				order_id               TEXT NOT NULL REFERENCES orders (order_id),
+				from_status            TEXT NOT NULL,
+				to_status              TEXT NOT NULL,
+				changed_by             TEXT NOT NULL,
+				changed_at             TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX idx_order_status_history_order_id ON order_status_history (order_id)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "create invoices",
+		statements: []string{
+			`CREATE TABLE invoices (
+				invoice_id       TEXT PRIMARY KEY,
+				order_id         TEXT NOT NULL UNIQUE REFERENCES orders (order_id),
+				payment_method   TEXT,
+				payment_status   TEXT,
+				payment_due      DOUBLE PRECISION NOT NULL,
+				payment_due_date TIMESTAMP NOT NULL,
+				created_at       TIMESTAMP NOT NULL,
+				updated_at       TIMESTAMP NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "add order discounts and invoice pricing",
+		statements: []string{
+			`ALTER TABLE orders ADD COLUMN discounts TEXT`,
+			`ALTER TABLE invoices ADD COLUMN pricing TEXT`,
+		},
+	},
+	{
+		// Amounts move from floating point to text such as "1250 EUR": the
+		// integer count of minor units, so sums never drift, followed by the
+		// currency, if any.
+		version: 5,
+		name:    "store amounts in minor units",
+		statements: []string{
+			`ALTER TABLE foods ADD COLUMN price_minor TEXT`,
+			`UPDATE foods SET price_minor = CAST(CAST(ROUND(price * 100) AS BIGINT) AS TEXT)`,
+			`ALTER TABLE foods DROP COLUMN price`,
+			`ALTER TABLE foods RENAME COLUMN price_minor TO price`,
+			`ALTER TABLE invoices ADD COLUMN payment_due_minor TEXT NOT NULL DEFAULT '0'`,
+			`UPDATE invoices SET payment_due_minor = CAST(CAST(ROUND(payment_due * 100) AS BIGINT) AS TEXT)`,
+			`ALTER TABLE invoices DROP COLUMN payment_due`,
+			`ALTER TABLE invoices RENAME COLUMN payment_due_minor TO payment_due`,
+		},
+	},
+	{
+		// quantity used to hold the portion size; it moves to size and
+		// quantity becomes a count.
+		version: 6,
+		name:    "split order item size and quantity",
+		statements: []string{
+			`ALTER TABLE foods ADD COLUMN sizes TEXT`,
+			`ALTER TABLE order_items ADD COLUMN size TEXT`,
+			`UPDATE order_items SET size = quantity WHERE quantity IN ('S', 'M', 'L')`,
+			`ALTER TABLE order_items ADD COLUMN item_quantity INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE order_items DROP COLUMN quantity`,
+			`ALTER TABLE order_items RENAME COLUMN item_quantity TO quantity`,
+		},
+	},
+	{
+		version: 7,
+		name:    "add food modifier groups",
+		statements: []string{
+			`ALTER TABLE foods ADD COLUMN modifier_groups TEXT`,
+			`ALTER TABLE order_items ADD COLUMN modifiers TEXT`,
+		},
+	},
+	{
+		version: 8,
+		name:    "add kitchen stations and tickets",
+		statements: []string{
+			`ALTER TABLE menus ADD COLUMN station TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE foods ADD COLUMN station TEXT`,
+			`CREATE TABLE kitchen_tickets (
+				ticket_id     TEXT PRIMARY KEY,
+				order_id      TEXT NOT NULL REFERENCES orders (order_id),
+				order_item_id TEXT NOT NULL REFERENCES order_items (order_item_id),
+				table_number  INTEGER,
+				station       TEXT NOT NULL,
+				food_name     TEXT,
+				size          TEXT,
+				quantity      INTEGER NOT NULL,
+				modifiers     TEXT,
+				status        TEXT NOT NULL,
+				created_at    TIMESTAMP NOT NULL,
+				updated_at    TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX idx_kitchen_tickets_station_status ON kitchen_tickets (station, status)`,
+		},
+	},
+	{
+		version: 9,
+		name:    "add user roles",
+		statements: []string{
+			`ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 10,
+		name:    "add refresh tokens",
+		statements: []string{
+			`CREATE TABLE refresh_tokens (
+				token_id   TEXT PRIMARY KEY,
+				family_id  TEXT NOT NULL,
+				user_id    TEXT NOT NULL REFERENCES users (user_id),
+				expires_at TIMESTAMP NOT NULL,
+				used_at    TIMESTAMP,
+				revoked_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "add email verification and password resets",
+		statements: []string{
+			`ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE`,
+			`CREATE TABLE password_resets (
+				reset_id   TEXT PRIMARY KEY,
+				user_id    TEXT NOT NULL REFERENCES users (user_id),
+				token_hash TEXT NOT NULL UNIQUE,
+				expires_at TIMESTAMP NOT NULL,
+				used_at    TIMESTAMP,
+				created_at TIMESTAMP NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 12,
+		name:    "add login throttles and audit events",
+		statements: []string{
+			`CREATE TABLE login_throttles (
+				key             TEXT PRIMARY KEY,
+				failures        INTEGER NOT NULL,
+				last_failure_at TIMESTAMP NOT NULL,
+				locked_until    TIMESTAMP
+			)`,
+			`CREATE TABLE audit_events (
+				event_id   TEXT PRIMARY KEY,
+				type       TEXT NOT NULL,
+				user_id    TEXT NOT NULL,
+				actor_id   TEXT NOT NULL,
+				ip         TEXT NOT NULL,
+				detail     TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX idx_audit_events_created_at ON audit_events (created_at)`,
+		},
+	},
+	{
+		version: 13,
+		name:    "add totp two-factor authentication",
+		statements: []string{
+			`ALTER TABLE users ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE`,
+			`ALTER TABLE users ADD COLUMN totp_secret TEXT`,
+			`ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0`,
+			`ALTER TABLE users ADD COLUMN recovery_codes TEXT`,
+			`ALTER TABLE refresh_tokens ADD COLUMN mfa BOOLEAN NOT NULL DEFAULT FALSE`,
+		},
+	},
+	{
+		version: 14,
+		name:    "add api keys",
+		statements: []string{
+			`CREATE TABLE api_keys (
+				key_id       TEXT PRIMARY KEY,
+				name         TEXT NOT NULL,
+				prefix       TEXT NOT NULL,
+				key_hash     TEXT NOT NULL UNIQUE,
+				scopes       TEXT NOT NULL,
+				created_by   TEXT NOT NULL,
+				expires_at   TIMESTAMP,
+				last_used_at TIMESTAMP,
+				revoked_at   TIMESTAMP,
+				created_at   TIMESTAMP NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 15,
+		name:    "add user deactivation",
+		statements: []string{
+			`ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMP`,
+		},
+	},
+	{
+		// existing rows belong to the default tenant
+		version: 16,
+		name:    "add tenants",
+		statements: []string{
+			`CREATE TABLE tenants (
+				tenant_id        TEXT PRIMARY KEY,
+				name             TEXT NOT NULL,
+				currency         TEXT,
+				default_tax_rate DOUBLE PRECISION,
+				tax_rates        TEXT,
+				timezone         TEXT,
+				created_at       TIMESTAMP NOT NULL,
+				updated_at       TIMESTAMP NOT NULL
+			)`,
+			`ALTER TABLE foods ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE menus ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE orders ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE order_items ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE tables ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE notes ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE invoices ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE kitchen_tickets ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE users ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE audit_events ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX idx_foods_tenant_id ON foods (tenant_id)`,
+			`CREATE INDEX idx_menus_tenant_id ON menus (tenant_id)`,
+			`CREATE INDEX idx_orders_tenant_id ON orders (tenant_id)`,
+			`CREATE INDEX idx_order_items_tenant_id ON order_items (tenant_id)`,
+			`CREATE INDEX idx_tables_tenant_id ON tables (tenant_id)`,
+			`CREATE INDEX idx_kitchen_tickets_tenant_id ON kitchen_tickets (tenant_id)`,
+		},
+	},
+	{
+		version: 17,
+		name:    "add reservations",
+		statements: []string{
+			`CREATE TABLE reservations (
+				reservation_id TEXT PRIMARY KEY,
+				name           TEXT NOT NULL,
+				phone          TEXT NOT NULL,
+				email          TEXT,
+				party_size     INTEGER NOT NULL,
+				reserved_at    TIMESTAMP NOT NULL,
+				ends_at        TIMESTAMP NOT NULL,
+				table_id       TEXT NOT NULL REFERENCES tables (table_id),
+				status         TEXT NOT NULL,
+				notes          TEXT,
+				created_at     TIMESTAMP NOT NULL,
+				updated_at     TIMESTAMP NOT NULL,
+				tenant_id      TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX idx_reservations_table_id_reserved_at ON reservations (table_id, reserved_at)`,
+			`CREATE INDEX idx_reservations_tenant_id ON reservations (tenant_id)`,
+		},
+	},
+	{
+		version: 18,
+		name:    "add waitlist",
+		statements: []string{
+			`CREATE TABLE waitlist_entries (
+				entry_id            TEXT PRIMARY KEY,
+				name                TEXT NOT NULL,
+				party_size          INTEGER NOT NULL,
+				phone               TEXT NOT NULL,
+				notes               TEXT,
+				status              TEXT NOT NULL,
+				quoted_wait_minutes INTEGER NOT NULL,
+				notified_at         TIMESTAMP,
+				seated_at           TIMESTAMP,
+				table_id            TEXT REFERENCES tables (table_id),
+				order_id            TEXT REFERENCES orders (order_id),
+				created_at          TIMESTAMP NOT NULL,
+				updated_at          TIMESTAMP NOT NULL,
+				tenant_id           TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX idx_waitlist_entries_status ON waitlist_entries (status)`,
+			`CREATE INDEX idx_waitlist_entries_tenant_id ON waitlist_entries (tenant_id)`,
+		},
+	},
+	{
+		version: 19,
+		name:    "add floor plan",
+		statements: []string{
+			`ALTER TABLE tables ADD COLUMN section TEXT`,
+			`ALTER TABLE tables ADD COLUMN x DOUBLE PRECISION`,
+			`ALTER TABLE tables ADD COLUMN y DOUBLE PRECISION`,
+			`ALTER TABLE tables ADD COLUMN shape TEXT`,
+			`ALTER TABLE tables ADD COLUMN server_id TEXT`,
+			`ALTER TABLE tables ADD COLUMN status TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 20,
+		name:    "add split payments",
+		statements: []string{
+			`ALTER TABLE order_items ADD COLUMN seat INTEGER`,
+			`CREATE TABLE payments (
+				payment_id     TEXT PRIMARY KEY,
+				invoice_id     TEXT NOT NULL REFERENCES invoices (invoice_id),
+				order_id       TEXT NOT NULL,
+				label          TEXT NOT NULL,
+				seat           INTEGER,
+				order_item_ids TEXT,
+				amount         TEXT NOT NULL,
+				payment_method TEXT,
+				status         TEXT NOT NULL,
+				paid_at        TIMESTAMP,
+				created_at     TIMESTAMP NOT NULL,
+				updated_at     TIMESTAMP NOT NULL,
+				tenant_id      TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX idx_payments_invoice_id ON payments (invoice_id)`,
+			`CREATE INDEX idx_payments_tenant_id ON payments (tenant_id)`,
+		},
+	},
+}
+
+// Migrate applies every migration newer than the recorded schema version,
+// each inside its own transaction.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := apply(ctx, db, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range m.statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+		m.version, m.name, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,57 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+)
+
+type noteRepository struct {
+	db *sql.DB
+}
+
+const noteColumns = `note_id, title, text, created_at, updated_at, tenant_id`
+
+func scanNote(row scanner) (models.Note, error) {
+	var note models.Note
+	err := row.Scan(&note.Note_id, &note.Title, &note.Text, &note.Created_at, &note.Updated_at, &note.Tenant_id)
+	note.ID = objectID(note.Note_id)
+	return note, err
+}
+
+func (r *noteRepository) List(ctx context.Context) ([]models.Note, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+noteColumns+` FROM notes WHERE tenant_id = $1 ORDER BY created_at, note_id`, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []models.Note{}
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+func (r *noteRepository) FindByID(ctx context.Context, noteId string) (models.Note, error) {
+	note, err := scanNote(r.db.QueryRowContext(ctx, `SELECT `+noteColumns+` FROM notes WHERE note_id = $1 AND tenant_id = $2`, noteId, repository.TenantID(ctx)))
+	return note, notFound(err)
+}
+
+func (r *noteRepository) Create(ctx context.Context, note models.Note) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO notes (`+noteColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		note.Note_id, note.Title, note.Text, note.Created_at, note.Updated_at, repository.TenantID(ctx))
+	return err
+}
+
+func (r *noteRepository) Update(ctx context.Context, note models.Note) error {
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE notes SET title = $2, text = $3, created_at = $4, updated_at = $5 WHERE note_id = $1 AND tenant_id = $6`,
+		note.Note_id, note.Title, note.Text, note.Created_at, note.Updated_at, repository.TenantID(ctx)))
+}
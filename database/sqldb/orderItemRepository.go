@@ -0,0 +1,167 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"restaurant_management/views"
+)
+
+type orderItemRepository struct {
+	db *sql.DB
+}
+
+const orderItemColumns = `order_item_id, order_id, food_id, size, quantity, modifiers, seat, created_at, updated_at, tenant_id`
+
+func scanOrderItem(row scanner) (models.OrderItem, error) {
+	var orderItem models.OrderItem
+	err := row.Scan(&orderItem.Order_item_id, &orderItem.Order_id, &orderItem.Food_id, &orderItem.Size, &orderItem.Quantity, jsonColumn{&orderItem.Modifiers}, &orderItem.Seat, &orderItem.Created_at, &orderItem.Updated_at, &orderItem.Tenant_id)
+	orderItem.ID = objectID(orderItem.Order_item_id)
+	return orderItem, err
+}
+
+func (r *orderItemRepository) List(ctx context.Context) ([]models.OrderItem, error) {
+	return r.list(ctx, `SELECT `+orderItemColumns+` FROM order_items WHERE tenant_id = $1 ORDER BY created_at, order_item_id`, repository.TenantID(ctx))
+}
+
+func (r *orderItemRepository) ListByOrder(ctx context.Context, orderId string) ([]models.OrderItem, error) {
+	return r.list(ctx, `SELECT `+orderItemColumns+` FROM order_items WHERE order_id = $1 AND tenant_id = $2 ORDER BY created_at, order_item_id`, orderId, repository.TenantID(ctx))
+}
+
+func (r *orderItemRepository) list(ctx context.Context, query string, args ...any) ([]models.OrderItem, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orderItems := []models.OrderItem{}
+	for rows.Next() {
+		orderItem, err := scanOrderItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		orderItems = append(orderItems, orderItem)
+	}
+	return orderItems, rows.Err()
+}
+
+func (r *orderItemRepository) FindByID(ctx context.Context, orderItemId string) (models.OrderItem, error) {
+	orderItem, err := scanOrderItem(r.db.QueryRowContext(ctx, `SELECT `+orderItemColumns+` FROM order_items WHERE order_item_id = $1 AND tenant_id = $2`, orderItemId, repository.TenantID(ctx)))
+	return orderItem, notFound(err)
+}
+
+func (r *orderItemRepository) CreateMany(ctx context.Context, orderItems []models.OrderItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range orderItems {
+		if err := insertOrderItem(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertOrderItem(ctx context.Context, db execer, item models.OrderItem) error {
+	modifiers, err := jsonValue(item.Modifiers)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO order_items (`+orderItemColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		item.Order_item_id, item.Order_id, item.Food_id, item.Size, item.Quantity, modifiers, item.Seat, item.Created_at, item.Updated_at, repository.TenantID(ctx))
+	return err
+}
+
+// Update first touches the order of the item, which locks its row and
+// checks that the items of the order can still be edited.
+func (r *orderItemRepository) Update(ctx context.Context, orderItem models.OrderItem) error {
+	modifiers, err := jsonValue(orderItem.Modifiers)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	args, editable := statusIn([]any{orderItem.Order_item_id, orderItem.Updated_at, repository.TenantID(ctx)}, models.ItemEditableOrderStatuses)
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE orders SET updated_at = $2 WHERE tenant_id = $3 AND `+editable+`
+		AND order_id = (SELECT order_id FROM order_items WHERE order_item_id = $1 AND tenant_id = $3)`, args...))
+	if err == repository.ErrNotFound {
+		tx.Rollback()
+		if _, err := r.FindByID(ctx, orderItem.Order_item_id); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE order_items SET food_id = $2, size = $3, quantity = $4, modifiers = $5, seat = $6, updated_at = $7 WHERE order_item_id = $1 AND tenant_id = $8`,
+		orderItem.Order_item_id, orderItem.Food_id, orderItem.Size, orderItem.Quantity, modifiers, orderItem.Seat, orderItem.Updated_at, repository.TenantID(ctx)))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ItemsByOrder left-joins the items of an order with their food, order and
+// table, then groups them like the Mongo pipeline does.
+func (r *orderItemRepository) ItemsByOrder(ctx context.Context, id string) ([]views.OrderItemsView, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT oi.order_item_id, oi.seat, oi.size, oi.quantity, oi.modifiers, f.food_id, f.price, f.sizes, f.name, f.food_image, m.category, t.table_number, t.table_id, o.order_id
+		FROM order_items oi
+		LEFT JOIN foods f ON f.food_id = oi.food_id
+		LEFT JOIN menus m ON m.menu_id = f.menu_id
+		LEFT JOIN orders o ON o.order_id = oi.order_id
+		LEFT JOIN tables t ON t.table_id = o.table_id
+		WHERE oi.order_id = $1 AND oi.tenant_id = $2
+		ORDER BY oi.created_at, oi.order_item_id`, id, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []views.OrderItemLine{}
+	for rows.Next() {
+		var line views.OrderItemLine
+		var item models.OrderItem
+		var food models.Food
+		var foodId, category, tableId, orderId sql.NullString
+		if err := rows.Scan(&line.Order_item_id, &line.Seat, &line.Size, &line.Quantity, jsonColumn{&item.Modifiers}, &foodId, &food.Price, jsonColumn{&food.Sizes}, &line.Food_name, &line.Food_image, &category, &line.Table_number, &tableId, &orderId); err != nil {
+			return nil, err
+		}
+		line.Modifiers = item.Modifiers
+		item.Size = line.Size
+		price, err := item.UnitPrice(food)
+		if err != nil {
+			return nil, err
+		}
+		if price != nil {
+			amount, err := price.Mul(int64(line.Quantity))
+			if err != nil {
+				return nil, err
+			}
+			line.Price, line.Amount = price, &amount
+		}
+		line.Food_id = foodId.String
+		line.Menu_category = category.String
+		line.Table_id = tableId.String
+		line.Order_id = orderId.String
+		line.Total_count = 1
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return views.GroupOrderItems(lines)
+}
@@ -0,0 +1,400 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type orderRepository struct {
+	db *sql.DB
+}
+
+const orderColumns = `order_id, table_id, order_date, created_at, updated_at, status, discounts, tenant_id`
+
+func scanOrder(row scanner) (models.Order, error) {
+	var order models.Order
+	err := row.Scan(&order.Order_id, &order.Table_id, &order.Order_date, &order.Created_at, &order.Updated_at, &order.Status, jsonColumn{&order.Discounts}, &order.Tenant_id)
+	order.ID = objectID(order.Order_id)
+	return order, err
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertStatusChange(ctx context.Context, db execer, orderId string, change models.OrderStatusChange) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO order_status_history (order_status_change_id, order_id, from_status, to_status, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		primitive.NewObjectID().Hex(), orderId, change.From, change.To, change.Changed_by, change.Changed_at)
+	return err
+}
+
+// loadHistory fills Status_history on orders. An empty orderId loads the
+// history of every order.
+func (r *orderRepository) loadHistory(ctx context.Context, orders []models.Order, orderId string) error {
+	query := `SELECT order_id, from_status, to_status, changed_by, changed_at FROM order_status_history`
+	args := []any{}
+	if orderId != "" {
+		query += ` WHERE order_id = $1`
+		args = append(args, orderId)
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY changed_at, order_status_change_id`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	history := map[string][]models.OrderStatusChange{}
+	for rows.Next() {
+		var id string
+		var change models.OrderStatusChange
+		if err := rows.Scan(&id, &change.From, &change.To, &change.Changed_by, &change.Changed_at); err != nil {
+			return err
+		}
+		history[id] = append(history[id], change)
+	}
+	for i := range orders {
+		orders[i].Status_history = history[orders[i].Order_id]
+	}
+	return rows.Err()
+}
+
+func (r *orderRepository) List(ctx context.Context) ([]models.Order, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE tenant_id = $1 ORDER BY created_at, order_id`, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, r.loadHistory(ctx, orders, "")
+}
+
+func (r *orderRepository) FindByID(ctx context.Context, orderId string) (models.Order, error) {
+	order, err := scanOrder(r.db.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE order_id = $1 AND tenant_id = $2`, orderId, repository.TenantID(ctx)))
+	if err != nil {
+		return order, notFound(err)
+	}
+	orders := []models.Order{order}
+	err = r.loadHistory(ctx, orders, orderId)
+	return orders[0], err
+}
+
+func (r *orderRepository) ListAtTable(ctx context.Context, tableId string, statuses []string) ([]models.Order, error) {
+	args, inStatus := statusIn([]any{tableId, repository.TenantID(ctx)}, statuses)
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE table_id = $1 AND tenant_id = $2 AND `+inStatus+` ORDER BY created_at, order_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// a table holds few orders at a time, so their histories are loaded
+	// one by one rather than all at once as List does
+	for i := range orders {
+		if err := r.loadHistory(ctx, orders[i:i+1], orders[i].Order_id); err != nil {
+			return nil, err
+		}
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) Create(ctx context.Context, order models.Order, orderItems ...models.OrderItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertOrder(ctx, tx, order); err != nil {
+		return err
+	}
+	for _, item := range orderItems {
+		if err := insertOrderItem(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// insertOrder inserts order and its history.
+func insertOrder(ctx context.Context, db execer, order models.Order) error {
+	discounts, err := jsonValue(order.Discounts)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO orders (`+orderColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		order.Order_id, order.Table_id, order.Order_date, order.Created_at, order.Updated_at, order.Status, discounts, repository.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	for _, change := range order.Status_history {
+		if err := insertStatusChange(ctx, db, order.Order_id, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *orderRepository) Update(ctx context.Context, order models.Order) error {
+	discounts, err := jsonValue(order.Discounts)
+	if err != nil {
+		return err
+	}
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE orders SET table_id = $2, order_date = $3, updated_at = $4, discounts = $5 WHERE order_id = $1 AND tenant_id = $6`,
+		order.Order_id, order.Table_id, order.Order_date, order.Updated_at, discounts, repository.TenantID(ctx)))
+}
+
+func (r *orderRepository) UpdateStatus(ctx context.Context, orderId string, change models.OrderStatusChange) error {
+	return r.updateStatus(ctx, orderId, change, nil)
+}
+
+func (r *orderRepository) SendToKitchen(ctx context.Context, orderId string, change models.OrderStatusChange, tickets []models.Ticket) error {
+	return r.updateStatus(ctx, orderId, change, tickets)
+}
+
+// updateStatus moves the order by change, voiding its open tickets when it
+// is cancelled or voided, and stores tickets.
+func (r *orderRepository) updateStatus(ctx context.Context, orderId string, change models.OrderStatusChange, tickets []models.Ticket) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE orders SET status = $3, updated_at = $4 WHERE order_id = $1 AND status = $2 AND tenant_id = $5`,
+		orderId, change.From, change.To, change.Changed_at, repository.TenantID(ctx)))
+	if err == repository.ErrNotFound {
+		// release the connection before looking the order up again
+		tx.Rollback()
+		if _, err := r.FindByID(ctx, orderId); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	if err := insertStatusChange(ctx, tx, orderId, change); err != nil {
+		return err
+	}
+	if change.To == models.OrderStatusCancelled || change.To == models.OrderStatusVoided {
+		if err := voidTickets(ctx, tx, orderId, change.Changed_at); err != nil {
+			return err
+		}
+	}
+	if err := insertTickets(ctx, tx, tickets); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *orderRepository) Transfer(ctx context.Context, orderId string, tableId string, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	args, editable := statusIn([]any{orderId, tableId, at, repository.TenantID(ctx)}, models.EditableOrderStatuses)
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE orders SET table_id = $2, updated_at = $3 WHERE order_id = $1 AND tenant_id = $4 AND `+editable, args...))
+	if err == repository.ErrNotFound {
+		tx.Rollback()
+		return r.conflict(ctx, orderId)
+	}
+	if err != nil {
+		return err
+	}
+	if err := pointTickets(ctx, tx, orderId, at); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *orderRepository) MoveItems(ctx context.Context, from string, to string, orderItemIds []string, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, orderId := range []string{from, to} {
+		if err := touchEditable(ctx, tx, orderId, at); err == repository.ErrNotFound {
+			tx.Rollback()
+			return r.conflict(ctx, orderId)
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := moveItems(ctx, tx, from, to, orderItemIds, at); err != nil {
+		return err
+	}
+	if err := pointTickets(ctx, tx, to, at); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *orderRepository) SplitOff(ctx context.Context, from string, to models.Order, orderItemIds []string, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := touchEditable(ctx, tx, from, at); err == repository.ErrNotFound {
+		tx.Rollback()
+		return r.conflict(ctx, from)
+	} else if err != nil {
+		return err
+	}
+	if err := insertOrder(ctx, tx, to); err != nil {
+		return err
+	}
+	if err := moveItems(ctx, tx, from, to.Order_id, orderItemIds, at); err != nil {
+		return err
+	}
+	if err := pointTickets(ctx, tx, to.Order_id, at); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// moveItems moves the order items orderItemIds from order from to order to.
+// It returns ErrConflict if an item is not on from.
+func moveItems(ctx context.Context, tx *sql.Tx, from string, to string, orderItemIds []string, at time.Time) error {
+	for _, orderItemId := range orderItemIds {
+		err := updated(tx.ExecContext(ctx,
+			`UPDATE order_items SET order_id = $3, updated_at = $4 WHERE order_item_id = $1 AND order_id = $2 AND tenant_id = $5`,
+			orderItemId, from, to, at, repository.TenantID(ctx)))
+		if err == repository.ErrNotFound {
+			return repository.ErrConflict
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *orderRepository) Merge(ctx context.Context, from []string, into string, tableId string, change models.OrderStatusChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	args, editable := statusIn([]any{into, tableId, change.Changed_at, repository.TenantID(ctx)}, models.EditableOrderStatuses)
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE orders SET table_id = $2, updated_at = $3 WHERE order_id = $1 AND tenant_id = $4 AND `+editable, args...))
+	if err == repository.ErrNotFound {
+		tx.Rollback()
+		return r.conflict(ctx, into)
+	}
+	if err != nil {
+		return err
+	}
+	for _, orderId := range from {
+		// the status is read and changed in the same transaction; the
+		// update only matches if nobody changed it in between
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE order_id = $1 AND tenant_id = $2`, orderId, repository.TenantID(ctx)).Scan(&status)
+		if err == sql.ErrNoRows {
+			return repository.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(models.EditableOrderStatuses, status) {
+			return repository.ErrConflict
+		}
+		orderChange := change
+		orderChange.From = status
+		err = updated(tx.ExecContext(ctx,
+			`UPDATE orders SET status = $3, updated_at = $4 WHERE order_id = $1 AND status = $2 AND tenant_id = $5`,
+			orderId, orderChange.From, orderChange.To, orderChange.Changed_at, repository.TenantID(ctx)))
+		if err == repository.ErrNotFound {
+			return repository.ErrConflict
+		}
+		if err != nil {
+			return err
+		}
+		if err := insertStatusChange(ctx, tx, orderId, orderChange); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE order_items SET order_id = $2, updated_at = $3 WHERE order_id = $1 AND tenant_id = $4`,
+			orderId, into, change.Changed_at, repository.TenantID(ctx))
+		if err != nil {
+			return err
+		}
+	}
+	if err := pointTickets(ctx, tx, into, change.Changed_at); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// touchEditable sets updated_at of an order that can still change, which
+// also locks its row for the rest of tx. It returns ErrNotFound if there is
+// no such order.
+func touchEditable(ctx context.Context, tx *sql.Tx, orderId string, at time.Time) error {
+	args, editable := statusIn([]any{orderId, at, repository.TenantID(ctx)}, models.EditableOrderStatuses)
+	return updated(tx.ExecContext(ctx,
+		`UPDATE orders SET updated_at = $2 WHERE order_id = $1 AND tenant_id = $3 AND `+editable, args...))
+}
+
+// statusIn appends statuses to args and returns the condition that matches
+// an order, or a ticket, in one of them.
+func statusIn(args []any, statuses []string) ([]any, string) {
+	placeholders := []string{}
+	for _, status := range statuses {
+		args = append(args, status)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	return args, `status IN (` + strings.Join(placeholders, ", ") + `)`
+}
+
+// conflict tells an order that is missing from one that changed meanwhile.
+// Call it outside any transaction.
+func (r *orderRepository) conflict(ctx context.Context, orderId string) error {
+	if _, err := r.FindByID(ctx, orderId); err != nil {
+		return err
+	}
+	return repository.ErrConflict
+}
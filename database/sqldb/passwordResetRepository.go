@@ -0,0 +1,50 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+const passwordResetColumns = `reset_id, user_id, token_hash, expires_at, used_at, created_at`
+
+func scanPasswordReset(row scanner) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := row.Scan(&reset.Reset_id, &reset.User_id, &reset.Token_hash, &reset.Expires_at, &reset.Used_at, &reset.Created_at)
+	reset.ID = objectID(reset.Reset_id)
+	return reset, err
+}
+
+func (r *passwordResetRepository) FindByTokenHash(ctx context.Context, tokenHash string) (models.PasswordReset, error) {
+	reset, err := scanPasswordReset(r.db.QueryRowContext(ctx, `SELECT `+passwordResetColumns+` FROM password_resets WHERE token_hash = $1`, tokenHash))
+	return reset, notFound(err)
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, reset models.PasswordReset) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO password_resets (`+passwordResetColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		reset.Reset_id, reset.User_id, reset.Token_hash, reset.Expires_at, reset.Used_at, reset.Created_at)
+	return err
+}
+
+func (r *passwordResetRepository) MarkUsed(ctx context.Context, resetId string, at time.Time) error {
+	err := updated(r.db.ExecContext(ctx,
+		`UPDATE password_resets SET used_at = $2 WHERE reset_id = $1 AND used_at IS NULL`, resetId, at))
+	if err == repository.ErrNotFound {
+		var count int64
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM password_resets WHERE reset_id = $1`, resetId).Scan(&count); err != nil {
+			return err
+		}
+		if count == 0 {
+			return repository.ErrNotFound
+		}
+		return repository.ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type paymentRepository struct {
+	db *sql.DB
+}
+
+const paymentColumns = `payment_id, invoice_id, order_id, label, seat, order_item_ids, amount, payment_method, status, paid_at, created_at, updated_at, tenant_id`
+
+func scanPayment(row scanner) (models.Payment, error) {
+	var payment models.Payment
+	err := row.Scan(&payment.Payment_id, &payment.Invoice_id, &payment.Order_id, &payment.Label, &payment.Seat,
+		jsonColumn{&payment.Order_item_ids}, &payment.Amount, &payment.Payment_method, &payment.Status, &payment.Paid_at,
+		&payment.Created_at, &payment.Updated_at, &payment.Tenant_id)
+	payment.ID = objectID(payment.Payment_id)
+	return payment, err
+}
+
+func (r *paymentRepository) ListByInvoice(ctx context.Context, invoiceId string) ([]models.Payment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+paymentColumns+` FROM payments WHERE invoice_id = $1 AND tenant_id = $2 ORDER BY created_at, payment_id`,
+		invoiceId, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
+
+func (r *paymentRepository) FindByID(ctx context.Context, paymentId string) (models.Payment, error) {
+	payment, err := scanPayment(r.db.QueryRowContext(ctx,
+		`SELECT `+paymentColumns+` FROM payments WHERE payment_id = $1 AND tenant_id = $2`, paymentId, repository.TenantID(ctx)))
+	return payment, notFound(err)
+}
+
+// Split touches the invoice row first so that splits and payments of the
+// same invoice wait for each other.
+func (r *paymentRepository) Split(ctx context.Context, invoiceId string, paid int, payments []models.Payment, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = updated(tx.ExecContext(ctx, `UPDATE invoices SET updated_at = $2 WHERE invoice_id = $1 AND tenant_id = $3`,
+		invoiceId, at, repository.TenantID(ctx)))
+	if err != nil {
+		return err
+	}
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM payments WHERE invoice_id = $1 AND tenant_id = $2 AND status = $3`,
+		invoiceId, repository.TenantID(ctx), models.PaymentStatusPaid).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count != paid {
+		return repository.ErrConflict
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE payments SET status = $3, updated_at = $4 WHERE invoice_id = $1 AND tenant_id = $2 AND status = $5`,
+		invoiceId, repository.TenantID(ctx), models.PaymentStatusVoided, at, models.PaymentStatusPending)
+	if err != nil {
+		return err
+	}
+	for _, payment := range payments {
+		if err := insertPayment(ctx, tx, payment); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertPayment(ctx context.Context, tx *sql.Tx, payment models.Payment) error {
+	itemIds, err := jsonValue(payment.Order_item_ids)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO payments (`+paymentColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		payment.Payment_id, payment.Invoice_id, payment.Order_id, payment.Label, payment.Seat, itemIds, payment.Amount,
+		payment.Payment_method, payment.Status, payment.Paid_at, payment.Created_at, payment.Updated_at, repository.TenantID(ctx))
+	return err
+}
+
+// Pay touches the invoice row first as well, so a payment cannot slip in
+// between Split counting the paid shares and voiding the pending ones, nor
+// two payments both find the other one still pending.
+func (r *paymentRepository) Pay(ctx context.Context, paymentId string, method string, change models.OrderStatusChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE invoices SET updated_at = updated_at
+		WHERE invoice_id = (SELECT invoice_id FROM payments WHERE payment_id = $1 AND tenant_id = $2) AND tenant_id = $2`,
+		paymentId, repository.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	var invoiceId, orderId string
+	err = tx.QueryRowContext(ctx, `SELECT invoice_id, order_id FROM payments WHERE payment_id = $1 AND tenant_id = $2`,
+		paymentId, repository.TenantID(ctx)).Scan(&invoiceId, &orderId)
+	if err != nil {
+		return notFound(err)
+	}
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE orders SET updated_at = updated_at WHERE order_id = $1 AND status = $2 AND tenant_id = $3`,
+		orderId, change.From, repository.TenantID(ctx)))
+	if err == repository.ErrNotFound {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE payments SET status = $2, payment_method = $3, paid_at = $4, updated_at = $4
+		WHERE payment_id = $1 AND status = $5 AND tenant_id = $6`,
+		paymentId, models.PaymentStatusPaid, method, change.Changed_at, models.PaymentStatusPending, repository.TenantID(ctx)))
+	if err == repository.ErrNotFound {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	var pending int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM payments WHERE invoice_id = $1 AND tenant_id = $2 AND status = $3`,
+		invoiceId, repository.TenantID(ctx), models.PaymentStatusPending).Scan(&pending)
+	if err != nil {
+		return err
+	}
+	if pending == 0 {
+		_, err = tx.ExecContext(ctx, `UPDATE orders SET status = $2, updated_at = $3 WHERE order_id = $1 AND tenant_id = $4`,
+			orderId, change.To, change.Changed_at, repository.TenantID(ctx))
+		if err != nil {
+			return err
+		}
+		if err := insertStatusChange(ctx, tx, orderId, change); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,58 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+const refreshTokenColumns = `token_id, family_id, user_id, mfa, expires_at, used_at, revoked_at, created_at`
+
+func scanRefreshToken(row scanner) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := row.Scan(&token.Token_id, &token.Family_id, &token.User_id, &token.Mfa, &token.Expires_at, &token.Used_at, &token.Revoked_at, &token.Created_at)
+	token.ID = objectID(token.Token_id)
+	return token, err
+}
+
+func (r *refreshTokenRepository) FindByID(ctx context.Context, tokenId string) (models.RefreshToken, error) {
+	token, err := scanRefreshToken(r.db.QueryRowContext(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_id = $1`, tokenId))
+	return token, notFound(err)
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token models.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (`+refreshTokenColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		token.Token_id, token.Family_id, token.User_id, token.Mfa, token.Expires_at, token.Used_at, token.Revoked_at, token.Created_at)
+	return err
+}
+
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, tokenId string, at time.Time) error {
+	err := updated(r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET used_at = $2 WHERE token_id = $1 AND used_at IS NULL`, tokenId, at))
+	if err == repository.ErrNotFound {
+		if _, err := r.FindByID(ctx, tokenId); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyId string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`, familyId, at)
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeUser(ctx context.Context, userId string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`, userId, at)
+	return err
+}
@@ -0,0 +1,137 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type reservationRepository struct {
+	db *sql.DB
+}
+
+const reservationColumns = `reservation_id, name, phone, email, party_size, reserved_at, ends_at, table_id, status, notes, created_at, updated_at, tenant_id`
+
+func scanReservation(row scanner) (models.Reservation, error) {
+	var reservation models.Reservation
+	err := row.Scan(&reservation.Reservation_id, &reservation.Name, &reservation.Phone, &reservation.Email, &reservation.Party_size,
+		&reservation.Reserved_at, &reservation.Ends_at, &reservation.Table_id, &reservation.Status, &reservation.Notes,
+		&reservation.Created_at, &reservation.Updated_at, &reservation.Tenant_id)
+	reservation.ID = objectID(reservation.Reservation_id)
+	return reservation, err
+}
+
+func (r *reservationRepository) List(ctx context.Context, from time.Time, to time.Time) ([]models.Reservation, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+reservationColumns+` FROM reservations
+		WHERE reserved_at < $1 AND ends_at > $2 AND tenant_id = $3
+		ORDER BY reserved_at, reservation_id`, to.UTC(), from.UTC(), repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []models.Reservation{}
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	return reservations, rows.Err()
+}
+
+func (r *reservationRepository) FindByID(ctx context.Context, reservationId string) (models.Reservation, error) {
+	reservation, err := scanReservation(r.db.QueryRowContext(ctx,
+		`SELECT `+reservationColumns+` FROM reservations WHERE reservation_id = $1 AND tenant_id = $2`, reservationId, repository.TenantID(ctx)))
+	return reservation, notFound(err)
+}
+
+func (r *reservationRepository) Create(ctx context.Context, reservation models.Reservation) error {
+	tx, err := r.lockTable(ctx, reservation)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO reservations (`+reservationColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		reservation.Reservation_id, reservation.Name, reservation.Phone, reservation.Email, reservation.Party_size,
+		reservation.Reserved_at.UTC(), reservation.Ends_at.UTC(), reservation.Table_id, reservation.Status, reservation.Notes,
+		reservation.Created_at, reservation.Updated_at, repository.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *reservationRepository) Update(ctx context.Context, reservation models.Reservation) error {
+	// reservations are never deleted, so a missing one cannot appear
+	// between this check and the update
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reservations WHERE reservation_id = $1 AND tenant_id = $2`,
+		reservation.Reservation_id, repository.TenantID(ctx)).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return repository.ErrNotFound
+	}
+
+	tx, err := r.lockTable(ctx, reservation)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = updated(tx.ExecContext(ctx,
+		`UPDATE reservations SET name = $2, phone = $3, email = $4, party_size = $5, reserved_at = $6, ends_at = $7,
+		table_id = $8, status = $9, notes = $10, updated_at = $11
+		WHERE reservation_id = $1 AND tenant_id = $12`,
+		reservation.Reservation_id, reservation.Name, reservation.Phone, reservation.Email, reservation.Party_size,
+		reservation.Reserved_at.UTC(), reservation.Ends_at.UTC(), reservation.Table_id, reservation.Status, reservation.Notes,
+		reservation.Updated_at, repository.TenantID(ctx)))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockTable begins the transaction a reservation is written in. Touching
+// the row of its table makes bookings of the same table wait for each other,
+// so the overlap check that follows cannot race; it returns ErrConflict if
+// another booked reservation holds the table while this one would.
+func (r *reservationRepository) lockTable(ctx context.Context, reservation models.Reservation) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE tables SET updated_at = updated_at WHERE table_id = $1 AND tenant_id = $2`,
+		reservation.Table_id, repository.TenantID(ctx))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if reservation.Status != models.ReservationBooked {
+		return tx, nil
+	}
+
+	var clashes int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM reservations
+		WHERE table_id = $1 AND tenant_id = $2 AND status = $3 AND reservation_id <> $4
+		AND reserved_at < $5 AND ends_at > $6`,
+		reservation.Table_id, repository.TenantID(ctx), models.ReservationBooked, reservation.Reservation_id,
+		reservation.Ends_at.UTC(), reservation.Reserved_at.UTC()).Scan(&clashes)
+	if err == nil && clashes > 0 {
+		err = repository.ErrConflict
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,67 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Driver names accepted by DBInstance.
+const (
+	SQLite   = "sqlite"
+	Postgres = "pgx"
+)
+
+// DBInstance opens the database, checks the connection and brings the
+// schema up to date.
+func DBInstance(driverName string, dataSourceName string) *sql.DB {
+	db, err := Open(driverName, dataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Connected to", driverName, "...")
+	return db
+}
+
+// Open is DBInstance for databases opened while serving, such as those of
+// tenants, where a failure must not stop the server.
+func Open(driverName string, dataSourceName string) (*sql.DB, error) {
+	if driverName == SQLite {
+		// Foreign keys are off by default in SQLite and the pragma is per
+		// connection, so a single connection keeps it (and :memory:) stable.
+		dataSourceName = withPragma(dataSourceName, "foreign_keys(1)")
+	}
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	if driverName == SQLite {
+		db.SetMaxOpenConns(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func withPragma(dataSourceName string, pragma string) string {
+	separator := "?"
+	if strings.Contains(dataSourceName, "?") {
+		separator = "&"
+	}
+	return dataSourceName + separator + "_pragma=" + pragma
+}
@@ -0,0 +1,95 @@
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewStore returns repositories backed by a migrated SQL database.
+func NewStore(db *sql.DB) *repository.Store {
+	return &repository.Store{
+		Foods:          &foodRepository{db: db},
+		Menus:          &menuRepository{db: db},
+		Orders:         &orderRepository{db: db},
+		OrderItems:     &orderItemRepository{db: db},
+		Tables:         &tableRepository{db: db},
+		Users:          &userRepository{db: db},
+		Notes:          &noteRepository{db: db},
+		Invoices:       &invoiceRepository{db: db},
+		Tickets:        &ticketRepository{db: db},
+		RefreshTokens:  &refreshTokenRepository{db: db},
+		PasswordResets: &passwordResetRepository{db: db},
+		LoginThrottles: &loginThrottleRepository{db: db},
+		AuditEvents:    &auditEventRepository{db: db},
+		APIKeys:        &apiKeyRepository{db: db},
+		Tenants:        &tenantRepository{db: db},
+		Reservations:   &reservationRepository{db: db},
+		Waitlist:       &waitlistRepository{db: db},
+		Payments:       &paymentRepository{db: db},
+	}
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// updated reports ErrNotFound when an UPDATE touched no row.
+func updated(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// objectID rebuilds the Mongo style ID the models carry from the hex key
+// stored in the *_id column.
+func objectID(hex string) primitive.ObjectID {
+	id, _ := primitive.ObjectIDFromHex(hex)
+	return id
+}
+
+// jsonValue encodes v for a TEXT column holding JSON. Nil slices, maps and
+// pointers are stored as NULL.
+func jsonValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// jsonColumn decodes a JSON TEXT column into v, leaving v alone on NULL.
+type jsonColumn struct {
+	v any
+}
+
+func (c jsonColumn) Scan(src any) error {
+	switch data := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(data), c.v)
+	case []byte:
+		return json.Unmarshal(data, c.v)
+	}
+	return errors.New("unsupported JSON column type")
+}
@@ -0,0 +1,42 @@
+package sqldb
+
+import (
+	"os"
+	"restaurant_management/repository"
+	"restaurant_management/repository/repositorytest"
+	"testing"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repository.Store {
+		db, err := Open(SQLite, ":memory:")
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return NewStore(db)
+	})
+}
+
+// TestPostgresStore runs against the database at TEST_DATABASE_URL, which
+// it empties first, and is skipped without one.
+func TestPostgresStore(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	repositorytest.Run(t, func(t *testing.T) *repository.Store {
+		db, err := Open(Postgres, url)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if _, err := db.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public`); err != nil {
+			t.Fatalf("emptying the database: %v", err)
+		}
+		if err := Migrate(t.Context(), db); err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		return NewStore(db)
+	})
+}
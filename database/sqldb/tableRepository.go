@@ -0,0 +1,64 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type tableRepository struct {
+	db *sql.DB
+}
+
+const tableColumns = `table_id, number_of_guests, table_number, section, x, y, shape, server_id, status, created_at, updated_at, tenant_id`
+
+func scanTable(row scanner) (models.Table, error) {
+	var table models.Table
+	err := row.Scan(&table.Table_id, &table.Number_of_quests, &table.Table_number, &table.Section, &table.X, &table.Y, &table.Shape, &table.Server_id, &table.Status, &table.Created_at, &table.Updated_at, &table.Tenant_id)
+	table.ID = objectID(table.Table_id)
+	return table, err
+}
+
+func (r *tableRepository) List(ctx context.Context) ([]models.Table, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+tableColumns+` FROM tables WHERE tenant_id = $1 ORDER BY created_at, table_id`, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []models.Table{}
+	for rows.Next() {
+		table, err := scanTable(rows)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+func (r *tableRepository) FindByID(ctx context.Context, tableId string) (models.Table, error) {
+	table, err := scanTable(r.db.QueryRowContext(ctx, `SELECT `+tableColumns+` FROM tables WHERE table_id = $1 AND tenant_id = $2`, tableId, repository.TenantID(ctx)))
+	return table, notFound(err)
+}
+
+func (r *tableRepository) Create(ctx context.Context, table models.Table) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO tables (`+tableColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		table.Table_id, table.Number_of_quests, table.Table_number, table.Section, table.X, table.Y, table.Shape, table.Server_id, table.Status, table.Created_at, table.Updated_at, repository.TenantID(ctx))
+	return err
+}
+
+func (r *tableRepository) Update(ctx context.Context, table models.Table) error {
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE tables SET number_of_guests = $2, table_number = $3, section = $4, x = $5, y = $6, shape = $7, server_id = $8, created_at = $9, updated_at = $10 WHERE table_id = $1 AND tenant_id = $11`,
+		table.Table_id, table.Number_of_quests, table.Table_number, table.Section, table.X, table.Y, table.Shape, table.Server_id, table.Created_at, table.Updated_at, repository.TenantID(ctx)))
+}
+
+func (r *tableRepository) UpdateStatus(ctx context.Context, tableId string, status string, at time.Time) error {
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE tables SET status = $2, updated_at = $3 WHERE table_id = $1 AND tenant_id = $4`,
+		tableId, status, at, repository.TenantID(ctx)))
+}
@@ -0,0 +1,65 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"restaurant_management/models"
+)
+
+type tenantRepository struct {
+	db *sql.DB
+}
+
+const tenantColumns = `tenant_id, name, currency, default_tax_rate, tax_rates, timezone, created_at, updated_at`
+
+func scanTenant(row scanner) (models.Tenant, error) {
+	var tenant models.Tenant
+	err := row.Scan(&tenant.Tenant_id, &tenant.Name, &tenant.Currency, &tenant.Default_tax_rate, jsonColumn{&tenant.Tax_rates},
+		&tenant.Timezone, &tenant.Created_at, &tenant.Updated_at)
+	tenant.ID = objectID(tenant.Tenant_id)
+	return tenant, err
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]models.Tenant, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+tenantColumns+` FROM tenants ORDER BY created_at, tenant_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := []models.Tenant{}
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, rows.Err()
+}
+
+func (r *tenantRepository) FindByID(ctx context.Context, tenantId string) (models.Tenant, error) {
+	tenant, err := scanTenant(r.db.QueryRowContext(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE tenant_id = $1`, tenantId))
+	return tenant, notFound(err)
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant models.Tenant) error {
+	taxRates, err := jsonValue(tenant.Tax_rates)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO tenants (`+tenantColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		tenant.Tenant_id, tenant.Name, tenant.Currency, tenant.Default_tax_rate, taxRates, tenant.Timezone, tenant.Created_at, tenant.Updated_at)
+	return err
+}
+
+func (r *tenantRepository) Update(ctx context.Context, tenant models.Tenant) error {
+	taxRates, err := jsonValue(tenant.Tax_rates)
+	if err != nil {
+		return err
+	}
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE tenants SET name = $2, currency = $3, default_tax_rate = $4, tax_rates = $5, timezone = $6, created_at = $7, updated_at = $8 WHERE tenant_id = $1`,
+		tenant.Tenant_id, tenant.Name, tenant.Currency, tenant.Default_tax_rate, taxRates, tenant.Timezone, tenant.Created_at, tenant.Updated_at))
+}
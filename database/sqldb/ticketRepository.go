@@ -0,0 +1,130 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"strings"
+	"time"
+)
+
+type ticketRepository struct {
+	db *sql.DB
+}
+
+const ticketColumns = `ticket_id, order_id, order_item_id, table_number, station, food_name, size, quantity, modifiers, status, created_at, updated_at, tenant_id`
+
+func scanTicket(row scanner) (models.Ticket, error) {
+	var ticket models.Ticket
+	err := row.Scan(&ticket.Ticket_id, &ticket.Order_id, &ticket.Order_item_id, &ticket.Table_number, &ticket.Station,
+		&ticket.Food_name, &ticket.Size, &ticket.Quantity, jsonColumn{&ticket.Modifiers}, &ticket.Status, &ticket.Created_at, &ticket.Updated_at, &ticket.Tenant_id)
+	ticket.ID = objectID(ticket.Ticket_id)
+	return ticket, err
+}
+
+func (r *ticketRepository) List(ctx context.Context, station string, statuses []string) ([]models.Ticket, error) {
+	if len(statuses) == 0 {
+		return []models.Ticket{}, nil
+	}
+	args := []any{station, repository.TenantID(ctx)}
+	placeholders := []string{}
+	for _, status := range statuses {
+		args = append(args, status)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	return r.list(ctx,
+		`SELECT `+ticketColumns+` FROM kitchen_tickets
+		WHERE ($1 = '' OR station = $1) AND tenant_id = $2 AND status IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY created_at, ticket_id`, args...)
+}
+
+func (r *ticketRepository) ListByOrder(ctx context.Context, orderId string) ([]models.Ticket, error) {
+	return r.list(ctx, `SELECT `+ticketColumns+` FROM kitchen_tickets WHERE order_id = $1 AND tenant_id = $2 ORDER BY created_at, ticket_id`, orderId, repository.TenantID(ctx))
+}
+
+func (r *ticketRepository) list(ctx context.Context, query string, args ...any) ([]models.Ticket, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := []models.Ticket{}
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, rows.Err()
+}
+
+func (r *ticketRepository) FindByID(ctx context.Context, ticketId string) (models.Ticket, error) {
+	ticket, err := scanTicket(r.db.QueryRowContext(ctx, `SELECT `+ticketColumns+` FROM kitchen_tickets WHERE ticket_id = $1 AND tenant_id = $2`, ticketId, repository.TenantID(ctx)))
+	return ticket, notFound(err)
+}
+
+func (r *ticketRepository) CreateMany(ctx context.Context, tickets []models.Ticket) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertTickets(ctx, tx, tickets); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertTickets(ctx context.Context, db execer, tickets []models.Ticket) error {
+	for _, ticket := range tickets {
+		modifiers, err := jsonValue(ticket.Modifiers)
+		if err != nil {
+			return err
+		}
+		_, err = db.ExecContext(ctx,
+			`INSERT INTO kitchen_tickets (`+ticketColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+			ticket.Ticket_id, ticket.Order_id, ticket.Order_item_id, ticket.Table_number, ticket.Station, ticket.Food_name,
+			ticket.Size, ticket.Quantity, modifiers, ticket.Status, ticket.Created_at, ticket.Updated_at, repository.TenantID(ctx))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// voidTickets voids the open tickets of the order orderId.
+func voidTickets(ctx context.Context, db execer, orderId string, at time.Time) error {
+	args, open := statusIn([]any{orderId, models.TicketStatusVoided, at, repository.TenantID(ctx)}, models.OpenTicketStatuses)
+	_, err := db.ExecContext(ctx,
+		`UPDATE kitchen_tickets SET status = $2, updated_at = $3 WHERE order_id = $1 AND tenant_id = $4 AND `+open, args...)
+	return err
+}
+
+// pointTickets points the tickets of the items on the order orderId at that
+// order and the number of its table.
+func pointTickets(ctx context.Context, db execer, orderId string, at time.Time) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE kitchen_tickets SET order_id = $1, updated_at = $2,
+			table_number = (SELECT t.table_number FROM orders o JOIN tables t ON t.table_id = o.table_id WHERE o.order_id = $1)
+		WHERE tenant_id = $3 AND order_item_id IN (SELECT order_item_id FROM order_items WHERE order_id = $1)`,
+		orderId, at, repository.TenantID(ctx))
+	return err
+}
+
+func (r *ticketRepository) UpdateStatus(ctx context.Context, ticketId string, from string, to string, at time.Time) error {
+	err := updated(r.db.ExecContext(ctx,
+		`UPDATE kitchen_tickets SET status = $3, updated_at = $4 WHERE ticket_id = $1 AND status = $2 AND tenant_id = $5`,
+		ticketId, from, to, at, repository.TenantID(ctx)))
+	if err == repository.ErrNotFound {
+		if _, err := r.FindByID(ctx, ticketId); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+)
+
+type userRepository struct {
+	db *sql.DB
+}
+
+const userColumns = `user_id, first_name, last_name, password, email, avatar, phone, role, email_verified, totp_enabled, totp_secret, totp_last_step, recovery_codes, deactivated_at, token, refresh_token, created_at, updated_at, tenant_id`
+
+func scanUser(row scanner) (models.User, error) {
+	var user models.User
+	err := row.Scan(&user.User_id, &user.First_name, &user.Last_name, &user.Password, &user.Email, &user.Avatar, &user.Phone, &user.Role, &user.Email_verified,
+		&user.Totp_enabled, &user.Totp_secret, &user.Totp_last_step, jsonColumn{&user.Recovery_codes}, &user.Deactivated_at,
+		&user.Token, &user.Refresh_token, &user.Created_at, &user.Updated_at, &user.Tenant_id)
+	user.ID = objectID(user.User_id)
+	return user, err
+}
+
+func (r *userRepository) List(ctx context.Context, page repository.Page) ([]models.User, error) {
+	limit := page.Limit
+	if limit < 1 {
+		limit = math.MaxInt64
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE tenant_id = $3 ORDER BY created_at, user_id LIMIT $1 OFFSET $2`,
+		limit, page.Skip, repository.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = nil
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *userRepository) FindByID(ctx context.Context, userId string) (models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE user_id = $1`, userId))
+	return user, notFound(err)
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+	return user, notFound(err)
+}
+
+func (r *userRepository) CountByEmail(ctx context.Context, email string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE email = $1`, email).Scan(&count)
+	return count, err
+}
+
+func (r *userRepository) CountByPhone(ctx context.Context, phone string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE phone = $1`, phone).Scan(&count)
+	return count, err
+}
+
+func (r *userRepository) Create(ctx context.Context, user models.User) error {
+	recoveryCodes, err := jsonValue(user.Recovery_codes)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO users (`+userColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`,
+		user.User_id, user.First_name, user.Last_name, user.Password, user.Email, user.Avatar, user.Phone, user.Role, user.Email_verified,
+		user.Totp_enabled, user.Totp_secret, user.Totp_last_step, recoveryCodes, user.Deactivated_at,
+		user.Token, user.Refresh_token, user.Created_at, user.Updated_at, user.Tenant_id)
+	return err
+}
+
+func (r *userRepository) Update(ctx context.Context, user models.User) error {
+	recoveryCodes, err := jsonValue(user.Recovery_codes)
+	if err != nil {
+		return err
+	}
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE users SET first_name = $2, last_name = $3, password = $4, email = $5, avatar = $6, phone = $7, role = $8,
+			email_verified = $9, totp_enabled = $10, totp_secret = $11, totp_last_step = $12, recovery_codes = $13,
+			deactivated_at = $14, token = $15, refresh_token = $16, created_at = $17, updated_at = $18,
+			tenant_id = $19 WHERE user_id = $1`,
+		user.User_id, user.First_name, user.Last_name, user.Password, user.Email, user.Avatar, user.Phone, user.Role, user.Email_verified,
+		user.Totp_enabled, user.Totp_secret, user.Totp_last_step, recoveryCodes, user.Deactivated_at,
+		user.Token, user.Refresh_token, user.Created_at, user.Updated_at, user.Tenant_id))
+}
+
+func (r *userRepository) UpdateTokens(ctx context.Context, userId string, token string, refreshToken string) error {
+	updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	return updated(r.db.ExecContext(ctx,
+		`UPDATE users SET token = $2, refresh_token = $3, updated_at = $4 WHERE user_id = $1`,
+		userId, token, refreshToken, updated_at))
+}
+
+func (r *userRepository) UseTOTPStep(ctx context.Context, userId string, step int64, at time.Time) error {
+	err := updated(r.db.ExecContext(ctx,
+		`UPDATE users SET totp_last_step = $2, updated_at = $3 WHERE user_id = $1 AND totp_last_step < $2`, userId, step, at))
+	if err == repository.ErrNotFound {
+		var count int64
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE user_id = $1`, userId).Scan(&count); err != nil {
+			return err
+		}
+		if count == 0 {
+			return repository.ErrNotFound
+		}
+		return repository.ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"strings"
+)
+
+type waitlistRepository struct {
+	db *sql.DB
+}
+
+const waitlistColumns = `entry_id, name, party_size, phone, notes, status, quoted_wait_minutes, notified_at, seated_at, table_id, order_id, created_at, updated_at, tenant_id`
+
+func scanWaitlistEntry(row scanner) (models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := row.Scan(&entry.Entry_id, &entry.Name, &entry.Party_size, &entry.Phone, &entry.Notes, &entry.Status,
+		&entry.Quoted_wait_minutes, &entry.Notified_at, &entry.Seated_at, &entry.Table_id, &entry.Order_id,
+		&entry.Created_at, &entry.Updated_at, &entry.Tenant_id)
+	entry.ID = objectID(entry.Entry_id)
+	return entry, err
+}
+
+func (r *waitlistRepository) List(ctx context.Context, statuses []string) ([]models.WaitlistEntry, error) {
+	if len(statuses) == 0 {
+		return []models.WaitlistEntry{}, nil
+	}
+	args := []any{repository.TenantID(ctx)}
+	placeholders := []string{}
+	for _, status := range statuses {
+		args = append(args, status)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+waitlistColumns+` FROM waitlist_entries
+		WHERE tenant_id = $1 AND status IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY created_at, entry_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.WaitlistEntry{}
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *waitlistRepository) FindByID(ctx context.Context, entryId string) (models.WaitlistEntry, error) {
+	entry, err := scanWaitlistEntry(r.db.QueryRowContext(ctx,
+		`SELECT `+waitlistColumns+` FROM waitlist_entries WHERE entry_id = $1 AND tenant_id = $2`, entryId, repository.TenantID(ctx)))
+	return entry, notFound(err)
+}
+
+func (r *waitlistRepository) Create(ctx context.Context, entry models.WaitlistEntry) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO waitlist_entries (`+waitlistColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		entry.Entry_id, entry.Name, entry.Party_size, entry.Phone, entry.Notes, entry.Status, entry.Quoted_wait_minutes,
+		entry.Notified_at, entry.Seated_at, entry.Table_id, entry.Order_id, entry.Created_at, entry.Updated_at, repository.TenantID(ctx))
+	return err
+}
+
+func (r *waitlistRepository) Update(ctx context.Context, entry models.WaitlistEntry, from string) error {
+	err := updated(r.db.ExecContext(ctx,
+		`UPDATE waitlist_entries SET name = $3, party_size = $4, phone = $5, notes = $6, status = $7, quoted_wait_minutes = $8,
+		notified_at = $9, seated_at = $10, table_id = $11, order_id = $12, updated_at = $13
+		WHERE entry_id = $1 AND status = $2 AND tenant_id = $14`,
+		entry.Entry_id, from, entry.Name, entry.Party_size, entry.Phone, entry.Notes, entry.Status, entry.Quoted_wait_minutes,
+		entry.Notified_at, entry.Seated_at, entry.Table_id, entry.Order_id, entry.Updated_at, repository.TenantID(ctx)))
+	if err == repository.ErrNotFound {
+		if _, err := r.FindByID(ctx, entry.Entry_id); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return err
+}
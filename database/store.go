@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collection names. Orders have always been stored in "food"; the name is
+// kept so existing deployments keep their data.
+const (
+	foodCollectionName          = "food_collection"
+	menuCollectionName          = "menu_collection"
+	orderCollectionName         = "food"
+	orderItemCollectionName     = "orderItem"
+	tableCollectionName         = "table"
+	userCollectionName          = "user"
+	noteCollectionName          = "note"
+	invoiceCollectionName       = "invoice"
+	ticketCollectionName        = "ticket"
+	refreshTokenCollectionName  = "refreshToken"
+	passwordResetCollectionName = "passwordReset"
+	loginThrottleCollectionName = "loginThrottle"
+	auditEventCollectionName    = "auditEvent"
+	apiKeyCollectionName        = "apiKey"
+	tenantCollectionName        = "tenant"
+	reservationCollectionName   = "reservation"
+	waitlistCollectionName      = "waitlist"
+	paymentCollectionName       = "payment"
+)
+
+// NewStore returns the MongoDB backed repositories.
+func NewStore(client *mongo.Client) *repository.Store {
+	return newStore(client.Database(databaseName))
+}
+
+// NewDatabaseStore returns repositories backed by another database of
+// client, such as the one of a tenant. The database is migrated first.
+func NewDatabaseStore(client *mongo.Client, name string) (*repository.Store, error) {
+	db := client.Database(name)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := Migrate(ctx, db); err != nil {
+		return nil, err
+	}
+	return newStore(db), nil
+}
+
+func newStore(db *mongo.Database) *repository.Store {
+	orders := &orderRepository{
+		collection: db.Collection(orderCollectionName),
+		items:      db.Collection(orderItemCollectionName),
+		tickets:    db.Collection(ticketCollectionName),
+		tables:     db.Collection(tableCollectionName),
+	}
+	return &repository.Store{
+		Foods:          &foodRepository{collection: db.Collection(foodCollectionName)},
+		Menus:          &menuRepository{collection: db.Collection(menuCollectionName)},
+		Orders:         orders,
+		OrderItems:     &orderItemRepository{collection: db.Collection(orderItemCollectionName), orders: orders},
+		Tables:         &tableRepository{collection: db.Collection(tableCollectionName)},
+		Users:          &userRepository{collection: db.Collection(userCollectionName)},
+		Notes:          &noteRepository{collection: db.Collection(noteCollectionName)},
+		Invoices:       &invoiceRepository{collection: db.Collection(invoiceCollectionName), payments: db.Collection(paymentCollectionName), orders: orders},
+		Tickets:        &ticketRepository{collection: db.Collection(ticketCollectionName)},
+		RefreshTokens:  &refreshTokenRepository{collection: db.Collection(refreshTokenCollectionName)},
+		PasswordResets: &passwordResetRepository{collection: db.Collection(passwordResetCollectionName)},
+		LoginThrottles: &loginThrottleRepository{collection: db.Collection(loginThrottleCollectionName)},
+		AuditEvents:    &auditEventRepository{collection: db.Collection(auditEventCollectionName)},
+		APIKeys:        &apiKeyRepository{collection: db.Collection(apiKeyCollectionName)},
+		Tenants:        &tenantRepository{collection: db.Collection(tenantCollectionName)},
+		Reservations:   &reservationRepository{collection: db.Collection(reservationCollectionName), tables: db.Collection(tableCollectionName)},
+		Waitlist:       &waitlistRepository{collection: db.Collection(waitlistCollectionName)},
+		Payments:       &paymentRepository{collection: db.Collection(paymentCollectionName), invoices: db.Collection(invoiceCollectionName), orders: orders},
+	}
+}
+
+// transaction runs fn in a transaction on db, which needs MongoDB to run as
+// a replica set.
+func transaction(ctx context.Context, db *mongo.Database, fn func(sc mongo.SessionContext) error) error {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// owned limits filter to the records of the tenant ctx acts for.
+func owned(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = repository.TenantID(ctx)
+	return filter
+}
+
+func notFound(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return repository.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type tableRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *tableRepository) List(ctx context.Context) ([]models.Table, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	tables := []models.Table{}
+	if err := cursor.All(ctx, &tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+func (r *tableRepository) FindByID(ctx context.Context, tableId string) (models.Table, error) {
+	var table models.Table
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"table_id": tableId})).Decode(&table)
+	return table, notFound(err)
+}
+
+func (r *tableRepository) Create(ctx context.Context, table models.Table) error {
+	table.Tenant_id = repository.TenantID(ctx)
+	_, err := r.collection.InsertOne(ctx, table)
+	return err
+}
+
+func (r *tableRepository) Update(ctx context.Context, table models.Table) error {
+	result, err := r.collection.UpdateOne(ctx, owned(ctx, bson.M{"table_id": table.Table_id}),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "number_of_quests", Value: table.Number_of_quests},
+			{Key: "table_number", Value: table.Table_number},
+			{Key: "section", Value: table.Section},
+			{Key: "x", Value: table.X},
+			{Key: "y", Value: table.Y},
+			{Key: "shape", Value: table.Shape},
+			{Key: "server_id", Value: table.Server_id},
+			{Key: "created_at", Value: table.Created_at},
+			{Key: "updated_at", Value: table.Updated_at},
+		}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *tableRepository) UpdateStatus(ctx context.Context, tableId string, status string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, owned(ctx, bson.M{"table_id": tableId}),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: status},
+			{Key: "updated_at", Value: at},
+		}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
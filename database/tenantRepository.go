@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type tenantRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]models.Tenant, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	tenants := []models.Tenant{}
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func (r *tenantRepository) FindByID(ctx context.Context, tenantId string) (models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.collection.FindOne(ctx, bson.M{"tenant_id": tenantId}).Decode(&tenant)
+	return tenant, notFound(err)
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant models.Tenant) error {
+	_, err := r.collection.InsertOne(ctx, tenant)
+	return err
+}
+
+func (r *tenantRepository) Update(ctx context.Context, tenant models.Tenant) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"tenant_id": tenant.Tenant_id}, tenant)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ticketRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *ticketRepository) List(ctx context.Context, station string, statuses []string) ([]models.Ticket, error) {
+	filter := owned(ctx, bson.M{"status": bson.M{"$in": statuses}})
+	if station != "" {
+		filter["station"] = station
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	tickets := []models.Ticket{}
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (r *ticketRepository) FindByID(ctx context.Context, ticketId string) (models.Ticket, error) {
+	var ticket models.Ticket
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"ticket_id": ticketId})).Decode(&ticket)
+	return ticket, notFound(err)
+}
+
+func (r *ticketRepository) ListByOrder(ctx context.Context, orderId string) ([]models.Ticket, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{"order_id": orderId}), options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	tickets := []models.Ticket{}
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (r *ticketRepository) CreateMany(ctx context.Context, tickets []models.Ticket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+	documents := make([]any, 0, len(tickets))
+	for _, ticket := range tickets {
+		ticket.Tenant_id = repository.TenantID(ctx)
+		documents = append(documents, ticket)
+	}
+	_, err := r.collection.InsertMany(ctx, documents)
+	return err
+}
+
+func (r *ticketRepository) UpdateStatus(ctx context.Context, ticketId string, from string, to string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		owned(ctx, bson.M{"ticket_id": ticketId, "status": from}),
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: to},
+			{Key: "updated_at", Value: at},
+		}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, ticketId); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return nil
+}
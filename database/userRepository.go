@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *userRepository) List(ctx context.Context, page repository.Page) ([]models.User, error) {
+	opts := options.Find().
+		SetSkip(page.Skip).
+		SetProjection(bson.M{"password": 0})
+	if page.Limit > 0 {
+		opts.SetLimit(page.Limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, userId string) (models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&user)
+	return user, notFound(err)
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	return user, notFound(err)
+}
+
+func (r *userRepository) CountByEmail(ctx context.Context, email string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"email": email})
+}
+
+func (r *userRepository) CountByPhone(ctx context.Context, phone string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"phone": phone})
+}
+
+func (r *userRepository) Create(ctx context.Context, user models.User) error {
+	_, err := r.collection.InsertOne(ctx, user)
+	return err
+}
+
+func (r *userRepository) Update(ctx context.Context, user models.User) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"user_id": user.User_id}, user)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateTokens(ctx context.Context, userId string, token string, refreshToken string) error {
+	var updateObj bson.D
+
+	updateObj = append(updateObj, bson.E{Key: "token", Value: token})
+	updateObj = append(updateObj, bson.E{Key: "refresh_token", Value: refreshToken})
+	updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	updateObj = append(updateObj, bson.E{Key: "updated_at", Value: updated_at})
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.D{{Key: "$set", Value: updateObj}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UseTOTPStep(ctx context.Context, userId string, step int64, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"user_id": userId, "totp_last_step": bson.M{"$lt": step}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "totp_last_step", Value: step}, {Key: "updated_at", Value: at}}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if err := r.collection.FindOne(ctx, bson.M{"user_id": userId}).Err(); err != nil {
+			return notFound(err)
+		}
+		return repository.ErrConflict
+	}
+	return nil
+}
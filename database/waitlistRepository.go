@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type waitlistRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *waitlistRepository) List(ctx context.Context, statuses []string) ([]models.WaitlistEntry, error) {
+	cursor, err := r.collection.Find(ctx, owned(ctx, bson.M{"status": bson.M{"$in": statuses}}),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	entries := []models.WaitlistEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) FindByID(ctx context.Context, entryId string) (models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.collection.FindOne(ctx, owned(ctx, bson.M{"entry_id": entryId})).Decode(&entry)
+	return entry, notFound(err)
+}
+
+func (r *waitlistRepository) Create(ctx context.Context, entry models.WaitlistEntry) error {
+	entry.Tenant_id = repository.TenantID(ctx)
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *waitlistRepository) Update(ctx context.Context, entry models.WaitlistEntry, from string) error {
+	entry.Tenant_id = repository.TenantID(ctx)
+	result, err := r.collection.ReplaceOne(ctx, owned(ctx, bson.M{"entry_id": entry.Entry_id, "status": from}), entry)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, entry.Entry_id); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return nil
+}
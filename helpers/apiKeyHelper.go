@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"restaurant_management/models"
+	"time"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to spot.
+const APIKeyPrefix = "rmk_"
+
+// apiKeyTouchInterval keeps last-used tracking from writing on every request.
+const apiKeyTouchInterval = time.Minute
+
+var (
+	ErrAPIKeyInvalid = errors.New("API key is invalid")
+	ErrAPIKeyRevoked = errors.New("API key has been revoked")
+	ErrAPIKeyExpired = errors.New("API key has expired")
+)
+
+// GenerateAPIKey returns a new secret key with its display prefix and hash.
+func GenerateAPIKey() (key string, prefix string, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	prefix = key[:len(APIKeyPrefix)+6]
+	hash = HashAPIKey(key)
+	return
+}
+
+// HashAPIKey is how API keys are stored and looked up. Keys are long and
+// random, so a fast hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckAPIKey returns why key cannot be used at now, or nil.
+func CheckAPIKey(key models.APIKey, now time.Time) error {
+	if key.Revoked_at != nil {
+		return ErrAPIKeyRevoked
+	}
+	if key.Expires_at != nil && !now.Before(*key.Expires_at) {
+		return ErrAPIKeyExpired
+	}
+	return nil
+}
+
+// APIKeyNeedsTouch reports whether the key's last use is stale enough to
+// record again.
+func APIKeyNeedsTouch(key models.APIKey, now time.Time) bool {
+	return key.Last_used_at == nil || now.Sub(*key.Last_used_at) >= apiKeyTouchInterval
+}
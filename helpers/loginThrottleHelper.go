@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"restaurant_management/models"
+	"strings"
+	"time"
+)
+
+// Login throttling limits. Failures older than LoginFailureWindow are
+// forgotten; reaching a Max locks the key out for LoginLockout.
+const (
+	MaxAccountLoginFailures = 5
+	MaxIPLoginFailures      = 20
+	LoginFailureWindow      = 15 * time.Minute
+	LoginLockout            = 15 * time.Minute
+	maxLoginDelay           = 30 * time.Second
+)
+
+func AccountThrottleKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func IPThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// LoginDelay is how long a key must wait after its latest failure before
+// trying again: nothing for the first two failures, then a second, doubling
+// up to 30 seconds.
+func LoginDelay(failures int) time.Duration {
+	if failures < 3 {
+		return 0
+	}
+	delay := time.Second << min(failures-3, 5)
+	return min(delay, maxLoginDelay)
+}
+
+// LoginRetryAfter is how long the key has to wait at now before its next
+// attempt, zero if it may try right away. Only delayed keys wait out
+// LoginDelay; the others just honour lockouts, so staff sharing an address
+// do not slow each other down.
+func LoginRetryAfter(throttle models.LoginThrottle, now time.Time, delayed bool) time.Duration {
+	if throttle.Locked_until != nil && now.Before(*throttle.Locked_until) {
+		return throttle.Locked_until.Sub(now)
+	}
+	if !delayed || now.Sub(throttle.Last_failure_at) > LoginFailureWindow {
+		return 0
+	}
+	if next := throttle.Last_failure_at.Add(LoginDelay(throttle.Failures)); now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+// LoginFailuresSince is the cut-off passed to RecordFailure: failures before
+// the window or before the key's last lockout ended no longer count.
+func LoginFailuresSince(throttle models.LoginThrottle, now time.Time) time.Time {
+	since := now.Add(-LoginFailureWindow)
+	if throttle.Locked_until != nil && throttle.Locked_until.After(since) && !now.Before(*throttle.Locked_until) {
+		since = *throttle.Locked_until
+	}
+	return since
+}
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"restaurant_management/models"
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	want := []time.Duration{0, 0, 0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second}
+	for failures, delay := range want {
+		if got := LoginDelay(failures); got != delay {
+			t.Errorf("LoginDelay(%d) = %v, want %v", failures, got, delay)
+		}
+	}
+}
+
+func TestLoginRetryAfter(t *testing.T) {
+	now := time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC)
+	locked := now.Add(10 * time.Minute)
+	tests := []struct {
+		name     string
+		throttle models.LoginThrottle
+		delayed  bool
+		want     time.Duration
+	}{
+		{"locked", models.LoginThrottle{Failures: 5, Last_failure_at: now, Locked_until: &locked}, false, 10 * time.Minute},
+		{"delayed", models.LoginThrottle{Failures: 4, Last_failure_at: now.Add(-time.Second)}, true, time.Second},
+		{"not delayed", models.LoginThrottle{Failures: 4, Last_failure_at: now.Add(-time.Second)}, false, 0},
+		{"delay over", models.LoginThrottle{Failures: 4, Last_failure_at: now.Add(-3 * time.Second)}, true, 0},
+		{"outside the window", models.LoginThrottle{Failures: 9, Last_failure_at: now.Add(-LoginFailureWindow - time.Second)}, true, 0},
+	}
+	for _, tt := range tests {
+		if got := LoginRetryAfter(tt.throttle, now, tt.delayed); got != tt.want {
+			t.Errorf("%s: LoginRetryAfter = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoginFailuresSince(t *testing.T) {
+	now := time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC)
+	window := now.Add(-LoginFailureWindow)
+	ended, running := now.Add(-time.Minute), now.Add(time.Minute)
+	tests := []struct {
+		name     string
+		throttle models.LoginThrottle
+		want     time.Time
+	}{
+		{"never locked", models.LoginThrottle{}, window},
+		{"lockout ended in the window", models.LoginThrottle{Locked_until: &ended}, ended},
+		{"lockout still running", models.LoginThrottle{Locked_until: &running}, window},
+	}
+	for _, tt := range tests {
+		if got := LoginFailuresSince(tt.throttle, now); !got.Equal(tt.want) {
+			t.Errorf("%s: LoginFailuresSince = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
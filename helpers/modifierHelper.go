@@ -0,0 +1,192 @@
+package helpers
+
+import (
+	"fmt"
+	"restaurant_management/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PrepareModifierGroups checks the selection limits of groups and gives every
+// group and modifier without an id one. Groups and modifiers named as in
+// previous, the groups the food had before, keep the id they had there, so
+// order items that chose them still find them; the others get a new one.
+func PrepareModifierGroups(groups []models.ModifierGroup, previous []models.ModifierGroup) error {
+	for i := range groups {
+		group := &groups[i]
+		if group.Max_selections > 0 && group.Min_selections > group.Max_selections {
+			return fmt.Errorf("modifier group %q allows at most %d selections but needs %d", *group.Name, group.Max_selections, group.Min_selections)
+		}
+		var before *models.ModifierGroup
+		for j := range previous {
+			if previous[j].Modifier_group_id == group.Modifier_group_id || group.Modifier_group_id == "" && sameName(previous[j].Name, group.Name) {
+				before = &previous[j]
+				break
+			}
+		}
+		if group.Modifier_group_id == "" && before != nil {
+			group.Modifier_group_id = before.Modifier_group_id
+		}
+		if group.Modifier_group_id == "" {
+			group.Modifier_group_id = primitive.NewObjectID().Hex()
+		}
+		for j := range group.Modifiers {
+			modifier := &group.Modifiers[j]
+			if modifier.Modifier_id == "" && before != nil {
+				for _, old := range before.Modifiers {
+					if sameName(old.Name, modifier.Name) {
+						modifier.Modifier_id = old.Modifier_id
+						break
+					}
+				}
+			}
+			if modifier.Modifier_id == "" {
+				modifier.Modifier_id = primitive.NewObjectID().Hex()
+			}
+		}
+	}
+	return nil
+}
+
+func sameName(a *string, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// PriceFoodIn puts the price of food, and the price deltas of its sizes and
+// modifiers, in currency, the one its tenant bills in. Amounts without a
+// currency are taken to be in it; amounts in another currency, and a price
+// or size that would sell for less than zero, are refused.
+func PriceFoodIn(food *models.Food, currency string) error {
+	if food.Price != nil {
+		price, err := food.Price.In(currency)
+		if err != nil {
+			return err
+		}
+		food.Price = &price
+	}
+	for i := range food.Sizes {
+		size := &food.Sizes[i]
+		delta, err := size.Price_delta.In(currency)
+		if err != nil {
+			return fmt.Errorf("size %s: %w", size.Size, err)
+		}
+		size.Price_delta = delta
+	}
+	for i := range food.Modifier_groups {
+		for j := range food.Modifier_groups[i].Modifiers {
+			modifier := &food.Modifier_groups[i].Modifiers[j]
+			delta, err := modifier.Price_delta.In(currency)
+			if err != nil {
+				return fmt.Errorf("modifier %q: %w", *modifier.Name, err)
+			}
+			modifier.Price_delta = delta
+		}
+	}
+
+	sizes := []*string{nil}
+	for i := range food.Sizes {
+		sizes = append(sizes, &food.Sizes[i].Size)
+	}
+	for _, size := range sizes {
+		price, err := food.UnitPrice(size)
+		if err != nil {
+			return err
+		}
+		if price != nil && price.Amount < 0 {
+			if size == nil {
+				return fmt.Errorf("price %s is negative", price)
+			}
+			return fmt.Errorf("size %s would sell for %s", *size, price)
+		}
+	}
+	return nil
+}
+
+// CheckSize checks that food is sold in size. A food that lists sizes is
+// only sold in those; one without sizes is sold in any size at its base
+// price. No size at all is always the base price.
+func CheckSize(food models.Food, size *string) error {
+	if size == nil || len(food.Sizes) == 0 {
+		return nil
+	}
+	for _, foodSize := range food.Sizes {
+		if foodSize.Size == *size {
+			return nil
+		}
+	}
+	return fmt.Errorf("size %s is not offered with this food", *size)
+}
+
+// SelectModifiers checks selections against the modifier groups of food and
+// returns them with the name and price delta of each modifier filled in.
+func SelectModifiers(food models.Food, selections []models.OrderItemModifier) ([]models.OrderItemModifier, error) {
+	selected := []models.OrderItemModifier{}
+	counts := map[string]int{}
+	seen := map[string]bool{}
+	for _, selection := range selections {
+		group, modifier, ok := findModifier(food, selection)
+		if !ok {
+			return nil, fmt.Errorf("modifier %q of group %q is not offered with this food", selection.Modifier_id, selection.Modifier_group_id)
+		}
+		if seen[modifier.Modifier_id] {
+			return nil, fmt.Errorf("modifier %q is selected more than once", *modifier.Name)
+		}
+		seen[modifier.Modifier_id] = true
+		counts[group.Modifier_group_id]++
+		selected = append(selected, models.OrderItemModifier{
+			Modifier_group_id: group.Modifier_group_id,
+			Modifier_id:       modifier.Modifier_id,
+			Name:              *modifier.Name,
+			Price_delta:       modifier.Price_delta,
+		})
+	}
+
+	for _, group := range food.Modifier_groups {
+		minimum := group.Min_selections
+		if group.Required && minimum < 1 {
+			minimum = 1
+		}
+		count := counts[group.Modifier_group_id]
+		if count < minimum {
+			return nil, fmt.Errorf("modifier group %q needs at least %d selections", *group.Name, minimum)
+		}
+		if group.Max_selections > 0 && count > group.Max_selections {
+			return nil, fmt.Errorf("modifier group %q allows at most %d selections", *group.Name, group.Max_selections)
+		}
+	}
+	return selected, nil
+}
+
+// SameSelection reports whether a and b choose the same modifiers, in any
+// order.
+func SameSelection(a []models.OrderItemModifier, b []models.OrderItemModifier) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	chosen := map[[2]string]int{}
+	for _, modifier := range a {
+		chosen[[2]string{modifier.Modifier_group_id, modifier.Modifier_id}]++
+	}
+	for _, modifier := range b {
+		key := [2]string{modifier.Modifier_group_id, modifier.Modifier_id}
+		if chosen[key] == 0 {
+			return false
+		}
+		chosen[key]--
+	}
+	return true
+}
+
+func findModifier(food models.Food, selection models.OrderItemModifier) (models.ModifierGroup, models.Modifier, bool) {
+	for _, group := range food.Modifier_groups {
+		if group.Modifier_group_id != selection.Modifier_group_id {
+			continue
+		}
+		for _, modifier := range group.Modifiers {
+			if modifier.Modifier_id == selection.Modifier_id {
+				return group, modifier, true
+			}
+		}
+	}
+	return models.ModifierGroup{}, models.Modifier{}, false
+}
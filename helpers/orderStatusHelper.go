@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"fmt"
+	"restaurant_management/models"
+)
+
+// orderTransitions lists, for every status, the statuses an order may move
+// to next. Closed, cancelled and voided orders are final.
+var orderTransitions = map[string][]string{
+	models.OrderStatusOpen:          {models.OrderStatusSentToKitchen, models.OrderStatusCancelled},
+	models.OrderStatusSentToKitchen: {models.OrderStatusServed, models.OrderStatusVoided},
+	models.OrderStatusServed:        {models.OrderStatusBilled, models.OrderStatusVoided},
+	models.OrderStatusBilled:        {models.OrderStatusPaid, models.OrderStatusVoided},
+	models.OrderStatusPaid:          {models.OrderStatusClosed},
+	models.OrderStatusClosed:        {},
+	models.OrderStatusCancelled:     {},
+	models.OrderStatusVoided:        {},
+	models.OrderStatusMerged:        {},
+}
+
+// BillingRoles are the roles that may set discounts on an order, settle
+// its bill or write it off.
+var BillingRoles = []string{models.RoleCashier, models.RoleManager}
+
+// OrderStatusRoles returns the roles that may move an order to status, or
+// nil when every role that may change order status can. Voiding an order
+// and marking it paid move money, so only billing staff may.
+func OrderStatusRoles(status string) []string {
+	switch status {
+	case models.OrderStatusVoided, models.OrderStatusPaid:
+		return BillingRoles
+	}
+	return nil
+}
+
+// OrderStatus returns the status of order, treating orders stored before
+// statuses existed as open.
+func OrderStatus(order models.Order) string {
+	if order.Status == "" {
+		return models.OrderStatusOpen
+	}
+	return order.Status
+}
+
+// OrderEditable reports whether order can still change table and have items
+// moved on or off it.
+func OrderEditable(order models.Order) bool {
+	status := OrderStatus(order)
+	for _, editable := range models.EditableOrderStatuses {
+		if editable == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateOrderTransition reports why an order may not move from one status
+// to another, or nil if it may.
+func ValidateOrderTransition(from string, to string) error {
+	if _, ok := orderTransitions[to]; !ok {
+		return fmt.Errorf("unknown order status %q", to)
+	}
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("order cannot move from %q to %q", from, to)
+}
@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"reflect"
+	"restaurant_management/models"
+	"testing"
+)
+
+func TestValidateOrderTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{models.OrderStatusOpen, models.OrderStatusSentToKitchen, true},
+		{models.OrderStatusOpen, models.OrderStatusCancelled, true},
+		{models.OrderStatusOpen, models.OrderStatusServed, false},
+		{models.OrderStatusOpen, models.OrderStatusVoided, false},
+		{models.OrderStatusSentToKitchen, models.OrderStatusServed, true},
+		{models.OrderStatusSentToKitchen, models.OrderStatusVoided, true},
+		{models.OrderStatusSentToKitchen, models.OrderStatusCancelled, false},
+		{models.OrderStatusServed, models.OrderStatusBilled, true},
+		{models.OrderStatusServed, models.OrderStatusVoided, true},
+		{models.OrderStatusServed, models.OrderStatusOpen, false},
+		{models.OrderStatusBilled, models.OrderStatusPaid, true},
+		{models.OrderStatusBilled, models.OrderStatusVoided, true},
+		{models.OrderStatusBilled, models.OrderStatusServed, false},
+		{models.OrderStatusPaid, models.OrderStatusClosed, true},
+		{models.OrderStatusPaid, models.OrderStatusVoided, false},
+		{models.OrderStatusClosed, models.OrderStatusOpen, false},
+		{models.OrderStatusCancelled, models.OrderStatusOpen, false},
+		{models.OrderStatusVoided, models.OrderStatusPaid, false},
+		{models.OrderStatusMerged, models.OrderStatusOpen, false},
+		{models.OrderStatusOpen, models.OrderStatusOpen, false},
+		{models.OrderStatusOpen, "shipped", false},
+	}
+	for _, tt := range tests {
+		err := ValidateOrderTransition(tt.from, tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateOrderTransition(%q, %q) = %v, want allowed %v", tt.from, tt.to, err, tt.ok)
+		}
+	}
+}
+
+func TestOrderStatusRoles(t *testing.T) {
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{models.OrderStatusSentToKitchen, nil},
+		{models.OrderStatusServed, nil},
+		{models.OrderStatusClosed, nil},
+		{models.OrderStatusPaid, BillingRoles},
+		{models.OrderStatusVoided, BillingRoles},
+	}
+	for _, tt := range tests {
+		if got := OrderStatusRoles(tt.status); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("OrderStatusRoles(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestOrderEditable(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{"", true},
+		{models.OrderStatusOpen, true},
+		{models.OrderStatusSentToKitchen, true},
+		{models.OrderStatusServed, true},
+		{models.OrderStatusBilled, false},
+		{models.OrderStatusPaid, false},
+		{models.OrderStatusMerged, false},
+	}
+	for _, tt := range tests {
+		if got := OrderEditable(models.Order{Status: tt.status}); got != tt.want {
+			t.Errorf("OrderEditable(status %q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
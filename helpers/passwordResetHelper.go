@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"restaurant_management/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PasswordResetLifetime is how long a password reset token works.
+const PasswordResetLifetime = time.Hour
+
+// NewPasswordReset creates a reset record for the user and returns it with
+// the token to mail out. Only the token's hash is on the record.
+func NewPasswordReset(userId string) (models.PasswordReset, string, error) {
+	var reset models.PasswordReset
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return reset, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	reset.ID = primitive.NewObjectID()
+	reset.Reset_id = reset.ID.Hex()
+	reset.User_id = userId
+	reset.Token_hash = HashResetToken(token)
+	reset.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	reset.Expires_at = reset.Created_at.Add(PasswordResetLifetime)
+	return reset, token, nil
+}
+
+// HashResetToken is how reset tokens are looked up.
+func HashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,26 @@
+package helpers
+
+import "restaurant_management/models"
+
+// UserRole returns the role of user. Accounts created before roles existed
+// get the least privileged staff role until an admin assigns one.
+func UserRole(user models.User) string {
+	if user.Role == "" {
+		return models.RoleWaiter
+	}
+	return user.Role
+}
+
+// RoleAllowed reports whether role may do something open to allowed. Admins
+// are allowed everything.
+func RoleAllowed(role string, allowed ...string) bool {
+	if role == models.RoleAdmin {
+		return true
+	}
+	for _, candidate := range allowed {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
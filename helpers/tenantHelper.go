@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"restaurant_management/models"
+	"restaurant_management/money"
+	"restaurant_management/pricing"
+)
+
+// TenantPrices is prices with the currency and tax rates tenant sets put in
+// place of its own. The cash increment is in the deployment's currency, so
+// a tenant billing in another currency is not rounded to it.
+func TenantPrices(prices pricing.Config, tenant models.Tenant) pricing.Config {
+	if tenant.Currency != nil && *tenant.Currency != prices.Currency {
+		prices.Currency = *tenant.Currency
+		prices.Total_increment = money.Money{}
+	}
+	if tenant.Default_tax_rate != nil {
+		prices.Default_tax_rate = *tenant.Default_tax_rate
+	}
+	if tenant.Tax_rates != nil {
+		prices.Tax_rates = tenant.Tax_rates
+	}
+	return prices
+}
@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"fmt"
+	"restaurant_management/models"
+)
+
+// ticketStatuses is the order a kitchen ticket moves through.
+var ticketStatuses = []string{
+	models.TicketStatusQueued,
+	models.TicketStatusCooking,
+	models.TicketStatusReady,
+	models.TicketStatusServed,
+}
+
+// BumpTicketStatus returns the status a ticket moves to when it is bumped.
+func BumpTicketStatus(status string) (string, error) {
+	for i, current := range ticketStatuses {
+		if current == status && i+1 < len(ticketStatuses) {
+			return ticketStatuses[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("ticket with status %q cannot be bumped", status)
+}
+
+// RecallTicketStatus returns the status a ticket moves back to when it is
+// recalled.
+func RecallTicketStatus(status string) (string, error) {
+	for i, current := range ticketStatuses {
+		if current == status && i > 0 {
+			return ticketStatuses[i-1], nil
+		}
+	}
+	return "", fmt.Errorf("ticket with status %q cannot be recalled", status)
+}
+
+// ValidTicketStatus reports whether status is a known ticket status.
+// Voided tickets can be listed but neither bumped nor recalled.
+func ValidTicketStatus(status string) bool {
+	if status == models.TicketStatusVoided {
+		return true
+	}
+	for _, current := range ticketStatuses {
+		if current == status {
+			return true
+		}
+	}
+	return false
+}
@@ -1,103 +1,212 @@
 package helpers
 
 import (
-	"context"
-	"log"
-	"restaurant_management/database"
+	"errors"
+	"restaurant_management/models"
+	"restaurant_management/signing"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	AccessToken      = "access"
+	RefreshToken     = "refresh"
+	VerifyEmailToken = "verify_email"
+	MFAToken         = "mfa"
+)
+
+// RefreshTokenLifetime is how long a refresh token can be exchanged for a
+// new pair. Access tokens last a day.
+const RefreshTokenLifetime = 7 * 24 * time.Hour
+
+// VerificationTokenLifetime is how long an email verification link works.
+const VerificationTokenLifetime = 48 * time.Hour
+
+// MFATokenLifetime is how long the second login step may take.
+const MFATokenLifetime = 5 * time.Minute
+
 type SignedDetails struct {
 	Email      string
 	First_name string
 	Last_name  string
 	Uid        string
+	Role       string
+	Tenant_id  string
+	Token_type string
+	Mfa        bool
 	jwt.StandardClaims
 }
 
-var userCollection *mongo.Collection = database.OpenCollection(database.Client, "user")
-
-var SECRET_KEY string = "vasanth"
+// SigningKeys signs and verifies every token. main sets it at startup,
+// before anything is served.
+var SigningKeys *signing.KeySet
+
+// NewRefreshToken starts a refresh token record for the user. An empty
+// familyId starts a new family, as on login; rotation passes the family of
+// the token being exchanged.
+func NewRefreshToken(userId string, familyId string) models.RefreshToken {
+	var token models.RefreshToken
+	token.ID = primitive.NewObjectID()
+	token.Token_id = token.ID.Hex()
+	token.Family_id = familyId
+	if token.Family_id == "" {
+		token.Family_id = token.Token_id
+	}
+	token.User_id = userId
+	token.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	token.Expires_at = token.Created_at.Add(RefreshTokenLifetime)
+	return token
+}
 
-func GenerateAllTokens(email string, firstName string, lastName string, uuid string) (signedToken string, signedRefreshToken string, err error) {
+// GenerateAllTokens signs an access token and the refresh token for the
+// refresh record. Both pass on whether the login used TOTP.
+func GenerateAllTokens(email string, firstName string, lastName string, uuid string, role string, tenantId string, refresh models.RefreshToken) (signedToken string, signedRefreshToken string, err error) {
 	claims := &SignedDetails{
 		Email:      email,
 		First_name: firstName,
 		Last_name:  lastName,
 		Uid:        uuid,
+		Role:       role,
+		Tenant_id:  tenantId,
+		Token_type: AccessToken,
+		Mfa:        refresh.Mfa,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(24)).Unix(),
 		},
 	}
 
 	refreshClaims := &SignedDetails{
+		Uid:        uuid,
+		Token_type: RefreshToken,
+		Mfa:        refresh.Mfa,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(24)).Unix(),
+			Id:        refresh.Token_id,
+			ExpiresAt: refresh.Expires_at.Unix(),
 		},
 	}
 
-	signedToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(SECRET_KEY))
+	signedToken, err = SigningKeys.Sign(claims)
 	if err != nil {
-		log.Panic(err)
+		return "", "", err
 	}
-	signedRefreshToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(SECRET_KEY))
+	signedRefreshToken, err = SigningKeys.Sign(refreshClaims)
 	if err != nil {
-		log.Panic(err)
+		return "", "", err
 	}
-	return
+	return signedToken, signedRefreshToken, nil
+}
 
+// GenerateVerificationToken signs a token proving the user received mail at
+// their current address.
+func GenerateVerificationToken(user models.User) (string, error) {
+	claims := &SignedDetails{
+		Email:      *user.Email,
+		Uid:        user.User_id,
+		Token_type: VerifyEmailToken,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Local().Add(VerificationTokenLifetime).Unix(),
+		},
+	}
+	return SigningKeys.Sign(claims)
 }
 
-func UpdateAllTokens(signedToken string, signedRefreshToken string, userId string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-	defer cancel()
+// GenerateMFAToken signs the token handed out after a correct password when
+// the user still has to enter a TOTP code.
+func GenerateMFAToken(user models.User) (string, error) {
+	claims := &SignedDetails{
+		Uid:        user.User_id,
+		Token_type: MFAToken,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Local().Add(MFATokenLifetime).Unix(),
+		},
+	}
+	return SigningKeys.Sign(claims)
+}
 
-	var updateObj bson.D
+// Reasons a token is turned down. The Validate functions return one of
+// these.
+var (
+	ErrTokenMissing   = errors.New("no auth token provided")
+	ErrTokenMalformed = errors.New("token is malformed")
+	ErrTokenInvalid   = errors.New("token is invalid")
+	ErrTokenExpired   = errors.New("token has expired")
+)
 
-	updateObj = append(updateObj, bson.E{Key: "token", Value: signedToken})
-	updateObj = append(updateObj, bson.E{Key: "refresh_token", Value: signedRefreshToken})
-	update_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	updateObj = append(updateObj, bson.E{Key: "update_at", Value: update_at})
+func ValidateToken(signedToken string) (*SignedDetails, error) {
+	claims, err := parseToken(signedToken)
+	if err != nil {
+		return nil, err
+	}
 
-	upsert := true
-	filter := bson.M{"user_id": userId}
-	opt := options.UpdateOptions{
-		Upsert: &upsert,
+	// only access tokens are for calling the API; tokens from before
+	// token types existed have none
+	if claims.Token_type != AccessToken && claims.Token_type != "" {
+		return nil, ErrTokenInvalid
 	}
+	return claims, nil
+}
 
-	_, err := userCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}, &opt)
+// ValidateVerificationToken checks the signature, type and expiry of an email
+// verification token.
+func ValidateVerificationToken(signedToken string) (*SignedDetails, error) {
+	claims, err := parseToken(signedToken)
 	if err != nil {
-		log.Panic(err)
+		return nil, err
 	}
+	if claims.Token_type != VerifyEmailToken || claims.Uid == "" {
+		return nil, ErrTokenInvalid
+	}
+	return claims, nil
 }
 
-func ValidateToken(signedToken string) (claims *SignedDetails, msg string) {
-	token, err := jwt.ParseWithClaims(
-		signedToken,
-		&SignedDetails{},
-		func(token *jwt.Token) (any, error) {
-			return []byte(SECRET_KEY), nil
-		},
-	)
+// ValidateMFAToken checks the signature, type and expiry of the token from
+// the first login step.
+func ValidateMFAToken(signedToken string) (*SignedDetails, error) {
+	claims, err := parseToken(signedToken)
 	if err != nil {
-		log.Panic(err)
+		return nil, err
 	}
+	if claims.Token_type != MFAToken || claims.Uid == "" {
+		return nil, ErrTokenInvalid
+	}
+	return claims, nil
+}
 
-	// invalid token
-	claims, ok := token.Claims.(*SignedDetails)
-	if !ok {
-		msg = "Token invalid"
-		return
+// ValidateRefreshToken checks the signature, type and expiry of a refresh
+// token. Whether it was already used or revoked is up to the caller.
+func ValidateRefreshToken(signedToken string) (*SignedDetails, error) {
+	claims, err := parseToken(signedToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Token_type != RefreshToken || claims.Id == "" || claims.Uid == "" {
+		return nil, ErrTokenInvalid
 	}
+	return claims, nil
+}
 
-	// expired token
-	if claims.ExpiresAt < time.Now().Local().Unix() {
-		msg = "Token expired"
+// parseToken verifies the signature and expiry of a token and sorts any
+// failure into one of the ErrToken errors.
+func parseToken(signedToken string) (*SignedDetails, error) {
+	if signedToken == "" {
+		return nil, ErrTokenMissing
+	}
+	claims := &SignedDetails{}
+	_, err := jwt.ParseWithClaims(signedToken, claims, SigningKeys.Keyfunc)
+	if validationErr, ok := err.(*jwt.ValidationError); ok {
+		switch {
+		case validationErr.Errors&jwt.ValidationErrorMalformed != 0:
+			return nil, ErrTokenMalformed
+		case validationErr.Errors&(jwt.ValidationErrorUnverifiable|jwt.ValidationErrorSignatureInvalid) != 0:
+			return nil, ErrTokenInvalid
+		case validationErr.Errors&jwt.ValidationErrorExpired != 0:
+			return nil, ErrTokenExpired
+		}
+	}
+	if err != nil {
+		return nil, ErrTokenInvalid
 	}
-	return
+	return claims, nil
 }
@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"restaurant_management/models"
+	"restaurant_management/signing"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestTokenTypes(t *testing.T) {
+	SigningKeys = signing.Ephemeral()
+	refresh := NewRefreshToken("u1", "")
+	access, refreshToken, err := GenerateAllTokens("a@example.com", "Ann", "Lee", "u1", models.RoleWaiter, "", refresh)
+	if err != nil {
+		t.Fatalf("GenerateAllTokens: %v", err)
+	}
+
+	claims, err := ValidateToken(access)
+	if err != nil || claims.Uid != "u1" || claims.Role != models.RoleWaiter || claims.Token_type != AccessToken {
+		t.Errorf("ValidateToken(access) = %+v, %v", claims, err)
+	}
+	if _, err := ValidateToken(refreshToken); err != ErrTokenInvalid {
+		t.Errorf("ValidateToken(refresh) = %v, want ErrTokenInvalid", err)
+	}
+	claims, err = ValidateRefreshToken(refreshToken)
+	if err != nil || claims.Id != refresh.Token_id {
+		t.Errorf("ValidateRefreshToken(refresh) = %+v, %v; want id %s", claims, err, refresh.Token_id)
+	}
+	if _, err := ValidateRefreshToken(access); err != ErrTokenInvalid {
+		t.Errorf("ValidateRefreshToken(access) = %v, want ErrTokenInvalid", err)
+	}
+	if _, err := ValidateMFAToken(access); err != ErrTokenInvalid {
+		t.Errorf("ValidateMFAToken(access) = %v, want ErrTokenInvalid", err)
+	}
+}
+
+func TestTokenFailures(t *testing.T) {
+	SigningKeys = signing.Ephemeral()
+	expired, err := SigningKeys.Sign(&SignedDetails{
+		Uid:            "u1",
+		Token_type:     AccessToken,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := signing.Ephemeral().Sign(&SignedDetails{Uid: "u1", Token_type: AccessToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"missing", "", ErrTokenMissing},
+		{"malformed", "not.a.jwt", ErrTokenMalformed},
+		{"signed by another key set", foreign, ErrTokenInvalid},
+		{"expired", expired, ErrTokenExpired},
+	}
+	for _, tt := range tests {
+		if _, err := ValidateToken(tt.token); err != tt.want {
+			t.Errorf("%s: ValidateToken = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TOTP parameters (RFC 6238 defaults, which every authenticator app
+// supports).
+const (
+	TOTPIssuer         = "Restaurant Management"
+	totpPeriod         = 30
+	totpDigits         = 6
+	totpSkew           = 1
+	RecoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+// TOTPRequiredRoles lists the roles that must pass TOTP at login before
+// Authorize lets them through. main fills it from TOTP_REQUIRED_ROLES.
+var TOTPRequiredRoles []string
+
+func RoleRequiresTOTP(role string) bool {
+	for _, required := range TOTPRequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateTOTPSecret returns a random base32 secret for a new enrollment.
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// TOTPURI is the otpauth:// URI authenticator apps scan as a QR code.
+func TOTPURI(secret string, account string) string {
+	label := url.PathEscape(TOTPIssuer + ":" + account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// ValidateTOTP checks code against secret at now, allowing one step of clock
+// skew either way. It returns the time step the code belongs to, which must
+// be later than lastStep so a code cannot be replayed.
+func ValidateTOTP(secret string, code string, now time.Time, lastStep int64) (int64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step > lastStep && hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// GenerateRecoveryCodes returns RecoveryCodeCount fresh codes and their
+// bcrypt hashes. Only the hashes are stored; the codes are shown once.
+func GenerateRecoveryCodes() (codes []string, hashes []string, err error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz023456789" // 32 symbols, no i, l, o or 1
+	for range RecoveryCodeCount {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		for i := range raw {
+			raw[i] = alphabet[raw[i]%32]
+		}
+		code := string(raw[:5]) + "-" + string(raw[5:])
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, string(hash))
+	}
+	return codes, hashes, nil
+}
+
+// UseRecoveryCode looks code up among hashes and returns the hashes left
+// once it is spent.
+func UseRecoveryCode(hashes []string, code string) ([]string, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for i, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+			return append(hashes[:i:i], hashes[i+1:]...), true
+		}
+	}
+	return hashes, false
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 test key "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTP(t *testing.T) {
+	// the RFC's SHA-1 vector for T = 59s is 94287082; six digits keep 287082
+	at := time.Unix(59, 0)
+	if step, ok := ValidateTOTP(rfcSecret, "287082", at, 0); !ok || step != 1 {
+		t.Errorf("ValidateTOTP at 59s = %d, %v; want step 1", step, ok)
+	}
+	if _, ok := ValidateTOTP(strings.ToLower(rfcSecret), "287082", at, 0); !ok {
+		t.Error("a lower-case secret is refused")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, "287082", at, 1); ok {
+		t.Error("a code of the last used step is accepted again")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, "287082", at.Add(totpPeriod*time.Second), 0); !ok {
+		t.Error("a code one step old is refused")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, "287082", at.Add(2*totpPeriod*time.Second), 0); ok {
+		t.Error("a code two steps old is accepted")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, "287083", at, 0); ok {
+		t.Error("a wrong code is accepted")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("%d codes and %d hashes, want %d", len(codes), len(hashes), RecoveryCodeCount)
+	}
+
+	left, ok := UseRecoveryCode(hashes, " "+strings.ToUpper(codes[3])+" ")
+	if !ok || len(left) != RecoveryCodeCount-1 {
+		t.Fatalf("UseRecoveryCode = %d hashes left, %v; want %d, true", len(left), ok, RecoveryCodeCount-1)
+	}
+	if _, ok := UseRecoveryCode(left, codes[3]); ok {
+		t.Error("a spent recovery code works again")
+	}
+	if _, ok := UseRecoveryCode(left, codes[4]); !ok {
+		t.Error("spending one code used up another")
+	}
+	if len(hashes) != RecoveryCodeCount || hashes[3] == "" {
+		t.Error("UseRecoveryCode changed the hashes it was given")
+	}
+}
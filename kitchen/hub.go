@@ -0,0 +1,70 @@
+package kitchen
+
+import (
+	"restaurant_management/models"
+	"sync"
+)
+
+// feedBuffer is how many updates a subscriber may fall behind before it is
+// dropped.
+const feedBuffer = 64
+
+// Hub fans ticket updates out to live kitchen feeds. It only reaches
+// subscribers of this process; clients catch up on anything else by listing
+// tickets when they (re)connect.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan models.Ticket]subscription
+}
+
+// subscription is which tickets a feed receives.
+type subscription struct {
+	tenantId string
+	station  string
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: map[chan models.Ticket]subscription{}}
+}
+
+// Subscribe returns a feed of the tickets of tenantId at station, or at every
+// station when it is empty, and a func that ends the subscription. The feed
+// is closed if the subscriber falls too far behind.
+func (h *Hub) Subscribe(tenantId string, station string) (<-chan models.Ticket, func()) {
+	feed := make(chan models.Ticket, feedBuffer)
+	h.mu.Lock()
+	h.subscribers[feed] = subscription{tenantId: tenantId, station: station}
+	h.mu.Unlock()
+
+	return feed, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[feed]; ok {
+			delete(h.subscribers, feed)
+			close(feed)
+		}
+	}
+}
+
+// Publish sends ticket to every subscriber of its tenant and station
+// without blocking.
+func (h *Hub) Publish(ticket models.Ticket) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for feed, sub := range h.subscribers {
+		if sub.tenantId != ticket.Tenant_id {
+			continue
+		}
+		if sub.station != "" && sub.station != ticket.Station {
+			continue
+		}
+		select {
+		case feed <- ticket:
+		default:
+			// a stalled client should reconnect and resync rather than
+			// hold up the kitchen
+			delete(h.subscribers, feed)
+			close(feed)
+		}
+	}
+}
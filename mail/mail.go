@@ -0,0 +1,57 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages. Handlers only see this interface, so a real mail
+// service can be plugged in without touching them.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewSender returns a FileSender writing to dir, or a LogSender when dir is
+// empty.
+func NewSender(dir string) Sender {
+	if dir == "" {
+		return LogSender{}
+	}
+	return FileSender{Dir: dir}
+}
+
+// LogSender writes messages to the standard logger instead of sending them.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, message Message) error {
+	log.Printf("mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// FileSender writes every message to its own .eml file in Dir, for local
+// development and tests.
+type FileSender struct {
+	Dir string
+}
+
+func (s FileSender) Send(ctx context.Context, message Message) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), strings.NewReplacer("@", "_at_", "/", "_").Replace(message.To))
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n", message.To, message.Subject, now.Format(time.RFC1123Z), message.Body)
+	return os.WriteFile(filepath.Join(s.Dir, name), []byte(content), 0o644)
+}
@@ -1,41 +1,148 @@
 package main
 
 import (
-	"net/http"
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"os"
-	"restaurant_management/middleware"
+	"restaurant_management/booking"
+	"restaurant_management/database"
+	"restaurant_management/database/memory"
+	"restaurant_management/database/sqldb"
+	"restaurant_management/helpers"
+	"restaurant_management/mail"
+	"restaurant_management/models"
+	"restaurant_management/notify"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
 	"restaurant_management/routes"
-
-	"github.com/gin-gonic/gin"
+	"restaurant_management/signing"
+	"strings"
+	"time"
 )
 
 func main() {
-	router := gin.New()
+	if len(os.Args) == 3 && os.Args[1] == "make-admin" {
+		if err := makeAdmin(openStore(), os.Args[2]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	prices, err := pricing.LoadConfig(os.Getenv("PRICING_CONFIG"))
+	if err != nil {
+		panic(err)
+	}
+	bookings, err := booking.LoadConfig(os.Getenv("BOOKING_CONFIG"))
+	if err != nil {
+		panic(err)
+	}
+	helpers.SigningKeys, err = signingKeys()
+	if err != nil {
+		panic(err)
+	}
+	if roles := os.Getenv("TOTP_REQUIRED_ROLES"); roles != "" {
+		helpers.TOTPRequiredRoles = strings.Split(roles, ",")
+	}
+	router := routes.NewRouter(openStore(), prices, bookings, mail.NewSender(os.Getenv("MAIL_DIR")), notify.NewNotifier(os.Getenv("NOTIFY_WEBHOOK_URL")))
 
-	// default route
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"msg": "Server running successfully"})
-	})
+	PORT := os.Getenv("PORT")
+	router.Run(PORT)
+}
+
+// signingKeys loads the token signing keys from JWT_KEYS_CONFIG. A key made
+// up at startup would log everyone out on every restart, and instances
+// would reject each other's tokens, so one is only used with
+// JWT_EPHEMERAL_KEYS=true or the memory backend, which forgets its accounts
+// on restart anyway.
+func signingKeys() (*signing.KeySet, error) {
+	if path := os.Getenv("JWT_KEYS_CONFIG"); path != "" {
+		return signing.Load(path)
+	}
+	if os.Getenv("JWT_EPHEMERAL_KEYS") != "true" && os.Getenv("STORAGE_BACKEND") != "memory" {
+		return nil, errors.New("JWT_KEYS_CONFIG is not set; set it to the token signing key config, or JWT_EPHEMERAL_KEYS=true to sign with a key that only lasts until the next restart")
+	}
+	log.Println("WARNING: JWT_KEYS_CONFIG is not set; tokens are signed with a key generated for this process and stop working when it restarts")
+	return signing.Ephemeral(), nil
+}
 
-	router.Use(gin.Logger())
-	routes.UserRoutes(router)
-	router.Use(middleware.Authentication())
-
-	routes.FoodRoutes(router)
-	routes.MenuRoutes(router)
-	routes.OrderRoutes(router)
-	routes.OrderItemRoutes(router)
-	routes.TableRoutes(router)
-
-	// Catch-all handler for undefined routes
-	router.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Route not found",
-			"path":    c.Request.URL.Path,
-			"message": "Check your endpoint",
-		})
+// openStore picks the storage backend from STORAGE_BACKEND. MongoDB is the
+// default; "memory" boots without any external service. With
+// TENANT_ISOLATION=database every tenant but the default one keeps its
+// records in a database of its own, named by TENANT_DATABASE with "{tenant}"
+// replaced by the tenant id.
+func openStore() *repository.Store {
+	store, openTenant, pattern := openBackend(os.Getenv("TENANT_DATABASE"))
+	if os.Getenv("TENANT_ISOLATION") != "database" {
+		return store
+	}
+	if !strings.Contains(pattern, "{tenant}") {
+		panic("TENANT_DATABASE must contain {tenant}")
+	}
+	return repository.NewTenantStore(store, func(tenantId string) (*repository.Store, error) {
+		return openTenant(strings.ReplaceAll(pattern, "{tenant}", tenantId))
 	})
+}
 
-	PORT := os.Getenv("PORT")
-	router.Run(PORT)
+// openBackend returns the store of the backend, how to open another database
+// of it by name, and the tenant database pattern with the backend's default
+// filled in.
+func openBackend(pattern string) (*repository.Store, func(name string) (*repository.Store, error), string) {
+	switch os.Getenv("STORAGE_BACKEND") {
+	case "memory":
+		return memory.NewStore(), func(string) (*repository.Store, error) {
+			return memory.NewStore(), nil
+		}, "{tenant}"
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "restaurant.db"
+		}
+		if pattern == "" {
+			pattern = strings.TrimSuffix(path, ".db") + "_{tenant}.db"
+		}
+		return sqldb.NewStore(sqldb.DBInstance(sqldb.SQLite, path)), openSQL(sqldb.SQLite), pattern
+	case "postgres":
+		return sqldb.NewStore(sqldb.DBInstance(sqldb.Postgres, os.Getenv("DATABASE_URL"))), openSQL(sqldb.Postgres), pattern
+	default:
+		client := database.DBInsance()
+		if pattern == "" {
+			pattern = "restaurant_{tenant}"
+		}
+		return database.NewStore(client), func(name string) (*repository.Store, error) {
+			return database.NewDatabaseStore(client, name)
+		}, pattern
+	}
+}
+
+// makeAdmin gives the account registered with email the admin role. Sign-up
+// never makes admins, so this is how a deployment gets its first one.
+func makeAdmin(store *repository.Store, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+	user, err := store.Users.FindByEmail(ctx, email)
+	if err == repository.ErrNotFound {
+		return fmt.Errorf("no account is registered with %s", email)
+	}
+	if err != nil {
+		return err
+	}
+	user.Role = models.RoleAdmin
+	user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	if err := store.Users.Update(ctx, user); err != nil {
+		return err
+	}
+	fmt.Printf("%s is now an admin; the role applies from their next login\n", email)
+	return nil
+}
+
+func openSQL(driverName string) func(string) (*repository.Store, error) {
+	return func(dataSourceName string) (*repository.Store, error) {
+		db, err := sqldb.Open(driverName, dataSourceName)
+		if err != nil {
+			return nil, err
+		}
+		return sqldb.NewStore(db), nil
+	}
 }
@@ -1,32 +1,185 @@
 package middleware
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Authentication() gin.HandlerFunc {
+const realm = "restaurant_management"
+
+// Authentication accepts either a JWT from login or an API key. API keys
+// act with the roles in their scopes, under the uid "api_key:<key_id>".
+// Requests act for the tenant of the token or key.
+func Authentication(store *repository.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clientToken := c.Request.Header.Get("token")
-		if clientToken == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "No auth token provided"})
-			c.Abort()
+		if apiKey := requestAPIKey(c); apiKey != "" {
+			authenticateAPIKey(c, store, apiKey)
 			return
 		}
-		claims, err := helpers.ValidateToken(clientToken)
-		if err != "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+
+		claims, err := helpers.ValidateToken(requestToken(c))
+		if err != nil {
+			challenge := `Bearer realm="` + realm + `"`
+			if err != helpers.ErrTokenMissing {
+				challenge += `, error="invalid_token", error_description="` + err.Error() + `"`
+			}
+			c.Header("WWW-Authenticate", challenge)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
+		if !accountActive(c, store, claims.Uid) {
+			return
+		}
 
 		c.Set("email", claims.Email)
 		c.Set("first_name", claims.First_name)
 		c.Set("last_name", claims.Last_name)
 		c.Set("uid", claims.Uid)
+		c.Set("role", claims.Role)
+		c.Set("mfa", claims.Mfa)
+		c.Set("home_tenant", claims.Tenant_id)
+		c.Set("tenant", claims.Tenant_id)
 
+		if tenantId := c.Request.Header.Get("X-Tenant-ID"); tenantId != "" {
+			actAsTenant(c, store, tenantId)
+			return
+		}
 		c.Next()
 	}
 }
+
+// accountActive answers 401 and aborts unless the account userId still
+// exists and is not deactivated. Tokens outlive a deactivation, so every
+// request checks.
+func accountActive(c *gin.Context, store *repository.Store, userId string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	user, err := store.Users.FindByID(ctx, userId)
+	if err != nil && err != repository.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user details"})
+		c.Abort()
+		return false
+	}
+	if err == nil && user.Deactivated_at == nil {
+		return true
+	}
+	reason := "account is deactivated"
+	if err != nil {
+		reason = "account no longer exists"
+	}
+	c.Header("WWW-Authenticate", `Bearer realm="`+realm+`", error="invalid_token", error_description="`+reason+`"`)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": reason})
+	c.Abort()
+	return false
+}
+
+// actAsTenant lets a head office admin work on the data of an outlet by
+// sending its id in "X-Tenant-ID".
+func actAsTenant(c *gin.Context, store *repository.Store, tenantId string) {
+	if c.GetString("home_tenant") != repository.DefaultTenant || c.GetString("role") != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only head office admins can act for another tenant"})
+		c.Abort()
+		return
+	}
+
+	if tenantExists(c, store, tenantId) {
+		c.Set("tenant", tenantId)
+		c.Next()
+	}
+}
+
+// PublicTenant lets requests that need no login, such as those of the
+// website, pick the outlet they are about with the "tenant" query
+// parameter. Without it they act for the head office.
+func PublicTenant(store *repository.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantId := c.Query("tenant")
+		if tenantId == "" || tenantExists(c, store, tenantId) {
+			c.Set("tenant", tenantId)
+			c.Next()
+		}
+	}
+}
+
+// tenantExists answers 404 and aborts if tenantId names no tenant.
+func tenantExists(c *gin.Context, store *repository.Store, tenantId string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := store.Tenants.FindByID(ctx, tenantId); err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tenant was not found"})
+		c.Abort()
+		return false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tenant"})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+func authenticateAPIKey(c *gin.Context, store *repository.Store, apiKey string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key, err := store.APIKeys.FindByHash(ctx, helpers.HashAPIKey(apiKey))
+	if err != nil && err != repository.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking API key"})
+		c.Abort()
+		return
+	}
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	if err == nil {
+		err = helpers.CheckAPIKey(key, now)
+	} else {
+		err = helpers.ErrAPIKeyInvalid
+	}
+	if err != nil {
+		c.Header("WWW-Authenticate", `ApiKey realm="`+realm+`"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	if helpers.APIKeyNeedsTouch(key, now) {
+		if err := store.APIKeys.Touch(ctx, key.Key_id, now); err != nil {
+			log.Printf("recording use of API key %s: %v", key.Key_id, err)
+		}
+	}
+	c.Set("uid", "api_key:"+key.Key_id)
+	c.Set("scopes", key.Scopes)
+	c.Set("home_tenant", key.Tenant_id)
+	c.Set("tenant", key.Tenant_id)
+	c.Next()
+}
+
+// requestToken reads the token from "Authorization: Bearer <token>", falling
+// back to the legacy "token" header older clients send.
+func requestToken(c *gin.Context) string {
+	header := c.Request.Header.Get("Authorization")
+	if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return c.Request.Header.Get("token")
+}
+
+// requestAPIKey reads an API key from "X-API-Key" or
+// "Authorization: ApiKey <key>".
+func requestAPIKey(c *gin.Context) string {
+	if key := c.Request.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	header := c.Request.Header.Get("Authorization")
+	if scheme, key, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
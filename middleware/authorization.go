@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"restaurant_management/helpers"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authorize lets the request through only if the role in its token, or one
+// of the scopes of its API key, is one of roles or admin, and the login passed
+// TOTP if the role requires it. It must run after Authentication.
+func Authorize(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Require(c, roles...) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthorizeUnlessSelf lets users act on their own record, the one whose id
+// is the :id route parameter; on anyone else's it is Authorize(roles...).
+func AuthorizeUnlessSelf(roles ...string) gin.HandlerFunc {
+	authorize := Authorize(roles...)
+	return func(c *gin.Context) {
+		if c.Param("id") != "" && c.Param("id") == c.GetString("uid") {
+			c.Next()
+			return
+		}
+		authorize(c)
+	}
+}
+
+// Require decides like Authorize, for handlers whose required role depends
+// on what the request asks for. It answers 403, aborts the request and
+// returns false when the request may not go on.
+func Require(c *gin.Context, roles ...string) bool {
+	if helpers.RoleRequiresTOTP(c.GetString("role")) && !c.GetBool("mfa") {
+		c.Header("WWW-Authenticate", `Bearer realm="`+realm+`", error="insufficient_scope"`)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role; enroll at POST /users/totp/enroll and log in again"})
+		c.Abort()
+		return false
+	}
+	if !allowed(c, roles) {
+		c.Header("WWW-Authenticate", `Bearer realm="`+realm+`", error="insufficient_scope"`)
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to do this"})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+func allowed(c *gin.Context, roles []string) bool {
+	if helpers.RoleAllowed(c.GetString("role"), roles...) {
+		return true
+	}
+	for _, scope := range c.GetStringSlice("scopes") {
+		if helpers.RoleAllowed(scope, roles...) {
+			return true
+		}
+	}
+	return false
+}
+
+// HeadOffice lets the request through only if it was authenticated for the
+// head office rather than an outlet. It must run after Authentication.
+func HeadOffice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("home_tenant") != repository.DefaultTenant {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the head office can do this"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey lets a machine client such as a kiosk call the API without a
+// user login. Scopes are the roles the key acts with; admin is never one.
+// Only a hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	Tenant_id    string             `json:"tenant_id"`
+	Key_id       string             `json:"key_id"`
+	Name         *string            `json:"name" validate:"required,min=2,max=100"`
+	Prefix       string             `json:"prefix"`
+	Key_hash     string             `json:"-"`
+	Scopes       []string           `json:"scopes" validate:"required,min=1,dive,eq=manager|eq=waiter|eq=kitchen|eq=cashier"`
+	Created_by   string             `json:"created_by"`
+	Expires_at   *time.Time         `json:"expires_at"`
+	Last_used_at *time.Time         `json:"last_used_at"`
+	Revoked_at   *time.Time         `json:"revoked_at"`
+	Created_at   time.Time          `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit event types.
+const (
+	AuditAccountLocked   = "account_locked"
+	AuditAccountUnlocked = "account_unlocked"
+	AuditIPLocked        = "ip_locked"
+	AuditAPIKeyCreated   = "api_key_created"
+	AuditAPIKeyRevoked   = "api_key_revoked"
+	AuditUserDeactivated = "user_deactivated"
+	AuditUserReactivated = "user_reactivated"
+)
+
+// AuditEvent records a security-relevant action. Actor_id is the user who
+// caused it, empty when it was the system.
+type AuditEvent struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Tenant_id  string             `json:"tenant_id"`
+	Event_id   string             `json:"event_id"`
+	Type       string             `json:"type"`
+	User_id    string             `json:"user_id"`
+	Actor_id   string             `json:"actor_id"`
+	Ip         string             `json:"ip"`
+	Detail     string             `json:"detail"`
+	Created_at time.Time          `json:"created_at"`
+}
@@ -1,18 +1,52 @@
 package models
 
 import (
+	"restaurant_management/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Food struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	Name       *string            `json:"name" validate:"required,min=2,max=100"`
-	Price      *float64           `json:"price" validate:"required"`
-	Food_image *string            `json:"food_image" validate:"required"`
-	Created_at time.Time          `json:"created_at"`
-	Update_at  time.Time          `json:"update_at"`
-	Food_id    string             `json:"food_id"`
-	Menu_id    *string            `json:"menu_id" validate:"required"`
+	ID              primitive.ObjectID `bson:"_id"`
+	Tenant_id       string             `json:"-"`
+	Name            *string            `json:"name" validate:"required,min=2,max=100"`
+	Price           *money.Money       `json:"price" validate:"required"`
+	Sizes           []FoodSize         `json:"sizes" validate:"dive"`
+	Modifier_groups []ModifierGroup    `json:"modifier_groups" validate:"dive"`
+	// Station overrides the station of the food's menu.
+	Station    *string   `json:"station" validate:"omitempty,eq=kitchen|eq=grill|eq=bar|eq=cold"`
+	Food_image *string   `json:"food_image" validate:"required"`
+	Created_at time.Time `json:"created_at"`
+	Update_at  time.Time `json:"update_at"`
+	Food_id    string    `json:"food_id"`
+	Menu_id    *string   `json:"menu_id" validate:"required"`
+}
+
+// FoodSize is what a portion size adds to (or, when negative, takes off)
+// the base price of a food.
+type FoodSize struct {
+	Size        string      `json:"size" validate:"required,eq=S|eq=M|eq=L"`
+	Price_delta money.Money `json:"price_delta"`
+}
+
+// UnitPrice is the price of one portion of the given size. Sizes the food
+// does not list cost the base price; a food without a price yields nil.
+func (food Food) UnitPrice(size *string) (*money.Money, error) {
+	if food.Price == nil {
+		return nil, nil
+	}
+	price := *food.Price
+	if size != nil {
+		for _, foodSize := range food.Sizes {
+			if foodSize.Size == *size {
+				var err error
+				if price, err = price.Add(foodSize.Price_delta); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+	return &price, nil
 }
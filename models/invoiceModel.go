@@ -0,0 +1,37 @@
+package models
+
+import (
+	"restaurant_management/money"
+	"restaurant_management/pricing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PaymentMethodCash  = "cash"
+	PaymentMethodCard  = "card"
+	PaymentMethodOther = "other"
+)
+
+const (
+	PaymentStatusPending       = "pending"
+	PaymentStatusPaid          = "paid"
+	PaymentStatusPartiallyPaid = "partially_paid"
+	PaymentStatusRefunded      = "refunded"
+	PaymentStatusVoided        = "voided" // a split share replaced by a new split
+)
+
+type Invoice struct {
+	ID               primitive.ObjectID `bson:"_id"`
+	Tenant_id        string             `json:"-"`
+	Invoice_id       string             `json:"invoice_id"`
+	Order_id         *string            `json:"order_id" validate:"required"`
+	Payment_method   *string            `json:"payment_method" validate:"omitempty,eq=cash|eq=card|eq=other"`
+	Payment_status   *string            `json:"payment_status" validate:"omitempty,eq=pending|eq=paid|eq=partially_paid|eq=refunded"`
+	Payment_due      money.Money        `json:"payment_due"`
+	Payment_due_date time.Time          `json:"payment_due_date"`
+	Pricing          *pricing.Breakdown `json:"pricing"`
+	Created_at       time.Time          `json:"created_at"`
+	Updated_at       time.Time          `json:"updated_at"`
+}
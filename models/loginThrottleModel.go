@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginThrottle counts recent failed logins for one key, an email address
+// ("email:...") or a client IP ("ip:...").
+type LoginThrottle struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	Key             string             `json:"key"`
+	Failures        int                `json:"failures"`
+	Last_failure_at time.Time          `json:"last_failure_at"`
+	Locked_until    *time.Time         `json:"locked_until"`
+}
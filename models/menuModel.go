@@ -8,8 +8,10 @@ import (
 
 type Menu struct {
 	ID         primitive.ObjectID `bson:"_id"`
+	Tenant_id  string             `json:"-"`
 	Name       string             `json:"name" validate:"required"`
 	Category   string             `json:"category" validate:"required"`
+	Station    string             `json:"station" validate:"omitempty,eq=kitchen|eq=grill|eq=bar|eq=cold"`
 	Start_Date *time.Time         `json:"start_date"`
 	End_Date   *time.Time         `json:"end_date"`
 	Created_at time.Time          `json:"created_at"`
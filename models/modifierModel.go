@@ -0,0 +1,31 @@
+package models
+
+import "restaurant_management/money"
+
+// ModifierGroup is a set of choices offered with a food, such as "Extras" or
+// "Remove". A required group needs at least one selection even when
+// Min_selections is zero; a Max_selections of zero means no upper limit.
+type ModifierGroup struct {
+	Modifier_group_id string     `json:"modifier_group_id"`
+	Name              *string    `json:"name" validate:"required,min=1,max=100"`
+	Required          bool       `json:"required"`
+	Min_selections    int        `json:"min_selections" validate:"gte=0"`
+	Max_selections    int        `json:"max_selections" validate:"gte=0"`
+	Modifiers         []Modifier `json:"modifiers" validate:"required,min=1,dive"`
+}
+
+type Modifier struct {
+	Modifier_id string      `json:"modifier_id"`
+	Name        *string     `json:"name" validate:"required,min=1,max=100"`
+	Price_delta money.Money `json:"price_delta"`
+}
+
+// OrderItemModifier is a modifier chosen for an order item. Name and
+// Price_delta are copied from the food when the item is created, so later
+// menu edits do not change what was ordered.
+type OrderItemModifier struct {
+	Modifier_group_id string      `json:"modifier_group_id" validate:"required"`
+	Modifier_id       string      `json:"modifier_id" validate:"required"`
+	Name              string      `json:"name"`
+	Price_delta       money.Money `json:"price_delta"`
+}
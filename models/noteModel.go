@@ -8,6 +8,7 @@ import (
 
 type Note struct {
 	ID         primitive.ObjectID `bson:"_id"`
+	Tenant_id  string             `json:"-"`
 	Text       string             `json:"text"`
 	Title      string             `json:"title"`
 	Created_at time.Time          `json:"created_at"`
@@ -1,17 +1,58 @@
 package models
 
 import (
+	"restaurant_management/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	SizeSmall  = "S"
+	SizeMedium = "M"
+	SizeLarge  = "L"
+)
+
 type OrderItem struct {
-	ID            primitive.ObjectID `bson:"_id"`
-	Quantity      *string            `json:"quantity" validate:"required,eq=S|eq=M|eq=L"`
-	Created_at    time.Time          `json:"created_at"`
-	Updated_at    time.Time          `json:"updated_at"`
-	Food_id       *string            `json:"food_id" validate:"required"`
-	Order_item_id string             `json:"order_item_id"`
-	Order_id      string             `json:"order_id"`
+	ID            primitive.ObjectID  `bson:"_id"`
+	Tenant_id     string              `json:"-"`
+	Size          *string             `json:"size" validate:"omitempty,eq=S|eq=M|eq=L"`
+	Quantity      *int                `json:"quantity" validate:"omitempty,min=1"`
+	Modifiers     []OrderItemModifier `json:"modifiers" validate:"dive"`
+	Seat          *int                `json:"seat" validate:"omitempty,min=1"`
+	Created_at    time.Time           `json:"created_at"`
+	Updated_at    time.Time           `json:"updated_at"`
+	Food_id       *string             `json:"food_id" validate:"required"`
+	Order_item_id string              `json:"order_item_id"`
+	Order_id      string              `json:"order_id"`
+}
+
+// UnitPrice is what one portion of item costs: the price of food for the
+// ordered size plus the chosen modifiers. A food without a price yields nil.
+func (item OrderItem) UnitPrice(food Food) (*money.Money, error) {
+	price, err := food.UnitPrice(item.Size)
+	if price == nil || err != nil {
+		return nil, err
+	}
+	modifiers, err := item.ModifiersPrice()
+	if err != nil {
+		return nil, err
+	}
+	*price, err = price.Add(modifiers)
+	if err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+// ModifiersPrice is what the chosen modifiers add to one portion.
+func (item OrderItem) ModifiersPrice() (money.Money, error) {
+	var price money.Money
+	for _, modifier := range item.Modifiers {
+		var err error
+		if price, err = price.Add(modifier.Price_delta); err != nil {
+			return price, err
+		}
+	}
+	return price, nil
 }
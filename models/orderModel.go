@@ -1,16 +1,54 @@
 package models
 
 import (
+	"restaurant_management/pricing"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Order statuses. See helpers.ValidateOrderTransition for the allowed moves.
+const (
+	OrderStatusOpen          = "open"
+	OrderStatusSentToKitchen = "sent_to_kitchen"
+	OrderStatusServed        = "served"
+	OrderStatusBilled        = "billed"
+	OrderStatusPaid          = "paid"
+	OrderStatusClosed        = "closed"
+	OrderStatusCancelled     = "cancelled"
+	OrderStatusVoided        = "voided"
+	OrderStatusMerged        = "merged" // its items moved to another order
+)
+
+// EditableOrderStatuses are the statuses in which an order can still change
+// table and have items moved on or off it: everything before the bill.
+var EditableOrderStatuses = []string{OrderStatusOpen, OrderStatusSentToKitchen, OrderStatusServed}
+
+// OccupyingOrderStatuses are the statuses in which an order holds its
+// table: until it is paid.
+var OccupyingOrderStatuses = []string{OrderStatusOpen, OrderStatusSentToKitchen, OrderStatusServed, OrderStatusBilled}
+
+// ItemEditableOrderStatuses are the statuses in which the items of an order
+// can still be edited: until they are served.
+var ItemEditableOrderStatuses = []string{OrderStatusOpen, OrderStatusSentToKitchen}
+
 type Order struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	Order_date time.Time          `json:"order_date"`
-	Created_at time.Time          `json:"created_at"`
-	Updated_at time.Time          `json:"updated_at"`
-	Order_id   string             `json:"order_id"`
-	Table_id   *string            `json:"table_id" validate:"required"`
+	ID             primitive.ObjectID  `bson:"_id"`
+	Tenant_id      string              `json:"-"`
+	Order_date     time.Time           `json:"order_date"`
+	Created_at     time.Time           `json:"created_at"`
+	Updated_at     time.Time           `json:"updated_at"`
+	Order_id       string              `json:"order_id"`
+	Table_id       *string             `json:"table_id" validate:"required"`
+	Status         string              `json:"status"`
+	Status_history []OrderStatusChange `json:"status_history"`
+	Discounts      []pricing.Discount  `json:"discounts" validate:"dive"`
+}
+
+// OrderStatusChange records one move of an order between statuses.
+type OrderStatusChange struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Changed_by string    `json:"changed_by"`
+	Changed_at time.Time `json:"changed_at"`
 }
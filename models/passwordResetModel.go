@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PasswordReset records a password reset token sent to a user. Only a hash
+// of the token is kept, so the stored record cannot be used to reset.
+type PasswordReset struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Reset_id   string             `json:"reset_id"`
+	User_id    string             `json:"user_id"`
+	Token_hash string             `json:"token_hash"`
+	Expires_at time.Time          `json:"expires_at"`
+	Used_at    *time.Time         `json:"used_at"`
+	Created_at time.Time          `json:"created_at"`
+}
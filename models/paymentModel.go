@@ -0,0 +1,36 @@
+package models
+
+import (
+	"restaurant_management/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Split modes of a bill.
+const (
+	SplitEven  = "even"
+	SplitSeat  = "seat"
+	SplitItems = "items"
+)
+
+// Payment is one share of a split bill: what one guest owes towards an
+// invoice and whether they paid it. The pending and paid shares of an
+// invoice always add up to its Payment_due. Seat and Order_item_ids tell
+// what the share covers when the bill was split by seat or by items.
+type Payment struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Tenant_id      string             `json:"-"`
+	Payment_id     string             `json:"payment_id"`
+	Invoice_id     string             `json:"invoice_id"`
+	Order_id       string             `json:"order_id"`
+	Label          string             `json:"label"`
+	Seat           *int               `json:"seat"`
+	Order_item_ids []string           `json:"order_item_ids"`
+	Amount         money.Money        `json:"amount"`
+	Payment_method *string            `json:"payment_method"`
+	Status         string             `json:"status"`
+	Paid_at        *time.Time         `json:"paid_at"`
+	Created_at     time.Time          `json:"created_at"`
+	Updated_at     time.Time          `json:"updated_at"`
+}
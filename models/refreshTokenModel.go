@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken records an issued refresh token. Every token obtained by
+// refreshing joins the family of the one it replaced, so a replayed token
+// can take its whole family down with it. Mfa records that the login passed
+// TOTP, which the refreshed tokens inherit.
+type RefreshToken struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Token_id   string             `json:"token_id"`
+	Family_id  string             `json:"family_id"`
+	User_id    string             `json:"user_id"`
+	Mfa        bool               `json:"mfa"`
+	Expires_at time.Time          `json:"expires_at"`
+	Used_at    *time.Time         `json:"used_at"`
+	Revoked_at *time.Time         `json:"revoked_at"`
+	Created_at time.Time          `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reservation statuses. Only booked reservations hold their table.
+const (
+	ReservationBooked    = "booked"
+	ReservationCancelled = "cancelled"
+)
+
+// Reservation holds a table for a party from Reserved_at until Ends_at,
+// which is Reserved_at plus the turn time for the party size.
+type Reservation struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Tenant_id      string             `json:"-"`
+	Name           *string            `json:"name" validate:"required,min=2,max=100"`
+	Phone          *string            `json:"phone" validate:"required"`
+	Email          *string            `json:"email" validate:"omitempty,email"`
+	Party_size     *int               `json:"party_size" validate:"required,min=1"`
+	Reserved_at    *time.Time         `json:"reserved_at" validate:"required"`
+	Ends_at        time.Time          `json:"ends_at"`
+	Table_id       string             `json:"table_id"`
+	Status         string             `json:"status"`
+	Notes          *string            `json:"notes"`
+	Created_at     time.Time          `json:"created_at"`
+	Updated_at     time.Time          `json:"updated_at"`
+	Reservation_id string             `json:"reservation_id"`
+}
@@ -6,10 +6,39 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Table statuses. Cleaning and out of service are set by staff; the others
+// follow from the orders and reservations of the table.
+const (
+	TableFree         = "free"
+	TableOccupied     = "occupied"
+	TableReserved     = "reserved"
+	TableCleaning     = "cleaning"
+	TableOutOfService = "out_of_service"
+)
+
+// Table shapes for drawing the floor plan.
+const (
+	TableRound     = "round"
+	TableSquare    = "square"
+	TableRectangle = "rectangle"
+	TableBooth     = "booth"
+)
+
+// Table is a table and where it stands on the floor plan: its section, the
+// X and Y of its position and its shape, with the waiter serving it in
+// Server_id. Status is only what staff set, cleaning or out of service; it
+// is empty otherwise.
 type Table struct {
 	ID               primitive.ObjectID `bson:"_id"`
+	Tenant_id        string             `json:"-"`
 	Number_of_quests *int               `json:"number_of_guests" validate:"required"`
 	Table_number     *int               `json:"tabe_number" valdate:"required"`
+	Section          *string            `json:"section" validate:"omitempty,max=50"`
+	X                *float64           `json:"x"`
+	Y                *float64           `json:"y"`
+	Shape            *string            `json:"shape" validate:"omitempty,oneof=round square rectangle booth"`
+	Server_id        *string            `json:"server_id"`
+	Status           string             `json:"status"`
 	Created_at       time.Time          `json:"created_at"`
 	Updated_at       time.Time          `json:"updated_at"`
 	Table_id         string             `json:"table_id"`
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tenant is one outlet of a restaurant group. Currency and the tax rates
+// replace those of the pricing config on the outlet's bills when set;
+// Timezone is the IANA zone clients show the outlet's times in.
+type Tenant struct {
+	ID               primitive.ObjectID `bson:"_id"`
+	Tenant_id        string             `json:"tenant_id"`
+	Name             *string            `json:"name" validate:"required,min=2,max=100"`
+	Currency         *string            `json:"currency" validate:"omitempty,iso4217"`
+	Default_tax_rate *float64           `json:"default_tax_rate" validate:"omitempty,gte=0,lte=100"`
+	Tax_rates        map[string]float64 `json:"tax_rates" validate:"omitempty,dive,gte=0,lte=100"`
+	Timezone         *string            `json:"timezone" validate:"omitempty,timezone"`
+	Created_at       time.Time          `json:"created_at"`
+	Updated_at       time.Time          `json:"updated_at"`
+}
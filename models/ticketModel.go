@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kitchen stations. Items whose food and menu name no station go to the
+// general kitchen queue.
+const (
+	StationKitchen = "kitchen"
+	StationGrill   = "grill"
+	StationBar     = "bar"
+	StationCold    = "cold"
+)
+
+// Ticket statuses, in the order a ticket moves through them.
+const (
+	TicketStatusQueued  = "queued"
+	TicketStatusCooking = "cooking"
+	TicketStatusReady   = "ready"
+	TicketStatusServed  = "served"
+	// TicketStatusVoided is for tickets whose order was cancelled or voided
+	// before they were served.
+	TicketStatusVoided = "voided"
+)
+
+// OpenTicketStatuses are the statuses of tickets the kitchen still has to
+// deal with, the ones shown on a kitchen display.
+var OpenTicketStatuses = []string{TicketStatusQueued, TicketStatusCooking, TicketStatusReady}
+
+// Ticket is one order item as the kitchen sees it: what to make, for which
+// table and at which station.
+type Ticket struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Tenant_id     string             `json:"-"`
+	Ticket_id     string             `json:"ticket_id"`
+	Order_id      string             `json:"order_id"`
+	Order_item_id string             `json:"order_item_id"`
+	Table_number  *int               `json:"table_number"`
+	Station       string             `json:"station"`
+	Food_name     *string            `json:"food_name"`
+	Size          *string            `json:"size"`
+	Quantity      int                `json:"quantity"`
+	Modifiers     []string           `json:"modifiers"`
+	Status        string             `json:"status"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+}
@@ -6,17 +6,39 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Roles a user can hold. Admins pass every permission check.
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleWaiter  = "waiter"
+	RoleKitchen = "kitchen"
+	RoleCashier = "cashier"
+)
+
+// User is a member of staff at the outlet Tenant_id, which is empty for the
+// head office. Totp_secret is set on enrollment but only takes
+// effect once a code confirms it and sets Totp_enabled; Recovery_codes holds
+// bcrypt hashes of the unused recovery codes. Deactivated users cannot log
+// in.
 type User struct {
-	ID            primitive.ObjectID `bson:"_id"`
-	First_name    *string            `json:"first_name" validate:"required,min=2,max=100"`
-	Last_name     *string            `json:"last_name" validate:"required,min=2,max=100"`
-	Password      *string            `json:"password" validate:"required,min=6,max=12"`
-	Email         *string            `json:"email" validate:"required,email"`
-	Avatar        *string            `json:"avatar"`
-	Phone         *string            `json:"phone"`
-	Token         *string            `json:"token"`
-	Refresh_token *string            `json:"refresh_token"`
-	Created_at    time.Time          `json:"created_at"`
-	Updated_at    time.Time          `json:"updated_at"`
-	User_id       string             `json:"user_id"`
+	ID             primitive.ObjectID `bson:"_id"`
+	Tenant_id      string             `json:"tenant_id"`
+	First_name     *string            `json:"first_name" validate:"required,min=2,max=100"`
+	Last_name      *string            `json:"last_name" validate:"required,min=2,max=100"`
+	Password       *string            `json:"password" validate:"required,min=6,max=12"`
+	Email          *string            `json:"email" validate:"required,email"`
+	Avatar         *string            `json:"avatar"`
+	Phone          *string            `json:"phone"`
+	Role           string             `json:"role" validate:"omitempty,eq=admin|eq=manager|eq=waiter|eq=kitchen|eq=cashier"`
+	Email_verified bool               `json:"email_verified"`
+	Totp_enabled   bool               `json:"totp_enabled"`
+	Totp_secret    *string            `json:"-"`
+	Totp_last_step int64              `json:"-"`
+	Recovery_codes []string           `json:"-"`
+	Deactivated_at *time.Time         `json:"deactivated_at"`
+	Token          *string            `json:"token"`
+	Refresh_token  *string            `json:"refresh_token"`
+	Created_at     time.Time          `json:"created_at"`
+	Updated_at     time.Time          `json:"updated_at"`
+	User_id        string             `json:"user_id"`
 }
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Waitlist statuses. Waiting and notified parties are still in the queue.
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistNotified  = "notified"
+	WaitlistSeated    = "seated"
+	WaitlistCancelled = "cancelled"
+)
+
+// WaitlistEntry is a walk-in party waiting for a table. Quoted_wait_minutes
+// is what the party was told when added; once seated, Table_id and Order_id
+// point at where they sat and what they ordered.
+type WaitlistEntry struct {
+	ID                  primitive.ObjectID `bson:"_id"`
+	Tenant_id           string             `json:"-"`
+	Name                *string            `json:"name" validate:"required,min=2,max=100"`
+	Party_size          *int               `json:"party_size" validate:"required,min=1"`
+	Phone               *string            `json:"phone" validate:"required"`
+	Notes               *string            `json:"notes"`
+	Status              string             `json:"status"`
+	Quoted_wait_minutes int                `json:"quoted_wait_minutes"`
+	Notified_at         *time.Time         `json:"notified_at"`
+	Seated_at           *time.Time         `json:"seated_at"`
+	Table_id            *string            `json:"table_id"`
+	Order_id            *string            `json:"order_id"`
+	Created_at          time.Time          `json:"created_at"`
+	Updated_at          time.Time          `json:"updated_at"`
+	Entry_id            string             `json:"entry_id"`
+}
@@ -0,0 +1,191 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// MarshalJSON writes m as a plain JSON number such as 12.50, so clients
+// keep reading amounts without a currency as numbers. An amount in a
+// currency is written as {"amount": 12.50, "currency": "EUR"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.Currency == "" {
+		return []byte(m.String()), nil
+	}
+	currency, err := json.Marshal(m.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(`{"amount":` + m.String() + `,"currency":` + string(currency) + `}`), nil
+}
+
+// UnmarshalJSON accepts a number (12.5), a numeric string ("12.50") or an
+// object {"amount": 12.5, "currency": "EUR"}. The amount is in major units
+// of the currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	currency := ""
+	if len(data) > 0 && data[0] == '{' {
+		var object struct {
+			Amount   json.RawMessage `json:"amount"`
+			Currency string          `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		data, currency = bytes.TrimSpace(object.Amount), object.Currency
+		switch {
+		case len(data) > 0 && data[0] == '{':
+			return fmt.Errorf("%w: nested amount", errUnsupported)
+		case bytes.Equal(data, []byte("null")):
+			*m = Money{Currency: currency}
+			return nil
+		}
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	parsed, err := Parse(string(data), currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// MarshalBSONValue stores m as a Decimal128, or as a document
+// {amount: Decimal128, currency: "EUR"} when it is in a currency.
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(m.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	if m.Currency == "" {
+		return bson.MarshalValue(d)
+	}
+	return bson.MarshalValue(bson.D{{Key: "amount", Value: d}, {Key: "currency", Value: m.Currency}})
+}
+
+// UnmarshalBSONValue reads what MarshalBSONValue writes as well as the
+// doubles and integers prices were stored as before.
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: t, Data: data}
+	currency := ""
+	if t == bsontype.EmbeddedDocument {
+		document := value.Document()
+		amount, err := document.LookupErr("amount")
+		if err != nil {
+			return fmt.Errorf("%w: document without amount", errUnsupported)
+		}
+		if amount.Type == bsontype.EmbeddedDocument {
+			return fmt.Errorf("%w: nested amount", errUnsupported)
+		}
+		currency, _ = document.Lookup("currency").StringValueOK()
+		value = amount
+	}
+	amount, err := bsonAmount(value, currency)
+	if err != nil {
+		return err
+	}
+	*m = Money{Amount: amount, Currency: currency}
+	return nil
+}
+
+// bsonAmount reads value, in major units of currency, as minor units.
+func bsonAmount(value bsoncore.Value, currency string) (int64, error) {
+	switch value.Type {
+	case bsontype.Null, bsontype.Undefined:
+		return 0, nil
+	case bsontype.Decimal128:
+		parsed, err := Parse(value.Decimal128().String(), currency)
+		return parsed.Amount, err
+	case bsontype.Double:
+		return floatAmount(value.Double(), unit(currency))
+	case bsontype.Int32:
+		return int64(value.Int32()) * unit(currency), nil
+	case bsontype.Int64:
+		v, u := value.Int64(), unit(currency)
+		if v > math.MaxInt64/u || v < math.MinInt64/u {
+			return 0, fmt.Errorf("%w: %d", errOverflow, v)
+		}
+		return v * u, nil
+	case bsontype.String:
+		parsed, err := Parse(value.StringValue(), currency)
+		return parsed.Amount, err
+	default:
+		return 0, fmt.Errorf("%w: bson %s", errUnsupported, value.Type)
+	}
+}
+
+// Value stores m in SQL as text: the integer count of minor units followed
+// by the currency, if any, such as "1250 EUR".
+func (m Money) Value() (driver.Value, error) {
+	if m.Currency == "" {
+		return fmt.Sprint(m.Amount), nil
+	}
+	return fmt.Sprintf("%d %s", m.Amount, m.Currency), nil
+}
+
+// Scan reads the text written by Value as well as the integer minor units
+// amounts were stored as before.
+func (m *Money) Scan(src any) error {
+	m.Currency = ""
+	switch v := src.(type) {
+	case nil:
+		m.Amount = 0
+	case int64:
+		m.Amount = v
+	case float64:
+		amount, err := floatAmount(v, 1)
+		if err != nil {
+			return err
+		}
+		m.Amount = amount
+	case []byte:
+		return m.Scan(string(v))
+	case string:
+		amount, currency, _ := strings.Cut(strings.TrimSpace(v), " ")
+		r, ok := new(big.Rat).SetString(amount)
+		if !ok {
+			return fmt.Errorf("%w: %q", errUnsupported, v)
+		}
+		minor, err := minorUnits(r, RoundHalfUp)
+		if err != nil {
+			return err
+		}
+		m.Amount = minor
+		m.Currency = strings.TrimSpace(currency)
+	default:
+		return fmt.Errorf("%w: %T", errUnsupported, src)
+	}
+	return nil
+}
+
+// floatAmount converts f to minor units, of which one unit of f holds
+// minor. Like FromFloat it goes by the shortest decimal that reads back as
+// f, but NaN, infinities and amounts too large to hold are errors.
+func floatAmount(f float64, minor int64) (int64, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%w: %v", errUnsupported, f)
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return minorUnits(r.Mul(r, big.NewRat(minor, 1)), RoundHalfUp)
+}
@@ -0,0 +1,104 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		m    Money
+		json string
+	}{
+		{New(1250, ""), `12.50`},
+		{New(-307, "USD"), `{"amount":-3.07,"currency":"USD"}`},
+		{New(1250, "JPY"), `{"amount":1250,"currency":"JPY"}`},
+		{New(1235, "KWD"), `{"amount":1.235,"currency":"KWD"}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.m)
+		if err != nil || string(data) != tt.json {
+			t.Errorf("Marshal(%+v) = %s, %v; want %s", tt.m, data, err, tt.json)
+		}
+		var m Money
+		if err := json.Unmarshal([]byte(tt.json), &m); err != nil || m != tt.m {
+			t.Errorf("Unmarshal(%s) = %+v, %v; want %+v", tt.json, m, err, tt.m)
+		}
+	}
+
+	inputs := map[string]Money{
+		`"12.5"`:                              New(1250, ""),
+		`{"amount":"1250","currency":"JPY"}`:  New(1250, "JPY"),
+		`{"amount":12.5,"currency":"KWD"}`:    New(12500, "KWD"),
+		`{"amount":null,"currency":"EUR"}`:    New(0, "EUR"),
+		`{"amount":0.125,"currency":"USD"}  `: New(13, "USD"),
+	}
+	for in, want := range inputs {
+		var m Money
+		if err := json.Unmarshal([]byte(in), &m); err != nil || m != want {
+			t.Errorf("Unmarshal(%s) = %+v, %v; want %+v", in, m, err, want)
+		}
+	}
+	for _, in := range []string{`"abc"`, `{"amount":{"amount":1}}`, `{"currency":"USD"}`} {
+		var m Money
+		if err := json.Unmarshal([]byte(in), &m); err == nil {
+			t.Errorf("Unmarshal(%s) = %+v, want an error", in, m)
+		}
+	}
+}
+
+func TestBSON(t *testing.T) {
+	type document struct {
+		Price Money `bson:"price"`
+	}
+	for _, m := range []Money{New(1250, ""), New(-307, "USD"), New(1250, "JPY"), New(1235, "KWD")} {
+		data, err := bson.Marshal(document{Price: m})
+		if err != nil {
+			t.Fatalf("Marshal(%+v): %v", m, err)
+		}
+		var read document
+		if err := bson.Unmarshal(data, &read); err != nil || read.Price != m {
+			t.Errorf("round trip of %+v = %+v, %v", m, read.Price, err)
+		}
+	}
+
+	legacy := map[string]struct {
+		value any
+		want  Money
+	}{
+		"double":      {12.5, New(1250, "")},
+		"int32":       {int32(12), New(1200, "")},
+		"int64":       {int64(12), New(1200, "")},
+		"yen double":  {bson.D{{Key: "amount", Value: 1250.0}, {Key: "currency", Value: "JPY"}}, New(1250, "JPY")},
+		"dinar int64": {bson.D{{Key: "amount", Value: int64(2)}, {Key: "currency", Value: "KWD"}}, New(2000, "KWD")},
+	}
+	for name, tt := range legacy {
+		data, err := bson.Marshal(bson.D{{Key: "price", Value: tt.value}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var read document
+		if err := bson.Unmarshal(data, &read); err != nil || read.Price != tt.want {
+			t.Errorf("%s = %+v, %v; want %+v", name, read.Price, err, tt.want)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	for _, m := range []Money{New(1250, ""), New(-307, "USD"), New(1250, "JPY")} {
+		value, err := m.Value()
+		if err != nil {
+			t.Fatalf("Value(%+v): %v", m, err)
+		}
+		var read Money
+		if err := read.Scan(value); err != nil || read != m {
+			t.Errorf("round trip of %+v through %v = %+v, %v", m, value, read, err)
+		}
+	}
+	var read Money
+	if err := read.Scan(int64(1250)); err != nil || read != New(1250, "") {
+		t.Errorf("Scan of integer minor units = %+v, %v", read, err)
+	}
+}
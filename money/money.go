@@ -0,0 +1,253 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Rounding modes used when an amount has to be cut back to whole minor units.
+const (
+	RoundHalfUp   = "half_up"
+	RoundHalfEven = "half_even"
+	RoundDown     = "down"
+	RoundUp       = "up"
+)
+
+// exponents holds the ISO 4217 currencies whose minor unit is not a
+// hundredth of the major unit. Every other currency, and amounts without a
+// currency, have two decimals.
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Exponent is the number of decimals of the minor unit of currency, such
+// as 2 for EUR, 0 for JPY and 3 for KWD.
+func Exponent(currency string) int {
+	if exponent, ok := exponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// unit is the number of minor units in one major unit of currency.
+func unit(currency string) int64 {
+	u := int64(1)
+	for range Exponent(currency) {
+		u *= 10
+	}
+	return u
+}
+
+// Money is an exact amount in minor units of Currency. An empty Currency
+// means the deployment's currency and is compatible with every other.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New returns amount minor units of currency.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal such as "12.5" or "-3.07" in currency. Digits
+// beyond the minor unit of currency are rounded half away from zero.
+func Parse(s string, currency string) (Money, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return fromRat(r, currency, RoundHalfUp)
+}
+
+// FromFloat converts a float amount in currency, rounding half away from
+// zero. NaN, infinities and amounts too large to hold yield zero.
+func FromFloat(f float64, currency string) Money {
+	m, err := Parse(strconv.FormatFloat(f, 'f', -1, 64), currency)
+	if err != nil {
+		return Money{Currency: currency}
+	}
+	return m
+}
+
+// fromRat converts r major units of currency to minor units.
+func fromRat(r *big.Rat, currency string, mode string) (Money, error) {
+	amount, err := minorUnits(new(big.Rat).Mul(r, big.NewRat(unit(currency), 1)), mode)
+	return Money{Amount: amount, Currency: currency}, err
+}
+
+// In returns m in currency. An amount without a currency is in the
+// deployment's currency, with two decimals, and is converted to the minor
+// unit of currency; an amount in another currency fails with
+// ErrCurrencyMismatch.
+func (m Money) In(currency string) (Money, error) {
+	if m.Currency == currency || currency == "" {
+		return m, nil
+	}
+	if m.Currency != "" {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, currency)
+	}
+	return fromRat(big.NewRat(m.Amount, unit("")), currency, RoundHalfUp)
+}
+
+// minorUnits rounds r to an integer using mode, failing when the result
+// does not fit in an int64.
+func minorUnits(r *big.Rat, mode string) (int64, error) {
+	quo := roundInt(r, mode)
+	if !quo.IsInt64() {
+		return 0, fmt.Errorf("%w: %s", errOverflow, r.FloatString(2))
+	}
+	return quo.Int64(), nil
+}
+
+func roundInt(r *big.Rat, mode string) *big.Int {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+	negative := r.Sign() < 0
+	// twice the remainder against the denominator tells us where we are
+	// relative to the halfway point
+	half := new(big.Int).Abs(rem)
+	half.Mul(half, big.NewInt(2))
+	cmp := half.Cmp(r.Denom())
+
+	away := false
+	switch mode {
+	case RoundDown:
+		away = negative
+	case RoundUp:
+		away = !negative
+	case RoundHalfEven:
+		away = cmp > 0 || (cmp == 0 && quo.Bit(0) == 1)
+	default:
+		away = cmp >= 0
+	}
+	if away {
+		if negative {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+// currency is the currency of an amount computed from m and o. It fails
+// when they are in different currencies.
+func (m Money) currency(o Money) (string, error) {
+	if m.Currency == "" {
+		return o.Currency, nil
+	}
+	if o.Currency != "" && o.Currency != m.Currency {
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return m.Currency, nil
+}
+
+// Add returns m + o. It fails when they are in different currencies or the
+// sum does not fit in an int64.
+func (m Money) Add(o Money) (Money, error) {
+	currency, err := m.currency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + o.Amount
+	if (sum >= m.Amount) != (o.Amount >= 0) {
+		return Money{}, fmt.Errorf("%w: %s + %s", errOverflow, m, o)
+	}
+	return Money{Amount: sum, Currency: currency}, nil
+}
+
+// Sub returns m - o, failing like Add.
+func (m Money) Sub(o Money) (Money, error) {
+	currency, err := m.currency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	difference := m.Amount - o.Amount
+	if (difference <= m.Amount) != (o.Amount >= 0) {
+		return Money{}, fmt.Errorf("%w: %s - %s", errOverflow, m, o)
+	}
+	return Money{Amount: difference, Currency: currency}, nil
+}
+
+// Mul multiplies by a whole quantity, which never needs rounding. It fails
+// when the product does not fit in an int64.
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s * %d", errOverflow, m, quantity)
+	}
+	return Money{Amount: product.Int64(), Currency: m.Currency}, nil
+}
+
+// Percent returns rate percent of m, rounded to a minor unit with mode.
+func (m Money) Percent(rate float64, mode string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: rate %v", errUnsupported, rate)
+	}
+	amount, err := minorUnits(r.Mul(r, big.NewRat(m.Amount, 100)), mode)
+	return Money{Amount: amount, Currency: m.Currency}, err
+}
+
+// Allocate returns part/whole of m, rounded with mode.
+func (m Money) Allocate(part Money, whole Money, mode string) (Money, error) {
+	if whole.Amount == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+	numerator := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(part.Amount))
+	amount, err := minorUnits(new(big.Rat).SetFrac(numerator, big.NewInt(whole.Amount)), mode)
+	return Money{Amount: amount, Currency: m.Currency}, err
+}
+
+// RoundTo rounds m to a multiple of increment. A zero increment leaves m
+// unchanged.
+func (m Money) RoundTo(increment Money, mode string) (Money, error) {
+	if increment.Amount <= 0 {
+		return m, nil
+	}
+	steps := roundInt(big.NewRat(m.Amount, increment.Amount), mode)
+	amount, err := minorUnits(new(big.Rat).SetInt(steps.Mul(steps, big.NewInt(increment.Amount))), mode)
+	return Money{Amount: amount, Currency: m.Currency}, err
+}
+
+// Min returns the smaller of m and o.
+func (m Money) Min(o Money) (Money, error) {
+	currency, err := m.currency(o)
+	if o.Amount < m.Amount {
+		return Money{Amount: o.Amount, Currency: currency}, err
+	}
+	return Money{Amount: m.Amount, Currency: currency}, err
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Float64 is for display and legacy callers only; never compute with it.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / float64(unit(m.Currency))
+}
+
+// String formats m as a plain decimal with as many places as its currency
+// has decimals, e.g. "-3.07", or "1250" for JPY.
+func (m Money) String() string {
+	return new(big.Rat).SetFrac64(m.Amount, unit(m.Currency)).FloatString(Exponent(m.Currency))
+}
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are
+// combined.
+var ErrCurrencyMismatch = errors.New("money: amounts are in different currencies")
+
+var (
+	errUnsupported = errors.New("money: unsupported value")
+	errOverflow    = errors.New("money: amount out of range")
+)
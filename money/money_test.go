@@ -0,0 +1,151 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		amount   int64
+		out      string
+	}{
+		{"12.5", "", 1250, "12.50"},
+		{"-3.07", "USD", -307, "-3.07"},
+		{"0.005", "EUR", 1, "0.01"},
+		{"-0.005", "EUR", -1, "-0.01"},
+		{"1250", "JPY", 1250, "1250"},
+		{"1250.5", "JPY", 1251, "1251"},
+		{"1.2345", "KWD", 1235, "1.235"},
+		{"0.5", "CLF", 5000, "0.5000"},
+	}
+	for _, tt := range tests {
+		m, err := Parse(tt.in, tt.currency)
+		if err != nil {
+			t.Errorf("Parse(%q, %q): %v", tt.in, tt.currency, err)
+			continue
+		}
+		if m != New(tt.amount, tt.currency) || m.String() != tt.out {
+			t.Errorf("Parse(%q, %q) = %d %q, want %d %q", tt.in, tt.currency, m.Amount, m.String(), tt.amount, tt.out)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1e400"} {
+		if _, err := Parse(in, "USD"); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestIn(t *testing.T) {
+	tests := []struct {
+		m        Money
+		currency string
+		want     Money
+	}{
+		{New(1250, ""), "USD", New(1250, "USD")},
+		{New(125000, ""), "JPY", New(1250, "JPY")},
+		{New(1250, ""), "KWD", New(12500, "KWD")},
+		{New(1250, "JPY"), "JPY", New(1250, "JPY")},
+		{New(1250, "EUR"), "", New(1250, "EUR")},
+	}
+	for _, tt := range tests {
+		got, err := tt.m.In(tt.currency)
+		if err != nil || got != tt.want {
+			t.Errorf("%+v.In(%q) = %+v, %v; want %+v", tt.m, tt.currency, got, err, tt.want)
+		}
+	}
+	if _, err := New(1250, "EUR").In("USD"); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("EUR in USD = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := New(1050, "USD"), New(275, "")
+	if sum, err := a.Add(b); err != nil || sum != New(1325, "USD") {
+		t.Errorf("Add = %+v, %v", sum, err)
+	}
+	if difference, err := b.Sub(a); err != nil || difference != New(-775, "USD") {
+		t.Errorf("Sub = %+v, %v", difference, err)
+	}
+	if product, err := a.Mul(3); err != nil || product != New(3150, "USD") {
+		t.Errorf("Mul = %+v, %v", product, err)
+	}
+	if share, err := a.Allocate(New(1, ""), New(3, ""), RoundHalfUp); err != nil || share != New(350, "USD") {
+		t.Errorf("Allocate = %+v, %v", share, err)
+	}
+	if _, err := a.Add(New(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("USD + EUR = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	top, bottom := New(math.MaxInt64, "USD"), New(math.MinInt64, "USD")
+	checks := map[string]func() (Money, error){
+		"Add":      func() (Money, error) { return top.Add(New(1, "USD")) },
+		"Add low":  func() (Money, error) { return bottom.Add(New(-1, "USD")) },
+		"Sub":      func() (Money, error) { return bottom.Sub(New(1, "USD")) },
+		"Sub high": func() (Money, error) { return top.Sub(New(-1, "USD")) },
+		"Mul":      func() (Money, error) { return top.Mul(2) },
+		"Mul low":  func() (Money, error) { return bottom.Mul(-1) },
+		"Allocate": func() (Money, error) { return top.Allocate(New(3, ""), New(2, ""), RoundHalfUp) },
+		"Percent":  func() (Money, error) { return top.Percent(200, RoundHalfUp) },
+	}
+	for name, check := range checks {
+		if m, err := check(); !errors.Is(err, errOverflow) {
+			t.Errorf("%s = %+v, %v; want errOverflow", name, m, err)
+		}
+	}
+
+	if sum, err := top.Add(New(-1, "USD")); err != nil || sum.Amount != math.MaxInt64-1 {
+		t.Errorf("Add near the top = %+v, %v", sum, err)
+	}
+	if difference, err := bottom.Sub(New(-1, "USD")); err != nil || difference.Amount != math.MinInt64+1 {
+		t.Errorf("Sub near the bottom = %+v, %v", difference, err)
+	}
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		mode   string
+		amount int64
+		rate   float64
+		want   int64
+	}{
+		{RoundHalfUp, 250, 10, 25},
+		{RoundHalfUp, 5, 50, 3},
+		{RoundHalfEven, 5, 50, 2},
+		{RoundDown, 9, 50, 4},
+		{RoundUp, 1, 10, 1},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.amount, "USD").Percent(tt.rate, tt.mode)
+		if err != nil || got.Amount != tt.want {
+			t.Errorf("%d at %v%% %s = %+v, %v; want %d", tt.amount, tt.rate, tt.mode, got, err, tt.want)
+		}
+	}
+
+	rounds := []struct {
+		mode      string
+		amount    int64
+		increment int64
+		want      int64
+	}{
+		{RoundHalfUp, 1002, 5, 1000},
+		{RoundHalfUp, 1003, 5, 1005},
+		{RoundHalfEven, 1025, 10, 1020},
+		{RoundHalfEven, 1035, 10, 1040},
+		{RoundDown, 1004, 5, 1000},
+		{RoundUp, 1001, 5, 1005},
+		{RoundUp, 1001, 0, 1001},
+	}
+	for _, tt := range rounds {
+		got, err := New(tt.amount, "USD").RoundTo(New(tt.increment, "USD"), tt.mode)
+		if err != nil || got.Amount != tt.want {
+			t.Errorf("%d to %d %s = %+v, %v; want %d", tt.amount, tt.increment, tt.mode, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Message is a short text for a guest, addressed by phone number.
+type Message struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// Notifier delivers messages to guests. Handlers only see this interface, so
+// an SMS service can be plugged in without touching them.
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// NewNotifier returns a WebhookNotifier posting to url, or a LogNotifier when
+// url is empty.
+func NewNotifier(url string) Notifier {
+	if url == "" {
+		return LogNotifier{}
+	}
+	return WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// LogNotifier writes messages to the standard logger instead of sending them.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, message Message) error {
+	log.Printf("notify %s: %s", message.To, message.Body)
+	return nil
+}
+
+// WebhookNotifier posts every message as JSON to URL, for an SMS gateway or
+// paging system to deliver. Any status but 2xx is an error.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n WebhookNotifier) Notify(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := n.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("notification webhook answered %s", response.Status)
+	}
+	return nil
+}
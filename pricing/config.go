@@ -0,0 +1,59 @@
+package pricing
+
+import (
+	"encoding/json"
+	"os"
+	"restaurant_management/money"
+)
+
+// Rounding modes for monetary amounts.
+const (
+	RoundHalfUp   = money.RoundHalfUp
+	RoundHalfEven = money.RoundHalfEven
+	RoundDown     = money.RoundDown
+	RoundUp       = money.RoundUp
+)
+
+const DefaultCurrency = "USD"
+
+// Config holds the rates the engine applies. Rates are percentages, so a
+// Default_tax_rate of 5 means 5%.
+type Config struct {
+	// Tax_rates maps a menu category to its tax rate. Categories missing
+	// from the map use Default_tax_rate.
+	Tax_rates           map[string]float64 `json:"tax_rates"`
+	Default_tax_rate    float64            `json:"default_tax_rate"`
+	Service_charge_rate float64            `json:"service_charge_rate"`
+	Rounding            string             `json:"rounding"`
+	// Total_increment rounds the grand total to a cash increment such as
+	// 0.05, in Currency. Zero leaves it at the minor unit of Currency.
+	Total_increment money.Money `json:"total_increment"`
+	// Currency is the ISO 4217 code every amount is billed in.
+	Currency string `json:"currency"`
+}
+
+// LoadConfig reads a JSON config from path. An empty path yields a config
+// without tax, service charge or cash rounding.
+func LoadConfig(path string) (Config, error) {
+	config := Config{Rounding: RoundHalfUp, Currency: DefaultCurrency}
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, err
+	}
+	// the increment was read before the currency it is in was known
+	config.Total_increment, err = config.Total_increment.In(config.Currency)
+	return config, err
+}
+
+func (c Config) taxRate(category string) float64 {
+	if rate, ok := c.Tax_rates[category]; ok {
+		return rate
+	}
+	return c.Default_tax_rate
+}
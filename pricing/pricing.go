@@ -0,0 +1,135 @@
+package pricing
+
+import "restaurant_management/money"
+
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+)
+
+// Discount is taken off the order subtotal, either as a percentage of it or
+// as a fixed amount.
+type Discount struct {
+	Name  string  `json:"name" bson:"name"`
+	Type  string  `json:"type" bson:"type" validate:"required,eq=percentage|eq=fixed"`
+	Value float64 `json:"value" bson:"value" validate:"gt=0"`
+}
+
+// Item is one priced line of an order. Order_item_id and Seat only say
+// which order item the line bills, so a bill can later be split by them.
+type Item struct {
+	Order_item_id string      `json:"order_item_id,omitempty" bson:"order_item_id,omitempty"`
+	Seat          *int        `json:"seat,omitempty" bson:"seat,omitempty"`
+	Name          string      `json:"name" bson:"name"`
+	Category      string      `json:"category" bson:"category"`
+	Size          string      `json:"size,omitempty" bson:"size,omitempty"`
+	Modifiers     []string    `json:"modifiers,omitempty" bson:"modifiers,omitempty"`
+	Unit_price    money.Money `json:"unit_price" bson:"unit_price"`
+	Quantity      int         `json:"quantity" bson:"quantity"`
+}
+
+// Line is the breakdown of a single Item.
+type Line struct {
+	Item     `bson:",inline"`
+	Subtotal money.Money `json:"subtotal" bson:"subtotal"`
+	Discount money.Money `json:"discount" bson:"discount"`
+	Tax_rate float64     `json:"tax_rate" bson:"tax_rate"`
+	Tax      money.Money `json:"tax" bson:"tax"`
+	Total    money.Money `json:"total" bson:"total"`
+}
+
+// Breakdown is the full bill for a set of items.
+type Breakdown struct {
+	Lines               []Line      `json:"lines" bson:"lines"`
+	Currency            string      `json:"currency" bson:"currency"`
+	Subtotal            money.Money `json:"subtotal" bson:"subtotal"`
+	Discount            money.Money `json:"discount" bson:"discount"`
+	Service_charge      money.Money `json:"service_charge" bson:"service_charge"`
+	Tax                 money.Money `json:"tax" bson:"tax"`
+	Rounding_adjustment money.Money `json:"rounding_adjustment" bson:"rounding_adjustment"`
+	Total               money.Money `json:"total" bson:"total"`
+}
+
+// Price computes the bill for items. Discounts apply to the subtotal and are
+// spread over the lines in proportion to their subtotal, so each line is
+// taxed at its category rate on what is actually charged. The service charge
+// is a percentage of the discounted subtotal and is not taxed. It fails with
+// money.ErrCurrencyMismatch when an item is priced in another currency than
+// the bill.
+func (c Config) Price(items []Item, discounts []Discount) (Breakdown, error) {
+	var l ledger
+	zero := money.New(0, c.Currency)
+	bill := Breakdown{
+		Currency:            c.Currency,
+		Subtotal:            zero,
+		Discount:            zero,
+		Service_charge:      zero,
+		Tax:                 zero,
+		Rounding_adjustment: zero,
+		Total:               zero,
+	}
+	for _, item := range items {
+		line := Line{Item: item}
+		line.Subtotal = l.keep(zero.Add(l.keep(item.Unit_price.Mul(int64(item.Quantity)))))
+		line.Tax_rate = c.taxRate(item.Category)
+		bill.Subtotal = l.keep(bill.Subtotal.Add(line.Subtotal))
+		bill.Lines = append(bill.Lines, line)
+	}
+
+	for _, discount := range discounts {
+		switch discount.Type {
+		case DiscountPercentage:
+			bill.Discount = l.keep(bill.Discount.Add(l.keep(bill.Subtotal.Percent(discount.Value, c.Rounding))))
+		case DiscountFixed:
+			bill.Discount = l.keep(bill.Discount.Add(money.FromFloat(discount.Value, c.Currency)))
+		}
+	}
+	bill.Discount = l.keep(bill.Discount.Min(bill.Subtotal))
+
+	// Spread the discount in proportion to the line subtotals. Split keeps
+	// every line's share between zero and its subtotal, as the discount is
+	// at most the order subtotal, and makes the shares add up to the order
+	// discount exactly.
+	subtotals := []money.Money{}
+	for _, line := range bill.Lines {
+		subtotals = append(subtotals, line.Subtotal)
+	}
+	shares := make([]money.Money, len(bill.Lines))
+	if len(bill.Lines) > 0 {
+		var err error
+		if shares, err = Split(bill.Discount, subtotals); err != nil {
+			return bill, err
+		}
+	}
+	for i := range bill.Lines {
+		line := &bill.Lines[i]
+		line.Discount = shares[i]
+		charged := l.keep(line.Subtotal.Sub(line.Discount))
+		line.Tax = l.keep(charged.Percent(line.Tax_rate, c.Rounding))
+		line.Total = l.keep(charged.Add(line.Tax))
+		bill.Tax = l.keep(bill.Tax.Add(line.Tax))
+	}
+
+	charged := l.keep(bill.Subtotal.Sub(bill.Discount))
+	bill.Service_charge = l.keep(charged.Percent(c.Service_charge_rate, c.Rounding))
+	exact := l.keep(l.keep(charged.Add(bill.Service_charge)).Add(bill.Tax))
+	bill.Total = l.keep(exact.RoundTo(c.Total_increment, c.Rounding))
+	bill.Rounding_adjustment = l.keep(bill.Total.Sub(exact))
+	if bill.Lines == nil {
+		bill.Lines = []Line{}
+	}
+	return bill, l.err
+}
+
+// ledger keeps the first error of the sums of a bill, so Price checks once
+// instead of after every step.
+type ledger struct {
+	err error
+}
+
+func (l *ledger) keep(m money.Money, err error) money.Money {
+	if l.err == nil {
+		l.err = err
+	}
+	return m
+}
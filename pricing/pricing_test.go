@@ -0,0 +1,137 @@
+package pricing
+
+import (
+	"errors"
+	"restaurant_management/money"
+	"testing"
+)
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
+func TestPrice(t *testing.T) {
+	config := Config{
+		Tax_rates:           map[string]float64{"drinks": 20},
+		Default_tax_rate:    10,
+		Service_charge_rate: 0,
+		Rounding:            RoundHalfUp,
+		Currency:            "USD",
+	}
+	tests := []struct {
+		name      string
+		config    func(Config) Config
+		items     []Item
+		discounts []Discount
+		discount  int64
+		lines     []int64 // discount of each line
+		tax       int64
+		service   int64
+		total     int64
+	}{
+		{
+			name:  "tax by category",
+			items: []Item{{Category: "main", Unit_price: usd(1000), Quantity: 2}, {Category: "drinks", Unit_price: usd(250), Quantity: 1}},
+			lines: []int64{0, 0},
+			tax:   250,
+			total: 2500,
+		},
+		{
+			name:      "percentage discount spread by subtotal",
+			items:     []Item{{Category: "main", Unit_price: usd(3000), Quantity: 1}, {Category: "main", Unit_price: usd(1000), Quantity: 1}},
+			discounts: []Discount{{Type: DiscountPercentage, Value: 10}},
+			discount:  400,
+			lines:     []int64{300, 100},
+			tax:       360,
+			total:     3960,
+		},
+		{
+			name:      "fixed discount capped at the subtotal",
+			items:     []Item{{Category: "main", Unit_price: usd(500), Quantity: 1}},
+			discounts: []Discount{{Type: DiscountFixed, Value: 8}},
+			discount:  500,
+			lines:     []int64{500},
+			total:     0,
+		},
+		{
+			name: "no line's share goes below zero or past its subtotal",
+			items: []Item{
+				{Category: "main", Unit_price: usd(3), Quantity: 1},
+				{Category: "main", Unit_price: usd(3), Quantity: 1},
+				{Category: "main", Unit_price: usd(3), Quantity: 1},
+				{Category: "main", Unit_price: usd(1), Quantity: 1},
+			},
+			discounts: []Discount{{Type: DiscountFixed, Value: 0.05}},
+			discount:  5,
+			lines:     []int64{2, 2, 1, 0},
+			tax:       0,
+			total:     5,
+		},
+		{
+			name:    "service charge on the discounted subtotal",
+			config:  func(c Config) Config { c.Service_charge_rate = 12.5; return c },
+			items:   []Item{{Category: "main", Unit_price: usd(2000), Quantity: 1}},
+			lines:   []int64{0},
+			tax:     200,
+			service: 250,
+			total:   2450,
+		},
+		{
+			name:   "total rounded to the cash increment",
+			config: func(c Config) Config { c.Total_increment = usd(5); return c },
+			items:  []Item{{Category: "main", Unit_price: usd(1001), Quantity: 1}},
+			lines:  []int64{0},
+			tax:    100,
+			total:  1100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config
+			if tt.config != nil {
+				c = tt.config(c)
+			}
+			bill, err := c.Price(tt.items, tt.discounts)
+			if err != nil {
+				t.Fatalf("Price: %v", err)
+			}
+			if bill.Discount != usd(tt.discount) || bill.Tax != usd(tt.tax) || bill.Service_charge != usd(tt.service) || bill.Total != usd(tt.total) {
+				t.Errorf("discount %v, tax %v, service %v, total %v; want %d, %d, %d, %d",
+					bill.Discount, bill.Tax, bill.Service_charge, bill.Total, tt.discount, tt.tax, tt.service, tt.total)
+			}
+			var discounted int64
+			for i, line := range bill.Lines {
+				if line.Discount.Amount != tt.lines[i] {
+					t.Errorf("line %d discount = %v, want %d", i, line.Discount, tt.lines[i])
+				}
+				if line.Discount.Amount < 0 || line.Discount.Amount > line.Subtotal.Amount {
+					t.Errorf("line %d discount %v is outside 0..%v", i, line.Discount, line.Subtotal)
+				}
+				discounted += line.Discount.Amount
+			}
+			if discounted != bill.Discount.Amount {
+				t.Errorf("line discounts add up to %d, want %v", discounted, bill.Discount)
+			}
+		})
+	}
+}
+
+func TestPriceCurrencyMismatch(t *testing.T) {
+	config := Config{Rounding: RoundHalfUp, Currency: "USD"}
+	_, err := config.Price([]Item{{Unit_price: money.New(100, "EUR"), Quantity: 1}}, nil)
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("Price of a EUR item on a USD bill = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestPriceWithoutDecimals(t *testing.T) {
+	config := Config{Default_tax_rate: 10, Rounding: RoundHalfUp, Currency: "JPY"}
+	items := []Item{{Category: "main", Unit_price: money.New(1250, "JPY"), Quantity: 2}}
+	bill, err := config.Price(items, []Discount{{Type: DiscountFixed, Value: 100}})
+	if err != nil {
+		t.Fatalf("Price: %v", err)
+	}
+	if bill.Discount != money.New(100, "JPY") || bill.Tax != money.New(240, "JPY") || bill.Total != money.New(2640, "JPY") {
+		t.Errorf("discount %v, tax %v, total %v; want 100, 240 and 2640 yen", bill.Discount, bill.Tax, bill.Total)
+	}
+}
@@ -0,0 +1,57 @@
+package pricing
+
+import (
+	"errors"
+	"restaurant_management/money"
+	"sort"
+)
+
+// ErrBadSplit is returned by Split when there is nothing to split into, or
+// when the total or a weight is negative.
+var ErrBadSplit = errors.New("pricing: split needs at least one part and no negative amounts")
+
+// Split divides total into one part per weight, in proportion to the
+// weights, or equally when they are all zero. Parts are cut down to whole
+// minor units and the units this leaves over go one each to the parts that
+// lost the most, so the parts add up to total exactly and differ by at most
+// one minor unit from their exact share.
+func Split(total money.Money, weights []money.Money) ([]money.Money, error) {
+	if len(weights) == 0 || total.Amount < 0 {
+		return nil, ErrBadSplit
+	}
+	var whole int64
+	for _, weight := range weights {
+		if weight.Amount < 0 {
+			return nil, ErrBadSplit
+		}
+		whole += weight.Amount
+	}
+	amounts := make([]int64, len(weights))
+	for i, weight := range weights {
+		amounts[i] = weight.Amount
+		if whole == 0 {
+			amounts[i] = 1
+		}
+	}
+	if whole == 0 {
+		whole = int64(len(weights))
+	}
+
+	parts := make([]money.Money, len(weights))
+	remainders := make([]int64, len(weights))
+	left := total.Amount
+	for i, amount := range amounts {
+		parts[i] = money.New(total.Amount*amount/whole, total.Currency)
+		remainders[i] = total.Amount * amount % whole
+		left -= parts[i].Amount
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order[:left] {
+		parts[i].Amount++
+	}
+	return parts, nil
+}
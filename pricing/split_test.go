@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"reflect"
+	"restaurant_management/money"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{"evenly", 1200, []int64{1, 1, 1}, []int64{400, 400, 400}},
+		{"leftover cent goes to the first of equal shares", 100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{"leftover cents go to the shares that lost the most", 100, []int64{1, 2}, []int64{33, 67}},
+		{"in proportion", 1000, []int64{600, 300, 100}, []int64{600, 300, 100}},
+		{"proportion rounded", 1001, []int64{450, 750}, []int64{375, 626}},
+		{"zero weight gets nothing", 700, []int64{0, 5}, []int64{0, 700}},
+		{"all zero weights split equally", 5, []int64{0, 0}, []int64{3, 2}},
+		{"nothing to split", 0, []int64{3, 1}, []int64{0, 0}},
+		{"single share", 999, []int64{7}, []int64{999}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights := []money.Money{}
+			for _, weight := range tt.weights {
+				weights = append(weights, money.New(weight, ""))
+			}
+			parts, err := Split(money.New(tt.total, "USD"), weights)
+			if err != nil {
+				t.Fatalf("Split: %v", err)
+			}
+			got := []int64{}
+			var sum int64
+			for _, part := range parts {
+				if part.Currency != "USD" {
+					t.Errorf("part currency = %q, want USD", part.Currency)
+				}
+				got = append(got, part.Amount)
+				sum += part.Amount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parts = %v, want %v", got, tt.want)
+			}
+			if sum != tt.total {
+				t.Errorf("parts add up to %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestSplitRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []int64
+	}{
+		{"no shares", 100, nil},
+		{"negative weight", 100, []int64{3, -1}},
+		{"negative weights adding up to zero", 100, []int64{1, -1}},
+		{"negative total", -100, []int64{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights := []money.Money{}
+			for _, weight := range tt.weights {
+				weights = append(weights, money.New(weight, ""))
+			}
+			if _, err := Split(money.New(tt.total, "USD"), weights); err != ErrBadSplit {
+				t.Errorf("Split error = %v, want ErrBadSplit", err)
+			}
+		})
+	}
+}
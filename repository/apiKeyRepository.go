@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type APIKeyRepository interface {
+	List(ctx context.Context) ([]models.APIKey, error)
+	FindByID(ctx context.Context, keyId string) (models.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (models.APIKey, error)
+	Create(ctx context.Context, key models.APIKey) error
+	Revoke(ctx context.Context, keyId string, at time.Time) error
+	// Touch records that the key was used at time at.
+	Touch(ctx context.Context, keyId string, at time.Time) error
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+)
+
+type AuditEventRepository interface {
+	// List returns events newest first.
+	List(ctx context.Context, page Page) ([]models.AuditEvent, error)
+	Create(ctx context.Context, event models.AuditEvent) error
+}
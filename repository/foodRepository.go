@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+)
+
+type FoodRepository interface {
+	// List returns one page of foods together with the total number of foods.
+	List(ctx context.Context, page Page) (foods []models.Food, total int64, err error)
+	FindByID(ctx context.Context, foodId string) (models.Food, error)
+	Create(ctx context.Context, food models.Food) error
+	// Update replaces the stored food with the same Food_id.
+	Update(ctx context.Context, food models.Food) error
+}
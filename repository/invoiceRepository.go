@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+)
+
+type InvoiceRepository interface {
+	List(ctx context.Context) ([]models.Invoice, error)
+	FindByID(ctx context.Context, invoiceId string) (models.Invoice, error)
+	FindByOrderID(ctx context.Context, orderId string) (models.Invoice, error)
+	// Create stores invoice with payment, the pending share of the whole
+	// bill, and moves its order by change, to billed, in one transaction.
+	// The order must still be in change.From and have no invoice yet;
+	// otherwise nothing is written and it returns ErrConflict.
+	Create(ctx context.Context, invoice models.Invoice, payment models.Payment, change models.OrderStatusChange) error
+	// Update replaces the stored invoice with the same Invoice_id.
+	Update(ctx context.Context, invoice models.Invoice) error
+}
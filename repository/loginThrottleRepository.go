@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type LoginThrottleRepository interface {
+	// Find returns the counter for key, or ErrNotFound if it has none.
+	Find(ctx context.Context, key string) (models.LoginThrottle, error)
+	// RecordFailure atomically counts a failed login at time at and returns
+	// the updated counter. Failures recorded before since are forgotten and
+	// the count starts over.
+	RecordFailure(ctx context.Context, key string, at time.Time, since time.Time) (models.LoginThrottle, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Clear drops the counter and any lock on key.
+	Clear(ctx context.Context, key string) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+)
+
+type MenuRepository interface {
+	List(ctx context.Context) ([]models.Menu, error)
+	FindByID(ctx context.Context, menuId string) (models.Menu, error)
+	Create(ctx context.Context, menu models.Menu) error
+	// Update replaces the stored menu with the same Menu_id.
+	Update(ctx context.Context, menu models.Menu) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+)
+
+type NoteRepository interface {
+	List(ctx context.Context) ([]models.Note, error)
+	FindByID(ctx context.Context, noteId string) (models.Note, error)
+	Create(ctx context.Context, note models.Note) error
+	// Update replaces the stored note with the same Note_id.
+	Update(ctx context.Context, note models.Note) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/views"
+)
+
+type OrderItemRepository interface {
+	List(ctx context.Context) ([]models.OrderItem, error)
+	FindByID(ctx context.Context, orderItemId string) (models.OrderItem, error)
+	// ListByOrder returns the items of the order orderId, oldest first.
+	ListByOrder(ctx context.Context, orderId string) ([]models.OrderItem, error)
+	CreateMany(ctx context.Context, orderItems []models.OrderItem) error
+	// Update replaces the stored order item with the same Order_item_id,
+	// provided its order is still in models.ItemEditableOrderStatuses.
+	// Otherwise it returns ErrConflict. The item stays on its order; items
+	// change orders via OrderRepository.MoveItems.
+	Update(ctx context.Context, orderItem models.OrderItem) error
+	// ItemsByOrder joins the items of an order with their food, order and
+	// table, grouped per order with the amount due.
+	ItemsByOrder(ctx context.Context, orderId string) ([]views.OrderItemsView, error)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type OrderRepository interface {
+	List(ctx context.Context) ([]models.Order, error)
+	FindByID(ctx context.Context, orderId string) (models.Order, error)
+	// ListAtTable returns the orders at tableId in one of statuses, oldest
+	// first. Orders stored before statuses existed count as open.
+	ListAtTable(ctx context.Context, tableId string, statuses []string) ([]models.Order, error)
+	// Create stores order together with orderItems, which belong to it, in
+	// one transaction.
+	Create(ctx context.Context, order models.Order, orderItems ...models.OrderItem) error
+	// Update replaces the stored order with the same Order_id. Status and
+	// Status_history are left untouched; they only change via UpdateStatus.
+	Update(ctx context.Context, order models.Order) error
+	// UpdateStatus moves the order to change.To and appends change to its
+	// history, provided its status is still change.From. Otherwise it
+	// returns ErrConflict. Moving it to cancelled or voided also voids its
+	// open kitchen tickets, in the same transaction.
+	UpdateStatus(ctx context.Context, orderId string, change models.OrderStatusChange) error
+	// SendToKitchen moves the order by change like UpdateStatus and stores
+	// tickets, the kitchen tickets of its items, in the same transaction.
+	SendToKitchen(ctx context.Context, orderId string, change models.OrderStatusChange, tickets []models.Ticket) error
+	// Transfer moves the order to tableId, provided it is still editable.
+	// Otherwise it returns ErrConflict.
+	//
+	// Transfer, MoveItems, SplitOff and Merge point the kitchen tickets of
+	// the items they move at the order and table the items end up on.
+	Transfer(ctx context.Context, orderId string, tableId string, at time.Time) error
+	// MoveItems moves the order items orderItemIds from order from to order
+	// to in one transaction. Both orders must still be editable and every
+	// item still on from; otherwise nothing moves and it returns ErrConflict.
+	MoveItems(ctx context.Context, from string, to string, orderItemIds []string, at time.Time) error
+	// SplitOff stores the new order to and moves the order items
+	// orderItemIds from order from onto it in one transaction. Order from
+	// must still be editable and every item still on it; otherwise nothing
+	// changes and it returns ErrConflict.
+	SplitOff(ctx context.Context, from string, to models.Order, orderItemIds []string, at time.Time) error
+	// Merge moves every item of the orders from to order into, moves into
+	// to tableId and closes every order of from as merged, recording change
+	// with From set to the status it had, in one transaction. Every order
+	// must still be editable; otherwise nothing changes and it returns
+	// ErrConflict.
+	Merge(ctx context.Context, from []string, into string, tableId string, change models.OrderStatusChange) error
+}
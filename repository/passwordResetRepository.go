@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type PasswordResetRepository interface {
+	FindByTokenHash(ctx context.Context, tokenHash string) (models.PasswordReset, error)
+	Create(ctx context.Context, reset models.PasswordReset) error
+	// MarkUsed records that the reset was carried out. It returns ErrConflict
+	// if it already had been.
+	MarkUsed(ctx context.Context, resetId string, at time.Time) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type PaymentRepository interface {
+	// ListByInvoice returns the payments of an invoice, voided ones
+	// included, oldest first.
+	ListByInvoice(ctx context.Context, invoiceId string) ([]models.Payment, error)
+	FindByID(ctx context.Context, paymentId string) (models.Payment, error)
+	// Split voids the pending payments of invoiceId and stores payments in
+	// their place in one transaction, provided the invoice still has paid
+	// payments. Otherwise nothing changes and it returns ErrConflict.
+	Split(ctx context.Context, invoiceId string, paid int, payments []models.Payment, at time.Time) error
+	// Pay marks a pending payment paid by method at change.Changed_at,
+	// provided its order is still in change.From. When that leaves no
+	// pending payment on the invoice, the bill is settled and the order
+	// moves on by change in the same transaction. It returns ErrConflict if
+	// the payment is no longer pending or its order has moved on.
+	Pay(ctx context.Context, paymentId string, method string, change models.OrderStatusChange) error
+}
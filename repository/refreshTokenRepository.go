@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type RefreshTokenRepository interface {
+	FindByID(ctx context.Context, tokenId string) (models.RefreshToken, error)
+	Create(ctx context.Context, token models.RefreshToken) error
+	// MarkUsed records that the token was exchanged. It returns ErrConflict
+	// if the token had already been used, which means it was replayed.
+	MarkUsed(ctx context.Context, tokenId string, at time.Time) error
+	// RevokeFamily revokes every token of the family that is not revoked yet.
+	RevokeFamily(ctx context.Context, familyId string, at time.Time) error
+	// RevokeUser revokes every token of the user that is not revoked yet.
+	RevokeUser(ctx context.Context, userId string, at time.Time) error
+}
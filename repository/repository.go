@@ -0,0 +1,39 @@
+package repository
+
+import "errors"
+
+// ErrNotFound is returned by every backend when a lookup matches no record.
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when a conditional write finds the record was
+// changed since it was read.
+var ErrConflict = errors.New("record was modified concurrently")
+
+// Page selects a window of a listing. A zero Limit means no limit.
+type Page struct {
+	Skip  int64
+	Limit int64
+}
+
+// Store bundles the repositories a backend provides so handlers can be
+// wired against a single value.
+type Store struct {
+	Foods          FoodRepository
+	Menus          MenuRepository
+	Orders         OrderRepository
+	OrderItems     OrderItemRepository
+	Tables         TableRepository
+	Users          UserRepository
+	Notes          NoteRepository
+	Invoices       InvoiceRepository
+	Tickets        TicketRepository
+	RefreshTokens  RefreshTokenRepository
+	PasswordResets PasswordResetRepository
+	LoginThrottles LoginThrottleRepository
+	AuditEvents    AuditEventRepository
+	APIKeys        APIKeyRepository
+	Tenants        TenantRepository
+	Reservations   ReservationRepository
+	Waitlist       WaitlistRepository
+	Payments       PaymentRepository
+}
@@ -0,0 +1,546 @@
+// Package repositorytest checks that a storage backend behaves the way the
+// repository interfaces document, so every backend can be run against the
+// same cases.
+package repositorytest
+
+import (
+	"context"
+	"reflect"
+	"restaurant_management/models"
+	"restaurant_management/money"
+	"restaurant_management/repository"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Run runs every case against stores made by open, a fresh empty store for
+// each case.
+func Run(t *testing.T, open func(t *testing.T) *repository.Store) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, store *repository.Store)
+	}{
+		{"FoodPages", testFoodPages},
+		{"Lookups", testLookups},
+		{"OrderWithItems", testOrderWithItems},
+		{"OrderStatus", testOrderStatus},
+		{"OrderItemEdits", testOrderItemEdits},
+		{"MoveItems", testMoveItems},
+		{"KitchenTickets", testKitchenTickets},
+		{"TOTPStep", testTOTPStep},
+		{"ReservationClashes", testReservationClashes},
+		{"ReservationUpdateClashes", testReservationUpdateClashes},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, open(t))
+		})
+	}
+}
+
+var at = time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC)
+
+func newID() string {
+	return primitive.NewObjectID().Hex()
+}
+
+// fixture is a table and a food on a menu, what every order needs.
+type fixture struct {
+	menu  models.Menu
+	food  models.Food
+	table models.Table
+}
+
+func seed(t *testing.T, ctx context.Context, store *repository.Store) fixture {
+	t.Helper()
+	var f fixture
+	f.menu = models.Menu{ID: primitive.NewObjectID(), Name: "Dinner", Category: "main", Created_at: at, Updated_at: at}
+	f.menu.Menu_id = f.menu.ID.Hex()
+	if err := store.Menus.Create(ctx, f.menu); err != nil {
+		t.Fatalf("Menus.Create: %v", err)
+	}
+	f.food = newFood(f.menu.Menu_id, "Pasta", 350)
+	if err := store.Foods.Create(ctx, f.food); err != nil {
+		t.Fatalf("Foods.Create: %v", err)
+	}
+	guests, number := 4, 7
+	f.table = models.Table{ID: primitive.NewObjectID(), Number_of_quests: &guests, Table_number: &number, Created_at: at, Updated_at: at}
+	f.table.Table_id = f.table.ID.Hex()
+	if err := store.Tables.Create(ctx, f.table); err != nil {
+		t.Fatalf("Tables.Create: %v", err)
+	}
+	return f
+}
+
+func newFood(menuId string, name string, price int64) models.Food {
+	image := "pasta.png"
+	amount := money.New(price, "USD")
+	food := models.Food{
+		ID:         primitive.NewObjectID(),
+		Name:       &name,
+		Price:      &amount,
+		Food_image: &image,
+		Menu_id:    &menuId,
+		Created_at: at,
+		Update_at:  at,
+	}
+	food.Food_id = food.ID.Hex()
+	return food
+}
+
+func newOrder(tableId string) models.Order {
+	order := models.Order{
+		ID:         primitive.NewObjectID(),
+		Order_date: at,
+		Created_at: at,
+		Updated_at: at,
+		Table_id:   &tableId,
+		Status:     models.OrderStatusOpen,
+	}
+	order.Order_id = order.ID.Hex()
+	return order
+}
+
+func newItem(orderId string, foodId string, quantity int) models.OrderItem {
+	item := models.OrderItem{
+		ID:         primitive.NewObjectID(),
+		Quantity:   &quantity,
+		Food_id:    &foodId,
+		Order_id:   orderId,
+		Created_at: at,
+		Updated_at: at,
+	}
+	item.Order_item_id = item.ID.Hex()
+	return item
+}
+
+// placeOrder stores an open order at the fixture's table with one item per
+// quantity.
+func placeOrder(t *testing.T, ctx context.Context, store *repository.Store, f fixture, quantities ...int) (models.Order, []models.OrderItem) {
+	t.Helper()
+	order := newOrder(f.table.Table_id)
+	items := []models.OrderItem{}
+	for _, quantity := range quantities {
+		items = append(items, newItem(order.Order_id, f.food.Food_id, quantity))
+	}
+	if err := store.Orders.Create(ctx, order, items...); err != nil {
+		t.Fatalf("Orders.Create: %v", err)
+	}
+	return order, items
+}
+
+func testFoodPages(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	names := []string{f.food.Food_id}
+	for _, name := range []string{"Soup", "Salad"} {
+		food := newFood(f.menu.Menu_id, name, 500)
+		if err := store.Foods.Create(ctx, food); err != nil {
+			t.Fatalf("Foods.Create: %v", err)
+		}
+		names = append(names, food.Food_id)
+	}
+
+	tests := []struct {
+		name string
+		page repository.Page
+		want []string
+	}{
+		{"everything", repository.Page{}, names},
+		{"first page", repository.Page{Limit: 2}, names[:2]},
+		{"second page", repository.Page{Skip: 2, Limit: 2}, names[2:]},
+		{"past the end", repository.Page{Skip: 5, Limit: 2}, []string{}},
+	}
+	for _, tt := range tests {
+		foods, total, err := store.Foods.List(ctx, tt.page)
+		if err != nil {
+			t.Fatalf("%s: Foods.List: %v", tt.name, err)
+		}
+		if total != int64(len(names)) {
+			t.Errorf("%s: total = %d, want %d", tt.name, total, len(names))
+		}
+		got := []string{}
+		for _, food := range foods {
+			got = append(got, food.Food_id)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: foods = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testLookups(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	food, err := store.Foods.FindByID(ctx, f.food.Food_id)
+	if err != nil {
+		t.Fatalf("Foods.FindByID: %v", err)
+	}
+	if *food.Name != *f.food.Name || *food.Price != *f.food.Price {
+		t.Errorf("Foods.FindByID = %s at %v, want %s at %v", *food.Name, *food.Price, *f.food.Name, *f.food.Price)
+	}
+
+	missing := newID()
+	if _, err := store.Foods.FindByID(ctx, missing); err != repository.ErrNotFound {
+		t.Errorf("Foods.FindByID of a missing food = %v, want ErrNotFound", err)
+	}
+	if err := store.Foods.Update(ctx, newFood(f.menu.Menu_id, "Ghost", 100)); err != repository.ErrNotFound {
+		t.Errorf("Foods.Update of a missing food = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Orders.FindByID(ctx, missing); err != repository.ErrNotFound {
+		t.Errorf("Orders.FindByID of a missing order = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Tables.FindByID(ctx, missing); err != repository.ErrNotFound {
+		t.Errorf("Tables.FindByID of a missing table = %v, want ErrNotFound", err)
+	}
+}
+
+func testOrderWithItems(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	order, items := placeOrder(t, ctx, store, f, 2, 1)
+
+	stored, err := store.Orders.FindByID(ctx, order.Order_id)
+	if err != nil {
+		t.Fatalf("Orders.FindByID: %v", err)
+	}
+	if stored.Status != models.OrderStatusOpen || *stored.Table_id != f.table.Table_id {
+		t.Errorf("stored order is %q at %s, want open at %s", stored.Status, *stored.Table_id, f.table.Table_id)
+	}
+
+	groups, err := store.OrderItems.ItemsByOrder(ctx, order.Order_id)
+	if err != nil {
+		t.Fatalf("ItemsByOrder: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("ItemsByOrder returned %d groups, want 1", len(groups))
+	}
+	group := groups[0]
+	if group.ID.Order_id != order.Order_id || group.ID.Table_id != f.table.Table_id || *group.Table_number != 7 {
+		t.Errorf("group = %+v, want order %s at table 7", group.ID, order.Order_id)
+	}
+	if group.Payment_due != money.New(1050, "USD") {
+		t.Errorf("payment due = %v, want USD 10.50", group.Payment_due)
+	}
+	if len(group.Order_items) != len(items) {
+		t.Fatalf("group has %d lines, want %d", len(group.Order_items), len(items))
+	}
+	for i, line := range group.Order_items {
+		if line.Order_item_id != items[i].Order_item_id || line.Quantity != *items[i].Quantity {
+			t.Errorf("line %d = %s x%d, want %s x%d", i, line.Order_item_id, line.Quantity, items[i].Order_item_id, *items[i].Quantity)
+		}
+		if *line.Price != *f.food.Price || line.Menu_category != f.menu.Category || *line.Food_name != *f.food.Name {
+			t.Errorf("line %d = %s (%s) at %v, want %s (%s) at %v", i, *line.Food_name, line.Menu_category, *line.Price,
+				*f.food.Name, f.menu.Category, *f.food.Price)
+		}
+	}
+
+	empty, err := store.OrderItems.ItemsByOrder(ctx, newID())
+	if err != nil || len(empty) != 0 {
+		t.Errorf("ItemsByOrder of an unknown order = %v, %v; want no groups", empty, err)
+	}
+}
+
+func testOrderStatus(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	order, _ := placeOrder(t, ctx, store, f, 1)
+
+	send := models.OrderStatusChange{From: models.OrderStatusOpen, To: models.OrderStatusSentToKitchen, Changed_by: "u", Changed_at: at}
+	if err := store.Orders.UpdateStatus(ctx, order.Order_id, send); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if err := store.Orders.UpdateStatus(ctx, order.Order_id, send); err != repository.ErrConflict {
+		t.Errorf("UpdateStatus from a status the order left = %v, want ErrConflict", err)
+	}
+	if err := store.Orders.UpdateStatus(ctx, newID(), send); err != repository.ErrNotFound {
+		t.Errorf("UpdateStatus of a missing order = %v, want ErrNotFound", err)
+	}
+
+	stored, err := store.Orders.FindByID(ctx, order.Order_id)
+	if err != nil {
+		t.Fatalf("Orders.FindByID: %v", err)
+	}
+	if stored.Status != models.OrderStatusSentToKitchen {
+		t.Errorf("status = %q, want %q", stored.Status, models.OrderStatusSentToKitchen)
+	}
+	last := stored.Status_history[len(stored.Status_history)-1]
+	if last.From != send.From || last.To != send.To || last.Changed_by != send.Changed_by || !last.Changed_at.Equal(at) {
+		t.Errorf("last history entry = %+v, want %+v", last, send)
+	}
+
+	open, err := store.Orders.ListAtTable(ctx, f.table.Table_id, []string{models.OrderStatusOpen})
+	if err != nil || len(open) != 0 {
+		t.Errorf("ListAtTable open = %d orders, %v; want none", len(open), err)
+	}
+	sent, err := store.Orders.ListAtTable(ctx, f.table.Table_id, models.EditableOrderStatuses)
+	if err != nil || len(sent) != 1 || sent[0].Order_id != order.Order_id {
+		t.Errorf("ListAtTable editable = %d orders, %v; want the order", len(sent), err)
+	}
+}
+
+func testOrderItemEdits(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	order, items := placeOrder(t, ctx, store, f, 1)
+
+	item := items[0]
+	quantity := 3
+	item.Quantity = &quantity
+	if err := store.OrderItems.Update(ctx, item); err != nil {
+		t.Fatalf("OrderItems.Update of an open order: %v", err)
+	}
+	stored, err := store.OrderItems.FindByID(ctx, item.Order_item_id)
+	if err != nil {
+		t.Fatalf("OrderItems.FindByID: %v", err)
+	}
+	if *stored.Quantity != 3 {
+		t.Errorf("quantity = %d, want 3", *stored.Quantity)
+	}
+
+	for _, step := range []string{models.OrderStatusSentToKitchen, models.OrderStatusServed} {
+		current, _ := store.Orders.FindByID(ctx, order.Order_id)
+		change := models.OrderStatusChange{From: current.Status, To: step, Changed_at: at}
+		if err := store.Orders.UpdateStatus(ctx, order.Order_id, change); err != nil {
+			t.Fatalf("UpdateStatus to %s: %v", step, err)
+		}
+	}
+	quantity = 5
+	if err := store.OrderItems.Update(ctx, item); err != repository.ErrConflict {
+		t.Errorf("OrderItems.Update of a served order = %v, want ErrConflict", err)
+	}
+}
+
+func testMoveItems(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	from, items := placeOrder(t, ctx, store, f, 1, 2)
+	to, _ := placeOrder(t, ctx, store, f, 1)
+
+	if err := store.Orders.MoveItems(ctx, from.Order_id, to.Order_id, []string{items[0].Order_item_id, newID()}, at); err != repository.ErrConflict {
+		t.Errorf("MoveItems with an item not on the order = %v, want ErrConflict", err)
+	}
+	moved, err := store.OrderItems.FindByID(ctx, items[0].Order_item_id)
+	if err != nil || moved.Order_id != from.Order_id {
+		t.Errorf("a refused move moved the item to %s (%v)", moved.Order_id, err)
+	}
+
+	if err := store.Orders.MoveItems(ctx, from.Order_id, to.Order_id, []string{items[0].Order_item_id}, at); err != nil {
+		t.Fatalf("MoveItems: %v", err)
+	}
+	moved, err = store.OrderItems.FindByID(ctx, items[0].Order_item_id)
+	if err != nil || moved.Order_id != to.Order_id {
+		t.Errorf("moved item is on %s (%v), want %s", moved.Order_id, err, to.Order_id)
+	}
+	groups, err := store.OrderItems.ItemsByOrder(ctx, to.Order_id)
+	if err != nil || len(groups) != 1 || len(groups[0].Order_items) != 2 {
+		t.Errorf("target order has %v, %v; want 2 items", groups, err)
+	}
+}
+
+func testKitchenTickets(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	from, items := placeOrder(t, ctx, store, f, 1, 2)
+	to, _ := placeOrder(t, ctx, store, f, 1)
+
+	tickets := []models.Ticket{}
+	for _, item := range items {
+		ticket := models.Ticket{
+			ID:            primitive.NewObjectID(),
+			Order_id:      from.Order_id,
+			Order_item_id: item.Order_item_id,
+			Table_number:  f.table.Table_number,
+			Station:       models.StationKitchen,
+			Food_name:     f.food.Name,
+			Quantity:      *item.Quantity,
+			Status:        models.TicketStatusQueued,
+			Created_at:    at,
+			Updated_at:    at,
+		}
+		ticket.Ticket_id = ticket.ID.Hex()
+		tickets = append(tickets, ticket)
+	}
+	send := models.OrderStatusChange{From: models.OrderStatusOpen, To: models.OrderStatusSentToKitchen, Changed_at: at}
+	if err := store.Orders.SendToKitchen(ctx, from.Order_id, send, tickets); err != nil {
+		t.Fatalf("SendToKitchen: %v", err)
+	}
+	if err := store.Orders.SendToKitchen(ctx, from.Order_id, send, tickets); err != repository.ErrConflict {
+		t.Errorf("SendToKitchen of an order already sent = %v, want ErrConflict", err)
+	}
+	queued, err := store.Tickets.ListByOrder(ctx, from.Order_id)
+	if err != nil || len(queued) != 2 {
+		t.Fatalf("Tickets.ListByOrder = %d tickets, %v; want 2", len(queued), err)
+	}
+
+	later := at.Add(time.Minute)
+	if err := store.Orders.MoveItems(ctx, from.Order_id, to.Order_id, []string{items[0].Order_item_id}, later); err != nil {
+		t.Fatalf("MoveItems: %v", err)
+	}
+	moved, err := store.Tickets.FindByID(ctx, tickets[0].Ticket_id)
+	if err != nil || moved.Order_id != to.Order_id {
+		t.Errorf("ticket of the moved item is on %s (%v), want %s", moved.Order_id, err, to.Order_id)
+	}
+
+	void := models.OrderStatusChange{From: models.OrderStatusSentToKitchen, To: models.OrderStatusVoided, Changed_at: later}
+	if err := store.Orders.UpdateStatus(ctx, from.Order_id, void); err != nil {
+		t.Fatalf("UpdateStatus to voided: %v", err)
+	}
+	voided, err := store.Tickets.FindByID(ctx, tickets[1].Ticket_id)
+	if err != nil || voided.Status != models.TicketStatusVoided {
+		t.Errorf("ticket of the voided order is %q (%v), want %q", voided.Status, err, models.TicketStatusVoided)
+	}
+	if moved, _ := store.Tickets.FindByID(ctx, tickets[0].Ticket_id); moved.Status != models.TicketStatusQueued {
+		t.Errorf("ticket moved off the voided order is %q, want %q", moved.Status, models.TicketStatusQueued)
+	}
+}
+
+func testTOTPStep(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	first, last, password, email := "Ann", "Lee", "hash", "ann@example.com"
+	user := models.User{ID: primitive.NewObjectID(), First_name: &first, Last_name: &last, Password: &password, Email: &email, Role: models.RoleWaiter, Created_at: at, Updated_at: at}
+	user.User_id = user.ID.Hex()
+	if err := store.Users.Create(ctx, user); err != nil {
+		t.Fatalf("Users.Create: %v", err)
+	}
+
+	if err := store.Users.UseTOTPStep(ctx, user.User_id, 5, at); err != nil {
+		t.Fatalf("UseTOTPStep(5): %v", err)
+	}
+	for _, step := range []int64{5, 4} {
+		if err := store.Users.UseTOTPStep(ctx, user.User_id, step, at); err != repository.ErrConflict {
+			t.Errorf("UseTOTPStep(%d) after 5 = %v, want ErrConflict", step, err)
+		}
+	}
+	if err := store.Users.UseTOTPStep(ctx, user.User_id, 6, at); err != nil {
+		t.Errorf("UseTOTPStep(6): %v", err)
+	}
+	if err := store.Users.UseTOTPStep(ctx, newID(), 7, at); err != repository.ErrNotFound {
+		t.Errorf("UseTOTPStep of a missing user = %v, want ErrNotFound", err)
+	}
+	stored, err := store.Users.FindByID(ctx, user.User_id)
+	if err != nil || stored.Totp_last_step != 6 {
+		t.Errorf("last step = %d (%v), want 6", stored.Totp_last_step, err)
+	}
+
+	// of concurrent logins with the same code exactly one gets the step
+	results := make(chan error, 8)
+	for range cap(results) {
+		go func() { results <- store.Users.UseTOTPStep(ctx, user.User_id, 7, at) }()
+	}
+	succeeded := 0
+	for range cap(results) {
+		if err := <-results; err == nil {
+			succeeded++
+		} else if err != repository.ErrConflict {
+			t.Errorf("concurrent UseTOTPStep: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d concurrent logins used step 7, want 1", succeeded)
+	}
+}
+
+// newReservation books a party of two at tableId from at+from to at+to.
+func newReservation(tableId string, status string, from time.Duration, to time.Duration) models.Reservation {
+	name, phone, party := "Lee", "123", 2
+	start := at.Add(from)
+	reservation := models.Reservation{
+		ID:          primitive.NewObjectID(),
+		Name:        &name,
+		Phone:       &phone,
+		Party_size:  &party,
+		Reserved_at: &start,
+		Ends_at:     at.Add(to),
+		Table_id:    tableId,
+		Status:      status,
+		Created_at:  at,
+		Updated_at:  at,
+	}
+	reservation.Reservation_id = reservation.ID.Hex()
+	return reservation
+}
+
+// addTable stores another table with number.
+func addTable(t *testing.T, ctx context.Context, store *repository.Store, number int) models.Table {
+	t.Helper()
+	guests := 4
+	table := models.Table{ID: primitive.NewObjectID(), Number_of_quests: &guests, Table_number: &number, Created_at: at, Updated_at: at}
+	table.Table_id = table.ID.Hex()
+	if err := store.Tables.Create(ctx, table); err != nil {
+		t.Fatalf("Tables.Create: %v", err)
+	}
+	return table
+}
+
+func testReservationClashes(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	t1, t2 := f.table.Table_id, addTable(t, ctx, store, 8).Table_id
+	if err := store.Reservations.Create(ctx, newReservation(t1, models.ReservationBooked, 0, 2*time.Hour)); err != nil {
+		t.Fatalf("Reservations.Create: %v", err)
+	}
+
+	// the refused cases come first; the accepted ones do not clash with
+	// each other either
+	tests := []struct {
+		name        string
+		tenant      string
+		reservation models.Reservation
+		want        error
+	}{
+		{"same table and time", "", newReservation(t1, models.ReservationBooked, 0, 2*time.Hour), repository.ErrConflict},
+		{"overlapping the end", "", newReservation(t1, models.ReservationBooked, time.Hour, 3*time.Hour), repository.ErrConflict},
+		{"overlapping the start", "", newReservation(t1, models.ReservationBooked, -time.Hour, time.Hour), repository.ErrConflict},
+		{"around", "", newReservation(t1, models.ReservationBooked, -time.Hour, 3*time.Hour), repository.ErrConflict},
+		{"right after", "", newReservation(t1, models.ReservationBooked, 2*time.Hour, 4*time.Hour), nil},
+		{"right before", "", newReservation(t1, models.ReservationBooked, -2*time.Hour, 0), nil},
+		{"another table", "", newReservation(t2, models.ReservationBooked, 0, 2*time.Hour), nil},
+		{"cancelled", "", newReservation(t1, models.ReservationCancelled, 0, 2*time.Hour), nil},
+		{"another tenant", "other", newReservation(t1, models.ReservationBooked, 0, 2*time.Hour), nil},
+	}
+	for _, tt := range tests {
+		tenantCtx := ctx
+		if tt.tenant != "" {
+			tenantCtx = repository.WithTenant(ctx, tt.tenant)
+		}
+		if err := store.Reservations.Create(tenantCtx, tt.reservation); err != tt.want {
+			t.Errorf("%s: Reservations.Create = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func testReservationUpdateClashes(t *testing.T, store *repository.Store) {
+	ctx := context.Background()
+	f := seed(t, ctx, store)
+	t1, t2 := f.table.Table_id, addTable(t, ctx, store, 8).Table_id
+	a := newReservation(t1, models.ReservationBooked, 0, 2*time.Hour)
+	b := newReservation(t2, models.ReservationBooked, 0, 2*time.Hour)
+	for _, reservation := range []models.Reservation{a, b} {
+		if err := store.Reservations.Create(ctx, reservation); err != nil {
+			t.Fatalf("Reservations.Create: %v", err)
+		}
+	}
+
+	later, end := at.Add(time.Hour), at.Add(3*time.Hour)
+	a.Reserved_at, a.Ends_at = &later, end
+	if err := store.Reservations.Update(ctx, a); err != nil {
+		t.Errorf("moving a reservation over its own time = %v, want nil", err)
+	}
+	b.Table_id = t1
+	if err := store.Reservations.Update(ctx, b); err != repository.ErrConflict {
+		t.Errorf("moving onto a held table = %v, want ErrConflict", err)
+	}
+	a.Status = models.ReservationCancelled
+	if err := store.Reservations.Update(ctx, a); err != nil {
+		t.Fatalf("cancelling = %v, want nil", err)
+	}
+	if err := store.Reservations.Update(ctx, b); err != nil {
+		t.Errorf("moving onto a table freed by cancelling = %v, want nil", err)
+	}
+	if err := store.Reservations.Update(ctx, newReservation(t1, models.ReservationBooked, 0, time.Hour)); err != repository.ErrNotFound {
+		t.Errorf("updating a missing reservation = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type ReservationRepository interface {
+	// List returns the reservations, cancelled ones included, that overlap
+	// from..to, earliest first.
+	List(ctx context.Context, from time.Time, to time.Time) ([]models.Reservation, error)
+	FindByID(ctx context.Context, reservationId string) (models.Reservation, error)
+	// Create stores reservation. If it is booked and another booked
+	// reservation holds its table for an overlapping time, it returns
+	// ErrConflict instead.
+	Create(ctx context.Context, reservation models.Reservation) error
+	// Update replaces the stored reservation with the same Reservation_id,
+	// refusing overlaps with ErrConflict like Create. It returns ErrNotFound,
+	// before checking for overlaps, if there is no such reservation.
+	Update(ctx context.Context, reservation models.Reservation) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type TableRepository interface {
+	List(ctx context.Context) ([]models.Table, error)
+	FindByID(ctx context.Context, tableId string) (models.Table, error)
+	Create(ctx context.Context, table models.Table) error
+	// Update replaces the stored table with the same Table_id. Status is
+	// left untouched; it only changes via UpdateStatus.
+	Update(ctx context.Context, table models.Table) error
+	// UpdateStatus sets the status staff gave the table, or clears it with
+	// "".
+	UpdateStatus(ctx context.Context, tableId string, status string, at time.Time) error
+}
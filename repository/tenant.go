@@ -0,0 +1,27 @@
+package repository
+
+import "context"
+
+// DefaultTenant owns every record created before tenants existed, and
+// everything in a single-outlet deployment.
+const DefaultTenant = ""
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx acting for tenantId.
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// TenantID is the tenant ctx acts for, DefaultTenant when none was set.
+//
+// Repositories of records a tenant owns (foods, menus, orders, order items,
+// tables, notes, invoices, tickets, reservations, waitlist entries and API
+// keys) stamp it on everything they create and only read or change records
+// of that tenant. Users and audit events belong to the whole deployment:
+// their Tenant_id is set by the caller and only their listings are limited
+// to the tenant.
+func TenantID(ctx context.Context) string {
+	tenantId, _ := ctx.Value(tenantKey{}).(string)
+	return tenantId
+}
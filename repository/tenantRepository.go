@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+)
+
+type TenantRepository interface {
+	List(ctx context.Context) ([]models.Tenant, error)
+	FindByID(ctx context.Context, tenantId string) (models.Tenant, error)
+	Create(ctx context.Context, tenant models.Tenant) error
+	// Update replaces the stored tenant with the same Tenant_id.
+	Update(ctx context.Context, tenant models.Tenant) error
+}
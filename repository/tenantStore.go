@@ -0,0 +1,528 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"restaurant_management/views"
+	"sync"
+	"time"
+)
+
+// NewTenantStore keeps the foods, menus, orders, order items, tables, notes,
+// invoices, tickets, reservations and waitlist of every tenant in a database
+// of its own, opened with open the first time the tenant is served. The
+// default tenant stays in shared, which also keeps everything that belongs
+// to the whole deployment: users, tokens, audit events, API keys and the
+// tenants themselves.
+func NewTenantStore(shared *Store, open func(tenantId string) (*Store, error)) *Store {
+	stores := &tenantStores{shared: shared, open: open, stores: map[string]*Store{}}
+	store := *shared
+	store.Foods = foodRouter{stores}
+	store.Menus = menuRouter{stores}
+	store.Orders = orderRouter{stores}
+	store.OrderItems = orderItemRouter{stores}
+	store.Tables = tableRouter{stores}
+	store.Notes = noteRouter{stores}
+	store.Invoices = invoiceRouter{stores}
+	store.Tickets = ticketRouter{stores}
+	store.Reservations = reservationRouter{stores}
+	store.Waitlist = waitlistRouter{stores}
+	store.Payments = paymentRouter{stores}
+	return &store
+}
+
+type tenantStores struct {
+	shared *Store
+	open   func(tenantId string) (*Store, error)
+	mu     sync.Mutex
+	stores map[string]*Store
+}
+
+// store returns the store of the tenant ctx acts for.
+func (s *tenantStores) store(ctx context.Context) (*Store, error) {
+	tenantId := TenantID(ctx)
+	if tenantId == DefaultTenant {
+		return s.shared, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if store, ok := s.stores[tenantId]; ok {
+		return store, nil
+	}
+	store, err := s.open(tenantId)
+	if err != nil {
+		return nil, err
+	}
+	s.stores[tenantId] = store
+	return store, nil
+}
+
+type foodRouter struct{ stores *tenantStores }
+
+func (r foodRouter) List(ctx context.Context, page Page) ([]models.Food, int64, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return store.Foods.List(ctx, page)
+}
+
+func (r foodRouter) FindByID(ctx context.Context, foodId string) (models.Food, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Food{}, err
+	}
+	return store.Foods.FindByID(ctx, foodId)
+}
+
+func (r foodRouter) Create(ctx context.Context, food models.Food) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Foods.Create(ctx, food)
+}
+
+func (r foodRouter) Update(ctx context.Context, food models.Food) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Foods.Update(ctx, food)
+}
+
+type menuRouter struct{ stores *tenantStores }
+
+func (r menuRouter) List(ctx context.Context) ([]models.Menu, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Menus.List(ctx)
+}
+
+func (r menuRouter) FindByID(ctx context.Context, menuId string) (models.Menu, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Menu{}, err
+	}
+	return store.Menus.FindByID(ctx, menuId)
+}
+
+func (r menuRouter) Create(ctx context.Context, menu models.Menu) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Menus.Create(ctx, menu)
+}
+
+func (r menuRouter) Update(ctx context.Context, menu models.Menu) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Menus.Update(ctx, menu)
+}
+
+type orderRouter struct{ stores *tenantStores }
+
+func (r orderRouter) List(ctx context.Context) ([]models.Order, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Orders.List(ctx)
+}
+
+func (r orderRouter) FindByID(ctx context.Context, orderId string) (models.Order, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Order{}, err
+	}
+	return store.Orders.FindByID(ctx, orderId)
+}
+
+func (r orderRouter) ListAtTable(ctx context.Context, tableId string, statuses []string) ([]models.Order, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Orders.ListAtTable(ctx, tableId, statuses)
+}
+
+func (r orderRouter) Create(ctx context.Context, order models.Order, orderItems ...models.OrderItem) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.Create(ctx, order, orderItems...)
+}
+
+func (r orderRouter) Update(ctx context.Context, order models.Order) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.Update(ctx, order)
+}
+
+func (r orderRouter) UpdateStatus(ctx context.Context, orderId string, change models.OrderStatusChange) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.UpdateStatus(ctx, orderId, change)
+}
+
+func (r orderRouter) SendToKitchen(ctx context.Context, orderId string, change models.OrderStatusChange, tickets []models.Ticket) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.SendToKitchen(ctx, orderId, change, tickets)
+}
+
+func (r orderRouter) Transfer(ctx context.Context, orderId string, tableId string, at time.Time) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.Transfer(ctx, orderId, tableId, at)
+}
+
+func (r orderRouter) MoveItems(ctx context.Context, from string, to string, orderItemIds []string, at time.Time) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.MoveItems(ctx, from, to, orderItemIds, at)
+}
+
+func (r orderRouter) SplitOff(ctx context.Context, from string, to models.Order, orderItemIds []string, at time.Time) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.SplitOff(ctx, from, to, orderItemIds, at)
+}
+
+func (r orderRouter) Merge(ctx context.Context, from []string, into string, tableId string, change models.OrderStatusChange) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Orders.Merge(ctx, from, into, tableId, change)
+}
+
+type orderItemRouter struct{ stores *tenantStores }
+
+func (r orderItemRouter) List(ctx context.Context) ([]models.OrderItem, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.OrderItems.List(ctx)
+}
+
+func (r orderItemRouter) FindByID(ctx context.Context, orderItemId string) (models.OrderItem, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.OrderItem{}, err
+	}
+	return store.OrderItems.FindByID(ctx, orderItemId)
+}
+
+func (r orderItemRouter) ListByOrder(ctx context.Context, orderId string) ([]models.OrderItem, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.OrderItems.ListByOrder(ctx, orderId)
+}
+
+func (r orderItemRouter) CreateMany(ctx context.Context, orderItems []models.OrderItem) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.OrderItems.CreateMany(ctx, orderItems)
+}
+
+func (r orderItemRouter) Update(ctx context.Context, orderItem models.OrderItem) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.OrderItems.Update(ctx, orderItem)
+}
+
+func (r orderItemRouter) ItemsByOrder(ctx context.Context, orderId string) ([]views.OrderItemsView, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.OrderItems.ItemsByOrder(ctx, orderId)
+}
+
+type tableRouter struct{ stores *tenantStores }
+
+func (r tableRouter) List(ctx context.Context) ([]models.Table, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Tables.List(ctx)
+}
+
+func (r tableRouter) FindByID(ctx context.Context, tableId string) (models.Table, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Table{}, err
+	}
+	return store.Tables.FindByID(ctx, tableId)
+}
+
+func (r tableRouter) Create(ctx context.Context, table models.Table) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Tables.Create(ctx, table)
+}
+
+func (r tableRouter) Update(ctx context.Context, table models.Table) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Tables.Update(ctx, table)
+}
+
+func (r tableRouter) UpdateStatus(ctx context.Context, tableId string, status string, at time.Time) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Tables.UpdateStatus(ctx, tableId, status, at)
+}
+
+type noteRouter struct{ stores *tenantStores }
+
+func (r noteRouter) List(ctx context.Context) ([]models.Note, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Notes.List(ctx)
+}
+
+func (r noteRouter) FindByID(ctx context.Context, noteId string) (models.Note, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Note{}, err
+	}
+	return store.Notes.FindByID(ctx, noteId)
+}
+
+func (r noteRouter) Create(ctx context.Context, note models.Note) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Notes.Create(ctx, note)
+}
+
+func (r noteRouter) Update(ctx context.Context, note models.Note) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Notes.Update(ctx, note)
+}
+
+type invoiceRouter struct{ stores *tenantStores }
+
+func (r invoiceRouter) List(ctx context.Context) ([]models.Invoice, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Invoices.List(ctx)
+}
+
+func (r invoiceRouter) FindByID(ctx context.Context, invoiceId string) (models.Invoice, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	return store.Invoices.FindByID(ctx, invoiceId)
+}
+
+func (r invoiceRouter) FindByOrderID(ctx context.Context, orderId string) (models.Invoice, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	return store.Invoices.FindByOrderID(ctx, orderId)
+}
+
+func (r invoiceRouter) Create(ctx context.Context, invoice models.Invoice, payment models.Payment, change models.OrderStatusChange) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Invoices.Create(ctx, invoice, payment, change)
+}
+
+func (r invoiceRouter) Update(ctx context.Context, invoice models.Invoice) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Invoices.Update(ctx, invoice)
+}
+
+type ticketRouter struct{ stores *tenantStores }
+
+func (r ticketRouter) List(ctx context.Context, station string, statuses []string) ([]models.Ticket, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Tickets.List(ctx, station, statuses)
+}
+
+func (r ticketRouter) FindByID(ctx context.Context, ticketId string) (models.Ticket, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	return store.Tickets.FindByID(ctx, ticketId)
+}
+
+func (r ticketRouter) ListByOrder(ctx context.Context, orderId string) ([]models.Ticket, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Tickets.ListByOrder(ctx, orderId)
+}
+
+func (r ticketRouter) CreateMany(ctx context.Context, tickets []models.Ticket) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Tickets.CreateMany(ctx, tickets)
+}
+
+func (r ticketRouter) UpdateStatus(ctx context.Context, ticketId string, from string, to string, at time.Time) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Tickets.UpdateStatus(ctx, ticketId, from, to, at)
+}
+
+type reservationRouter struct{ stores *tenantStores }
+
+func (r reservationRouter) List(ctx context.Context, from time.Time, to time.Time) ([]models.Reservation, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Reservations.List(ctx, from, to)
+}
+
+func (r reservationRouter) FindByID(ctx context.Context, reservationId string) (models.Reservation, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	return store.Reservations.FindByID(ctx, reservationId)
+}
+
+func (r reservationRouter) Create(ctx context.Context, reservation models.Reservation) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Reservations.Create(ctx, reservation)
+}
+
+func (r reservationRouter) Update(ctx context.Context, reservation models.Reservation) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Reservations.Update(ctx, reservation)
+}
+
+type waitlistRouter struct{ stores *tenantStores }
+
+func (r waitlistRouter) List(ctx context.Context, statuses []string) ([]models.WaitlistEntry, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Waitlist.List(ctx, statuses)
+}
+
+func (r waitlistRouter) FindByID(ctx context.Context, entryId string) (models.WaitlistEntry, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.WaitlistEntry{}, err
+	}
+	return store.Waitlist.FindByID(ctx, entryId)
+}
+
+func (r waitlistRouter) Create(ctx context.Context, entry models.WaitlistEntry) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Waitlist.Create(ctx, entry)
+}
+
+func (r waitlistRouter) Update(ctx context.Context, entry models.WaitlistEntry, from string) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Waitlist.Update(ctx, entry, from)
+}
+
+type paymentRouter struct{ stores *tenantStores }
+
+func (r paymentRouter) ListByInvoice(ctx context.Context, invoiceId string) ([]models.Payment, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Payments.ListByInvoice(ctx, invoiceId)
+}
+
+func (r paymentRouter) FindByID(ctx context.Context, paymentId string) (models.Payment, error) {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	return store.Payments.FindByID(ctx, paymentId)
+}
+
+func (r paymentRouter) Split(ctx context.Context, invoiceId string, paid int, payments []models.Payment, at time.Time) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Payments.Split(ctx, invoiceId, paid, payments, at)
+}
+
+func (r paymentRouter) Pay(ctx context.Context, paymentId string, method string, change models.OrderStatusChange) error {
+	store, err := r.stores.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Payments.Pay(ctx, paymentId, method, change)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type TicketRepository interface {
+	// List returns the tickets of station, or of every station when it is
+	// empty, whose status is one of statuses, oldest first.
+	List(ctx context.Context, station string, statuses []string) ([]models.Ticket, error)
+	FindByID(ctx context.Context, ticketId string) (models.Ticket, error)
+	// ListByOrder returns every ticket of the order orderId, oldest first.
+	ListByOrder(ctx context.Context, orderId string) ([]models.Ticket, error)
+	CreateMany(ctx context.Context, tickets []models.Ticket) error
+	// UpdateStatus moves the ticket to status to, provided it is still at
+	// from. Otherwise it returns ErrConflict.
+	UpdateStatus(ctx context.Context, ticketId string, from string, to string, at time.Time) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+	"time"
+)
+
+type UserRepository interface {
+	List(ctx context.Context, page Page) ([]models.User, error)
+	FindByID(ctx context.Context, userId string) (models.User, error)
+	FindByEmail(ctx context.Context, email string) (models.User, error)
+	CountByEmail(ctx context.Context, email string) (int64, error)
+	CountByPhone(ctx context.Context, phone string) (int64, error)
+	Create(ctx context.Context, user models.User) error
+	// Update replaces the stored user with the same User_id.
+	Update(ctx context.Context, user models.User) error
+	UpdateTokens(ctx context.Context, userId string, token string, refreshToken string) error
+	// UseTOTPStep records that the user logged in with a TOTP code of time
+	// step step. It returns ErrConflict if that step or a later one was
+	// already used, so a code cannot log in twice even from concurrent
+	// requests.
+	UseTOTPStep(ctx context.Context, userId string, step int64, at time.Time) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"restaurant_management/models"
+)
+
+type WaitlistRepository interface {
+	// List returns the entries whose status is one of statuses, in the
+	// order they joined.
+	List(ctx context.Context, statuses []string) ([]models.WaitlistEntry, error)
+	FindByID(ctx context.Context, entryId string) (models.WaitlistEntry, error)
+	Create(ctx context.Context, entry models.WaitlistEntry) error
+	// Update replaces the stored entry with the same Entry_id, provided its
+	// status is still from. Otherwise it returns ErrConflict.
+	Update(ctx context.Context, entry models.WaitlistEntry, from string) error
+}
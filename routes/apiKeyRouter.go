@@ -0,0 +1,17 @@
+package routes
+
+import (
+	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func APIKeyRoutes(incomingRoutes *gin.Engine, store *repository.Store) {
+	admin := middleware.Authorize(models.RoleAdmin)
+	incomingRoutes.GET("/api-keys", admin, controller.GetAPIKeys(store))
+	incomingRoutes.POST("/api-keys", admin, controller.CreateAPIKey(store))
+	incomingRoutes.DELETE("/api-keys/:id", admin, controller.RevokeAPIKey(store))
+}
@@ -0,0 +1,301 @@
+package routes
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// send makes a GET request to path with headers and returns the response.
+func (s *server) send(path string, headers map[string]string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response := httptest.NewRecorder()
+	s.router.ServeHTTP(response, request)
+	return response
+}
+
+func TestAuthHeaders(t *testing.T) {
+	s := newServer(t)
+	s.addUser("admin@example.com", "secret1", models.RoleAdmin)
+	s.addUser("waiter@example.com", "secret1", models.RoleWaiter)
+	admin := s.login("admin@example.com", "secret1")
+	waiter := s.login("waiter@example.com", "secret1")
+
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		status    int
+		challenge string
+	}{
+		{"bearer token", map[string]string{"Authorization": "Bearer " + admin}, http.StatusOK, ""},
+		{"lower-case scheme", map[string]string{"Authorization": "bearer " + admin}, http.StatusOK, ""},
+		{"legacy token header", map[string]string{"token": admin}, http.StatusOK, ""},
+		{"no token", nil, http.StatusUnauthorized, `Bearer realm="restaurant_management"`},
+		{"malformed token", map[string]string{"Authorization": "Bearer nonsense"}, http.StatusUnauthorized, `error="invalid_token"`},
+		{"role not allowed", map[string]string{"Authorization": "Bearer " + waiter}, http.StatusForbidden, `error="insufficient_scope"`},
+	}
+	for _, tt := range tests {
+		response := s.send("/users", tt.headers)
+		if response.Code != tt.status {
+			t.Errorf("%s: GET /users answered %d, want %d", tt.name, response.Code, tt.status)
+		}
+		if challenge := response.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, tt.challenge) || (tt.challenge == "") != (challenge == "") {
+			t.Errorf("%s: WWW-Authenticate = %q, want it to contain %q", tt.name, challenge, tt.challenge)
+		}
+	}
+}
+
+func TestRefreshTokenIsNotAnAccessToken(t *testing.T) {
+	s := newServer(t)
+	s.addUser("admin@example.com", "secret1", models.RoleAdmin)
+	var answer struct {
+		Refresh_token string `json:"refresh_token"`
+	}
+	if status := s.call(http.MethodPost, "/users/login", "", gin.H{"email": "admin@example.com", "password": "secret1"}, &answer); status != http.StatusOK {
+		t.Fatalf("login answered %d", status)
+	}
+	if status := s.call(http.MethodGet, "/users", answer.Refresh_token, nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("GET /users with the refresh token answered %d, want 401", status)
+	}
+}
+
+// mailedToken returns the token in the last message sent to address: the
+// paragraph after the first blank line.
+func (s *server) mailedToken(address string) string {
+	s.t.Helper()
+	messages := s.outbox.to(address)
+	if len(messages) == 0 {
+		s.t.Fatalf("no mail was sent to %s", address)
+	}
+	paragraphs := strings.Split(messages[len(messages)-1].Body, "\n\n")
+	if len(paragraphs) < 2 {
+		s.t.Fatalf("mail to %s carries no token: %q", address, messages[len(messages)-1].Body)
+	}
+	return strings.TrimSpace(paragraphs[1])
+}
+
+func TestEmailVerification(t *testing.T) {
+	s := newServer(t)
+	signup := gin.H{"first_name": "Ann", "last_name": "Lee", "email": "ann@example.com", "password": "secret1", "phone": "123"}
+	userId := s.created("/users/signup", "", signup)
+	token := s.mailedToken("ann@example.com")
+	if user, _ := s.store.Users.FindByID(context.Background(), userId); user.Email_verified {
+		t.Fatal("a new account starts out verified")
+	}
+
+	if status := s.call(http.MethodPost, "/users/verify", "", gin.H{"token": "nonsense"}, nil); status != http.StatusBadRequest {
+		t.Errorf("verifying with a bad token answered %d, want 400", status)
+	}
+	if status := s.call(http.MethodPost, "/users/verify", "", gin.H{"token": token}, nil); status != http.StatusOK {
+		t.Fatalf("verifying answered %d", status)
+	}
+	user, err := s.store.Users.FindByID(context.Background(), userId)
+	if err != nil || !user.Email_verified {
+		t.Fatalf("account verified = %v (%v), want true", user.Email_verified, err)
+	}
+
+	// a token for an address the account no longer has is refused
+	moved := "ann@elsewhere.example.com"
+	user.Email = &moved
+	user.Email_verified = false
+	if err := s.store.Users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	if status := s.call(http.MethodPost, "/users/verify", "", gin.H{"token": token}, nil); status != http.StatusBadRequest {
+		t.Errorf("verifying the old address answered %d, want 400", status)
+	}
+
+	if status := s.call(http.MethodPost, "/users/verify/resend", "", gin.H{"email": "nobody@example.com"}, nil); status != http.StatusOK {
+		t.Errorf("resending to an unknown address answered %d, want 200", status)
+	}
+	if messages := s.outbox.to("nobody@example.com"); len(messages) != 0 {
+		t.Errorf("%d messages sent to an unknown address", len(messages))
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	s := newServer(t)
+	userId := s.addUser("ann@example.com", "secret1", models.RoleWaiter)
+
+	for _, address := range []string{"ann@example.com", "nobody@example.com"} {
+		if status := s.call(http.MethodPost, "/users/password/forgot", "", gin.H{"email": address}, nil); status != http.StatusOK {
+			t.Errorf("forgot password for %s answered %d, want 200", address, status)
+		}
+	}
+	if messages := s.outbox.to("nobody@example.com"); len(messages) != 0 {
+		t.Errorf("%d messages sent to an unknown address", len(messages))
+	}
+	token := s.mailedToken("ann@example.com")
+
+	if status := s.call(http.MethodPost, "/users/password/reset", "", gin.H{"token": "nonsense", "password": "newpass1"}, nil); status != http.StatusBadRequest {
+		t.Errorf("resetting with a bad token answered %d, want 400", status)
+	}
+	if status := s.call(http.MethodPost, "/users/password/reset", "", gin.H{"token": token, "password": "newpass1"}, nil); status != http.StatusOK {
+		t.Fatalf("resetting answered %d", status)
+	}
+	s.login("ann@example.com", "newpass1")
+	if status := s.call(http.MethodPost, "/users/password/reset", "", gin.H{"token": token, "password": "again12"}, nil); status != http.StatusBadRequest {
+		t.Errorf("reusing the reset token answered %d, want 400", status)
+	}
+
+	expired, expiredToken, err := helpers.NewPasswordReset(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.Expires_at = expired.Created_at.Add(-time.Minute)
+	if err := s.store.PasswordResets.Create(context.Background(), expired); err != nil {
+		t.Fatal(err)
+	}
+	if status := s.call(http.MethodPost, "/users/password/reset", "", gin.H{"token": expiredToken, "password": "again12"}, nil); status != http.StatusBadRequest {
+		t.Errorf("resetting with an expired token answered %d, want 400", status)
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	s := newServer(t)
+	s.addUser("admin@example.com", "secret1", models.RoleAdmin)
+	waiterId := s.addUser("waiter@example.com", "secret1", models.RoleWaiter)
+	admin := s.login("admin@example.com", "secret1")
+
+	var unknown, wrong gin.H
+	if status := s.call(http.MethodPost, "/users/login", "", gin.H{"email": "nobody@example.com", "password": "secret1"}, &unknown); status != http.StatusUnauthorized {
+		t.Errorf("login with an unknown email answered %d, want 401", status)
+	}
+	for i := 0; i < 3; i++ {
+		if status := s.call(http.MethodPost, "/users/login", "", gin.H{"email": "waiter@example.com", "password": "wrong12"}, &wrong); status != http.StatusUnauthorized {
+			t.Fatalf("wrong password %d answered %d, want 401", i+1, status)
+		}
+	}
+	if unknown["error"] != wrong["error"] {
+		t.Errorf("unknown email answered %v, wrong password %v; want the same", unknown, wrong)
+	}
+	if status := s.call(http.MethodPost, "/users/login", "", gin.H{"email": "waiter@example.com", "password": "secret1"}, nil); status != http.StatusTooManyRequests {
+		t.Errorf("login right after the third failure answered %d, want 429", status)
+	}
+
+	if status := s.call(http.MethodPost, "/users/"+waiterId+"/unlock", admin, nil, nil); status != http.StatusOK {
+		t.Fatalf("unlock answered %d", status)
+	}
+	s.login("waiter@example.com", "secret1")
+}
+
+func TestChangePasswordIsThrottled(t *testing.T) {
+	s := newServer(t)
+	userId := s.addUser("waiter@example.com", "secret1", models.RoleWaiter)
+	token := s.login("waiter@example.com", "secret1")
+	change := func(current string) int {
+		return s.call(http.MethodPost, "/users/"+userId+"/password", token, gin.H{"current_password": current, "new_password": "newpass1"}, nil)
+	}
+
+	for i := 0; i < 3; i++ {
+		if status := change("wrong12"); status != http.StatusForbidden {
+			t.Fatalf("wrong current password %d answered %d, want 403", i+1, status)
+		}
+	}
+	if status := change("secret1"); status != http.StatusTooManyRequests {
+		t.Errorf("changing the password right after the third failure answered %d, want 429", status)
+	}
+	if status := s.call(http.MethodPost, "/users/login", "", gin.H{"email": "waiter@example.com", "password": "secret1"}, nil); status != http.StatusTooManyRequests {
+		t.Errorf("login after the failed password changes answered %d, want 429", status)
+	}
+
+	if err := s.store.LoginThrottles.Clear(context.Background(), helpers.AccountThrottleKey("waiter@example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if status := change("secret1"); status != http.StatusOK {
+		t.Fatalf("changing the password answered %d", status)
+	}
+	s.login("waiter@example.com", "newpass1")
+}
+
+// totpCode computes the RFC 6238 code of secret for time step step, the way
+// an authenticator app would.
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestTOTPLogin(t *testing.T) {
+	s := newServer(t)
+	s.addUser("manager@example.com", "secret1", models.RoleManager)
+	token := s.login("manager@example.com", "secret1")
+
+	var enrollment struct {
+		Secret string `json:"secret"`
+	}
+	if status := s.call(http.MethodPost, "/users/totp/enroll", token, nil, &enrollment); status != http.StatusOK {
+		t.Fatalf("enroll answered %d", status)
+	}
+	step := time.Now().Unix() / 30
+	var confirmed struct {
+		Recovery_codes []string `json:"recovery_codes"`
+	}
+	if status := s.call(http.MethodPost, "/users/totp/confirm", token, gin.H{"code": totpCode(t, enrollment.Secret, step)}, &confirmed); status != http.StatusOK {
+		t.Fatalf("confirm answered %d", status)
+	}
+
+	mfaToken := func() string {
+		t.Helper()
+		var answer struct {
+			Mfa_required bool   `json:"mfa_required"`
+			Mfa_token    string `json:"mfa_token"`
+		}
+		if status := s.call(http.MethodPost, "/users/login", "", gin.H{"email": "manager@example.com", "password": "secret1"}, &answer); status != http.StatusOK || !answer.Mfa_required {
+			t.Fatalf("login answered %d %+v, want a second step", status, answer)
+		}
+		return answer.Mfa_token
+	}
+	second := func(body gin.H) int {
+		body["mfa_token"] = mfaToken()
+		return s.call(http.MethodPost, "/users/login/totp", "", body, nil)
+	}
+
+	// the code used to confirm cannot log in; the next step's can, once
+	if status := second(gin.H{"code": totpCode(t, enrollment.Secret, step)}); status != http.StatusUnauthorized {
+		t.Errorf("logging in with the confirmation code answered %d, want 401", status)
+	}
+	next := totpCode(t, enrollment.Secret, step+1)
+	if status := second(gin.H{"code": next}); status != http.StatusOK {
+		t.Fatalf("logging in with a fresh code answered %d", status)
+	}
+	if status := second(gin.H{"code": next}); status != http.StatusUnauthorized {
+		t.Errorf("replaying the code answered %d, want 401", status)
+	}
+
+	if err := s.store.LoginThrottles.Clear(context.Background(), helpers.AccountThrottleKey("manager@example.com")); err != nil {
+		t.Fatal(err)
+	}
+	recovery := confirmed.Recovery_codes[0]
+	if status := second(gin.H{"recovery_code": recovery}); status != http.StatusOK {
+		t.Fatalf("logging in with a recovery code answered %d", status)
+	}
+	if status := second(gin.H{"recovery_code": recovery}); status != http.StatusUnauthorized {
+		t.Errorf("reusing the recovery code answered %d, want 401", status)
+	}
+}
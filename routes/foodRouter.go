@@ -2,13 +2,17 @@ package routes
 
 import (
 	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func FoodRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/foods", controller.GetFoods())
-	incomingRoutes.GET("/foods/:id", controller.GetFood())
-	incomingRoutes.POST("/foods", controller.CreateFood())
-	incomingRoutes.PATCH("/foods/:id", controller.UpdateFood())
+func FoodRoutes(incomingRoutes *gin.Engine, store *repository.Store, prices pricing.Config) {
+	incomingRoutes.GET("/foods", controller.GetFoods(store))
+	incomingRoutes.GET("/foods/:id", controller.GetFood(store))
+	incomingRoutes.POST("/foods", middleware.Authorize(models.RoleManager), controller.CreateFood(store, prices))
+	incomingRoutes.PATCH("/foods/:id", middleware.Authorize(models.RoleManager), controller.UpdateFood(store, prices))
 }
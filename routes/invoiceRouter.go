@@ -0,0 +1,22 @@
+package routes
+
+import (
+	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InvoiceRoutes(incomingRoutes *gin.Engine, store *repository.Store, prices pricing.Config) {
+	billing := middleware.Authorize(models.RoleCashier, models.RoleManager)
+	incomingRoutes.GET("/invoices", billing, controller.GetInvoices(store))
+	incomingRoutes.GET("/invoices/:id", billing, controller.GetInvoice(store))
+	incomingRoutes.POST("/invoices", billing, controller.CreateInvoice(store, prices))
+	incomingRoutes.PATCH("/invoices/:id", billing, controller.UpdateInvoice(store))
+	incomingRoutes.POST("/invoices/:id/split", billing, controller.SplitInvoice(store))
+	incomingRoutes.GET("/invoices/:id/payments", billing, controller.GetInvoicePayments(store))
+	incomingRoutes.POST("/payments/:id/pay", billing, controller.PayPayment(store))
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	controller "restaurant_management/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+func JWKSRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/.well-known/jwks.json", controller.GetJWKS())
+}
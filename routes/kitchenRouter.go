@@ -0,0 +1,20 @@
+package routes
+
+import (
+	controller "restaurant_management/controller"
+	"restaurant_management/kitchen"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func KitchenRoutes(incomingRoutes *gin.Engine, store *repository.Store, tickets *kitchen.Hub) {
+	kitchenStaff := middleware.Authorize(models.RoleKitchen, models.RoleWaiter, models.RoleManager)
+	incomingRoutes.GET("/kitchen/tickets", kitchenStaff, controller.GetTickets(store))
+	incomingRoutes.GET("/kitchen/tickets/:id", kitchenStaff, controller.GetTicket(store))
+	incomingRoutes.POST("/kitchen/tickets/:id/bump", kitchenStaff, controller.BumpTicket(store, tickets))
+	incomingRoutes.POST("/kitchen/tickets/:id/recall", kitchenStaff, controller.RecallTicket(store, tickets))
+	incomingRoutes.GET("/kitchen/stream", kitchenStaff, controller.StreamTickets(tickets))
+}
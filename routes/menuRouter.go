@@ -2,13 +2,16 @@ package routes
 
 import (
 	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func MenuRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/menus", controller.GetMenus())
-	incomingRoutes.GET("/menus/:id", controller.GetMenu())
-	incomingRoutes.POST("/menus", controller.CreateMenu())
-	incomingRoutes.PATCH("/menus/:id", controller.UpdateMenu())
+func MenuRoutes(incomingRoutes *gin.Engine, store *repository.Store) {
+	incomingRoutes.GET("/menus", controller.GetMenus(store))
+	incomingRoutes.GET("/menus/:id", controller.GetMenu(store))
+	incomingRoutes.POST("/menus", middleware.Authorize(models.RoleManager), controller.CreateMenu(store))
+	incomingRoutes.PATCH("/menus/:id", middleware.Authorize(models.RoleManager), controller.UpdateMenu(store))
 }
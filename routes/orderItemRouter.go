@@ -2,14 +2,19 @@ package routes
 
 import (
 	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func OrderItemRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/orderItems", controller.GetOrderItems())
-	incomingRoutes.GET("/orderItems/:id", controller.GetOrderItem())
-	incomingRoutes.GET("/orderItems-order/:id", controller.GetOrderItemsByOrderId())
-	incomingRoutes.POST("/orderItems", controller.CreateOrderItem())
-	incomingRoutes.PATCH("/orderItems/:id", controller.UpdateOrderItem())
+func OrderItemRoutes(incomingRoutes *gin.Engine, store *repository.Store, prices pricing.Config) {
+	ordering := middleware.Authorize(models.RoleWaiter, models.RoleManager)
+	incomingRoutes.GET("/orderItems", controller.GetOrderItems(store))
+	incomingRoutes.GET("/orderItems/:id", controller.GetOrderItem(store))
+	incomingRoutes.GET("/orderItems-order/:id", controller.GetOrderItemsByOrderId(store, prices))
+	incomingRoutes.POST("/orderItems", ordering, controller.CreateOrderItem(store))
+	incomingRoutes.PATCH("/orderItems/:id", ordering, controller.UpdateOrderItem(store))
 }
@@ -2,13 +2,23 @@ package routes
 
 import (
 	controller "restaurant_management/controller"
+	"restaurant_management/kitchen"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func OrderRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/orders", controller.GetOrders())
-	incomingRoutes.GET("/orders/:id", controller.GetOrder())
-	incomingRoutes.POST("/orders", controller.CreateOrder())
-	incomingRoutes.PATCH("/orders/:id", controller.UpdateOrder())
+func OrderRoutes(incomingRoutes *gin.Engine, store *repository.Store, prices pricing.Config, tickets *kitchen.Hub) {
+	service := middleware.Authorize(models.RoleWaiter, models.RoleCashier, models.RoleManager)
+	incomingRoutes.GET("/orders", controller.GetOrders(store))
+	incomingRoutes.GET("/orders/:id", controller.GetOrder(store))
+	incomingRoutes.POST("/orders", service, controller.CreateOrder(store))
+	incomingRoutes.PATCH("/orders/:id", service, controller.UpdateOrder(store, tickets))
+	incomingRoutes.POST("/orders/:id/transfer", service, controller.TransferOrder(store, tickets))
+	incomingRoutes.POST("/orders/:id/items/move", service, controller.MoveOrderItems(store, tickets))
+	incomingRoutes.POST("/orders/:id/status", middleware.Authorize(models.RoleWaiter, models.RoleKitchen, models.RoleCashier, models.RoleManager), controller.UpdateOrderStatus(store, tickets))
+	incomingRoutes.POST("/orders/:id/invoice", middleware.Authorize(models.RoleCashier, models.RoleManager), controller.GenerateOrderInvoice(store, prices))
 }
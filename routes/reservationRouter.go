@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"restaurant_management/booking"
+	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AvailabilityRoutes are public so the website can offer booking times.
+func AvailabilityRoutes(incomingRoutes *gin.Engine, store *repository.Store, bookings booking.Config) {
+	incomingRoutes.GET("/availability", middleware.PublicTenant(store), controller.GetAvailability(store, bookings))
+}
+
+func ReservationRoutes(incomingRoutes *gin.Engine, store *repository.Store, bookings booking.Config) {
+	hosting := middleware.Authorize(models.RoleManager, models.RoleWaiter)
+	incomingRoutes.GET("/reservations", controller.GetReservations(store, bookings))
+	incomingRoutes.GET("/reservations/:id", controller.GetReservation(store))
+	incomingRoutes.POST("/reservations", hosting, controller.CreateReservation(store, bookings))
+	incomingRoutes.PATCH("/reservations/:id", hosting, controller.UpdateReservation(store, bookings))
+	incomingRoutes.POST("/reservations/:id/cancel", hosting, controller.CancelReservation(store))
+}
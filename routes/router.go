@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"net/http"
+	"restaurant_management/booking"
+	"restaurant_management/kitchen"
+	"restaurant_management/mail"
+	"restaurant_management/middleware"
+	"restaurant_management/notify"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewRouter builds the full API on top of store, pricing bills with prices,
+// booking tables by bookings, sending account mail through mailer and
+// telling waiting guests their table is ready through notifier. It opens no connections of its
+// own, so it can be served from httptest with the in-memory store.
+func NewRouter(store *repository.Store, prices pricing.Config, bookings booking.Config, mailer mail.Sender, notifier notify.Notifier) *gin.Engine {
+	router := gin.New()
+	tickets := kitchen.NewHub()
+
+	// default route
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"msg": "Server running successfully"})
+	})
+
+	router.Use(gin.Logger())
+	JWKSRoutes(router)
+	UserRoutes(router, store, mailer)
+	AvailabilityRoutes(router, store, bookings)
+	router.Use(middleware.Authentication(store))
+
+	FoodRoutes(router, store, prices)
+	MenuRoutes(router, store)
+	OrderRoutes(router, store, prices, tickets)
+	OrderItemRoutes(router, store, prices)
+	TableRoutes(router, store, tickets)
+	FloorRoutes(router, store, prices, bookings)
+	InvoiceRoutes(router, store, prices)
+	KitchenRoutes(router, store, tickets)
+	APIKeyRoutes(router, store)
+	TenantRoutes(router, store, prices)
+	ReservationRoutes(router, store, bookings)
+	WaitlistRoutes(router, store, bookings, notifier)
+
+	// Catch-all handler for undefined routes
+	router.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Route not found",
+			"path":    c.Request.URL.Path,
+			"message": "Check your endpoint",
+		})
+	})
+	return router
+}
@@ -0,0 +1,434 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"restaurant_management/booking"
+	"restaurant_management/database/memory"
+	"restaurant_management/helpers"
+	"restaurant_management/mail"
+	"restaurant_management/models"
+	"restaurant_management/money"
+	"restaurant_management/notify"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+	"restaurant_management/signing"
+	"restaurant_management/views"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	helpers.SigningKeys = signing.Ephemeral()
+	os.Exit(m.Run())
+}
+
+// server is the whole API on an in-memory store.
+type server struct {
+	t      *testing.T
+	store  *repository.Store
+	outbox *outbox
+	router *gin.Engine
+}
+
+// outbox keeps the mail the server sends.
+type outbox struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (o *outbox) Send(ctx context.Context, message mail.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, message)
+	return nil
+}
+
+// to returns the messages sent to address, oldest first.
+func (o *outbox) to(address string) []mail.Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	messages := []mail.Message{}
+	for _, message := range o.messages {
+		if message.To == address {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+	prices, err := pricing.LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bookings, err := booking.LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memory.NewStore()
+	sent := &outbox{}
+	return &server{t: t, store: store, outbox: sent, router: NewRouter(store, prices, bookings, sent, notify.NewNotifier(""))}
+}
+
+// call sends body as JSON with token, if any, and decodes the JSON answer
+// into out, if given. It returns the status code.
+func (s *server) call(method string, path string, token string, body any, out any) int {
+	s.t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	request := httptest.NewRequest(method, path, &payload)
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response := httptest.NewRecorder()
+	s.router.ServeHTTP(response, request)
+	if out != nil {
+		if err := json.Unmarshal(response.Body.Bytes(), out); err != nil {
+			s.t.Fatalf("%s %s answered %d %q: %v", method, path, response.Code, response.Body.String(), err)
+		}
+	}
+	return response.Code
+}
+
+// addUser stores a verified account with role and returns its id. The
+// password is hashed at the lowest cost to keep the tests fast.
+func (s *server) addUser(email string, password string, role string) string {
+	s.t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	first, last, hashed := "Test", "User", string(hash)
+	now := time.Now().UTC().Truncate(time.Second)
+	user := models.User{
+		ID:             primitive.NewObjectID(),
+		First_name:     &first,
+		Last_name:      &last,
+		Email:          &email,
+		Password:       &hashed,
+		Role:           role,
+		Email_verified: true,
+		Created_at:     now,
+		Updated_at:     now,
+	}
+	user.User_id = user.ID.Hex()
+	if err := s.store.Users.Create(context.Background(), user); err != nil {
+		s.t.Fatalf("Users.Create: %v", err)
+	}
+	return user.User_id
+}
+
+// login logs in and returns the access token.
+func (s *server) login(email string, password string) string {
+	s.t.Helper()
+	var answer struct {
+		Token string `json:"token"`
+	}
+	if status := s.call(http.MethodPost, "/users/login", "", gin.H{"email": email, "password": password}, &answer); status != http.StatusOK {
+		s.t.Fatalf("login as %s answered %d", email, status)
+	}
+	return answer.Token
+}
+
+// created returns the id a create handler answers with.
+func (s *server) created(path string, token string, body any) string {
+	s.t.Helper()
+	var answer struct {
+		InsertedID string
+	}
+	if status := s.call(http.MethodPost, path, token, body, &answer); status != http.StatusOK {
+		s.t.Fatalf("POST %s answered %d", path, status)
+	}
+	return answer.InsertedID
+}
+
+// placeOrder adds a menu, a food and a table and orders two M portions and
+// one L of the food, priced 12.50 with L 2.00 more. It returns the order id.
+func (s *server) placeOrder(token string) string {
+	s.t.Helper()
+	menuId := s.created("/menus", token, gin.H{"name": "Lunch", "category": "main"})
+	foodId := s.created("/foods", token, gin.H{
+		"name": "Pasta", "price": 12.5, "food_image": "pasta.png", "menu_id": menuId,
+		"sizes": []gin.H{{"size": "M", "price_delta": 0}, {"size": "L", "price_delta": 2}},
+	})
+	tableId := s.created("/tables", token, gin.H{"number_of_guests": 4, "tabe_number": 7})
+
+	items := gin.H{"table_id": tableId, "order_items": []gin.H{
+		{"food_id": foodId, "size": "M", "quantity": 2},
+		{"food_id": foodId, "size": "L"},
+	}}
+	if status := s.call(http.MethodPost, "/orderItems", token, items, nil); status != http.StatusOK {
+		s.t.Fatalf("POST /orderItems answered %d", status)
+	}
+	var orders []models.Order
+	s.call(http.MethodGet, "/orders", token, nil, &orders)
+	for _, order := range orders {
+		if order.Table_id != nil && *order.Table_id == tableId {
+			return order.Order_id
+		}
+	}
+	s.t.Fatal("the order is not listed")
+	return ""
+}
+
+// advance moves an order through statuses, in order.
+func (s *server) advance(token string, orderId string, statuses ...string) {
+	s.t.Helper()
+	for _, status := range statuses {
+		if code := s.call(http.MethodPost, "/orders/"+orderId+"/status", token, gin.H{"status": status}, nil); code != http.StatusOK {
+			s.t.Fatalf("moving order to %s answered %d", status, code)
+		}
+	}
+}
+
+func TestOrderFlow(t *testing.T) {
+	s := newServer(t)
+	s.addUser("manager@example.com", "secret1", models.RoleManager)
+	token := s.login("manager@example.com", "secret1")
+	orderId := s.placeOrder(token)
+
+	var orders []models.Order
+	if status := s.call(http.MethodGet, "/orders", token, nil, &orders); status != http.StatusOK || len(orders) != 1 || orders[0].Order_id != orderId {
+		t.Fatalf("GET /orders answered %d with %d orders, want the order", status, len(orders))
+	}
+
+	var bill []struct {
+		Payment_due struct {
+			Amount float64
+		} `json:"payment_due"`
+		Order_items []any `json:"order_items"`
+	}
+	if status := s.call(http.MethodGet, "/orderItems-order/"+orderId, token, nil, &bill); status != http.StatusOK {
+		t.Fatalf("GET /orderItems-order answered %d", status)
+	}
+	if len(bill) != 1 || len(bill[0].Order_items) != 2 || bill[0].Payment_due.Amount != 39.5 {
+		t.Errorf("bill = %+v, want 2 items coming to 39.50", bill)
+	}
+
+	if status := s.call(http.MethodGet, "/orders", "", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("GET /orders without a token answered %d, want 401", status)
+	}
+}
+
+func TestDiscountsOnlyBeforeBilling(t *testing.T) {
+	s := newServer(t)
+	s.addUser("manager@example.com", "secret1", models.RoleManager)
+	token := s.login("manager@example.com", "secret1")
+	orderId := s.placeOrder(token)
+	discount := gin.H{"discounts": []gin.H{{"name": "staff", "type": "percentage", "value": 10}}}
+
+	if status := s.call(http.MethodPatch, "/orders/"+orderId, token, discount, nil); status != http.StatusOK {
+		t.Fatalf("discounting an open order answered %d, want 200", status)
+	}
+	s.advance(token, orderId, models.OrderStatusSentToKitchen, models.OrderStatusServed)
+	if status := s.call(http.MethodPost, "/orders/"+orderId+"/invoice", token, nil, nil); status != http.StatusOK {
+		t.Fatalf("invoicing answered %d", status)
+	}
+	if status := s.call(http.MethodPatch, "/orders/"+orderId, token, discount, nil); status != http.StatusConflict {
+		t.Errorf("discounting a billed order answered %d, want 409", status)
+	}
+}
+
+func TestFoodPrices(t *testing.T) {
+	s := newServer(t)
+	s.addUser("manager@example.com", "secret1", models.RoleManager)
+	token := s.login("manager@example.com", "secret1")
+	menuId := s.created("/menus", token, gin.H{"name": "Lunch", "category": "main"})
+	food := func(price any, sizes ...gin.H) gin.H {
+		return gin.H{"name": "Pasta", "price": price, "food_image": "pasta.png", "menu_id": menuId, "sizes": sizes}
+	}
+
+	refused := map[string]gin.H{
+		"a price in another currency":  food(gin.H{"amount": 12.5, "currency": "EUR"}),
+		"a negative price":             food(-1),
+		"a size in another currency":   food(12.5, gin.H{"size": "L", "price_delta": gin.H{"amount": 2, "currency": "EUR"}}),
+		"a size that sells below zero": food(1, gin.H{"size": "S", "price_delta": -2}),
+	}
+	for name, body := range refused {
+		if status := s.call(http.MethodPost, "/foods", token, body, nil); status != http.StatusBadRequest {
+			t.Errorf("creating a food with %s answered %d, want 400", name, status)
+		}
+	}
+
+	foodId := s.created("/foods", token, food(12.5, gin.H{"size": "S", "price_delta": -2}))
+	var created models.Food
+	s.call(http.MethodGet, "/foods/"+foodId, token, nil, &created)
+	if created.Price == nil || *created.Price != money.New(1250, pricing.DefaultCurrency) || created.Sizes[0].Price_delta != money.New(-200, pricing.DefaultCurrency) {
+		t.Errorf("food priced %+v with sizes %+v, want 12.50 and -2.00 in %s", created.Price, created.Sizes, pricing.DefaultCurrency)
+	}
+	if status := s.call(http.MethodPatch, "/foods/"+foodId, token, gin.H{"price": gin.H{"amount": 3, "currency": "EUR"}}, nil); status != http.StatusBadRequest {
+		t.Errorf("repricing the food in EUR answered %d, want 400", status)
+	}
+	if status := s.call(http.MethodPatch, "/foods/"+foodId, token, gin.H{"price": 1.5}, nil); status != http.StatusBadRequest {
+		t.Errorf("repricing the food so that S sells below zero answered %d, want 400", status)
+	}
+}
+
+func TestModifiersKeptAcrossEdits(t *testing.T) {
+	s := newServer(t)
+	s.addUser("manager@example.com", "secret1", models.RoleManager)
+	token := s.login("manager@example.com", "secret1")
+	menuId := s.created("/menus", token, gin.H{"name": "Lunch", "category": "main"})
+	extras := func(cheese float64) []gin.H {
+		return []gin.H{{"name": "Extras", "modifiers": []gin.H{{"name": "Cheese", "price_delta": cheese}, {"name": "Olives", "price_delta": 0.5}}}}
+	}
+	foodId := s.created("/foods", token, gin.H{"name": "Pasta", "price": 10, "food_image": "pasta.png", "menu_id": menuId, "modifier_groups": extras(1)})
+	var food models.Food
+	s.call(http.MethodGet, "/foods/"+foodId, token, nil, &food)
+	group := food.Modifier_groups[0]
+	cheese := gin.H{"modifier_group_id": group.Modifier_group_id, "modifier_id": group.Modifiers[0].Modifier_id}
+
+	tableId := s.created("/tables", token, gin.H{"number_of_guests": 2, "tabe_number": 3})
+	items := gin.H{"table_id": tableId, "order_items": []gin.H{{"food_id": foodId, "modifiers": []gin.H{cheese}}}}
+	if status := s.call(http.MethodPost, "/orderItems", token, items, nil); status != http.StatusOK {
+		t.Fatalf("POST /orderItems answered %d", status)
+	}
+	var ordered []models.OrderItem
+	s.call(http.MethodGet, "/orderItems", token, nil, &ordered)
+	itemId := ordered[0].Order_item_id
+
+	// the groups are sent again without ids, as a menu editor would
+	if status := s.call(http.MethodPatch, "/foods/"+foodId, token, gin.H{"modifier_groups": extras(2)}, &food); status != http.StatusOK {
+		t.Fatalf("repricing the cheese answered %d", status)
+	}
+	if food.Modifier_groups[0].Modifier_group_id != group.Modifier_group_id || food.Modifier_groups[0].Modifiers[0].Modifier_id != group.Modifiers[0].Modifier_id {
+		t.Errorf("modifier ids changed from %+v to %+v", group, food.Modifier_groups[0])
+	}
+
+	edits := []gin.H{{"quantity": 2}, {"modifiers": []gin.H{cheese}}}
+	for _, edit := range edits {
+		var item models.OrderItem
+		if status := s.call(http.MethodPatch, "/orderItems/"+itemId, token, edit, &item); status != http.StatusOK {
+			t.Fatalf("PATCH %v answered %d", edit, status)
+		}
+		if len(item.Modifiers) != 1 || item.Modifiers[0].Price_delta != money.New(100, pricing.DefaultCurrency) {
+			t.Errorf("after PATCH %v modifiers are %+v, want cheese at the 1.00 it was ordered at", edit, item.Modifiers)
+		}
+	}
+
+	olives := gin.H{"modifier_group_id": group.Modifier_group_id, "modifier_id": group.Modifiers[1].Modifier_id}
+	var item models.OrderItem
+	s.call(http.MethodPatch, "/orderItems/"+itemId, token, gin.H{"modifiers": []gin.H{cheese, olives}}, &item)
+	if len(item.Modifiers) != 2 || item.Modifiers[0].Price_delta != money.New(200, pricing.DefaultCurrency) {
+		t.Errorf("after choosing olives too modifiers are %+v, want cheese at the current 2.00", item.Modifiers)
+	}
+}
+
+func TestKitchenTicketsFollowTheOrder(t *testing.T) {
+	s := newServer(t)
+	s.addUser("manager@example.com", "secret1", models.RoleManager)
+	token := s.login("manager@example.com", "secret1")
+	orderId := s.placeOrder(token)
+
+	tickets := func(query string) []models.Ticket {
+		t.Helper()
+		var list []models.Ticket
+		if status := s.call(http.MethodGet, "/kitchen/tickets"+query, token, nil, &list); status != http.StatusOK {
+			t.Fatalf("GET /kitchen/tickets%s answered %d", query, status)
+		}
+		return list
+	}
+	if open := tickets(""); len(open) != 0 {
+		t.Fatalf("%d tickets before the order went to the kitchen, want none", len(open))
+	}
+
+	s.advance(token, orderId, models.OrderStatusSentToKitchen)
+	open := tickets("")
+	if len(open) != 2 {
+		t.Fatalf("%d tickets once the order went to the kitchen, want 2", len(open))
+	}
+	for _, ticket := range open {
+		if ticket.Order_id != orderId || ticket.Table_number == nil || *ticket.Table_number != 7 {
+			t.Errorf("ticket %+v is not for the order at table 7", ticket)
+		}
+	}
+
+	tableId := s.created("/tables", token, gin.H{"number_of_guests": 2, "tabe_number": 9})
+	if status := s.call(http.MethodPost, "/orders/"+orderId+"/transfer", token, gin.H{"table_id": tableId}, nil); status != http.StatusOK {
+		t.Fatalf("transfer answered %d", status)
+	}
+	for _, ticket := range tickets("") {
+		if ticket.Table_number == nil || *ticket.Table_number != 9 {
+			t.Errorf("ticket %s still points at table %v after the transfer, want 9", ticket.Ticket_id, ticket.Table_number)
+		}
+	}
+
+	s.advance(token, orderId, models.OrderStatusVoided)
+	if open := tickets(""); len(open) != 0 {
+		t.Errorf("%d tickets still open after the order was voided", len(open))
+	}
+	if voided := tickets("?status=" + models.TicketStatusVoided); len(voided) != 2 {
+		t.Errorf("%d voided tickets, want 2", len(voided))
+	}
+}
+
+func TestBillIsSettledByPayments(t *testing.T) {
+	s := newServer(t)
+	s.addUser("manager@example.com", "secret1", models.RoleManager)
+	token := s.login("manager@example.com", "secret1")
+	orderId := s.placeOrder(token)
+	s.advance(token, orderId, models.OrderStatusSentToKitchen, models.OrderStatusServed)
+
+	paid := gin.H{"payment_status": models.PaymentStatusPaid}
+	if status := s.call(http.MethodPost, "/orders/"+orderId+"/invoice", token, paid, nil); status != http.StatusBadRequest {
+		t.Errorf("invoicing the order as paid answered %d, want 400", status)
+	}
+	var invoice models.Invoice
+	if status := s.call(http.MethodPost, "/orders/"+orderId+"/invoice", token, nil, &invoice); status != http.StatusOK {
+		t.Fatalf("invoicing answered %d", status)
+	}
+	if status := s.call(http.MethodPatch, "/invoices/"+invoice.Invoice_id, token, paid, nil); status != http.StatusBadRequest {
+		t.Errorf("marking the invoice paid answered %d, want 400", status)
+	}
+	if status := s.call(http.MethodPost, "/orders/"+orderId+"/status", token, gin.H{"status": models.OrderStatusPaid}, nil); status != http.StatusConflict {
+		t.Errorf("marking the order paid answered %d, want 409", status)
+	}
+
+	var settled views.SettlementView
+	s.call(http.MethodGet, "/invoices/"+invoice.Invoice_id+"/payments", token, nil, &settled)
+	if len(settled.Payments) != 1 || settled.Payments[0].Amount != invoice.Payment_due || settled.Balance != invoice.Payment_due {
+		t.Fatalf("an unsplit invoice is settled as %+v, want one pending payment of %v", settled, invoice.Payment_due)
+	}
+
+	if status := s.call(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/split", token, gin.H{"mode": "even", "ways": 2}, &settled); status != http.StatusOK {
+		t.Fatalf("splitting the bill answered %d", status)
+	}
+	if len(settled.Payments) != 2 {
+		t.Fatalf("the split bill has %d payments, want the 2 shares in place of the full bill", len(settled.Payments))
+	}
+	var order models.Order
+	for i, payment := range settled.Payments {
+		if status := s.call(http.MethodPost, "/payments/"+payment.Payment_id+"/pay", token, gin.H{"payment_method": "card"}, nil); status != http.StatusOK {
+			t.Fatalf("paying share %d answered %d", i+1, status)
+		}
+		s.call(http.MethodGet, "/orders/"+orderId, token, nil, &order)
+		want := models.OrderStatusBilled
+		if i == len(settled.Payments)-1 {
+			want = models.OrderStatusPaid
+		}
+		if order.Status != want {
+			t.Errorf("after paying share %d the order is %s, want %s", i+1, order.Status, want)
+		}
+	}
+	s.call(http.MethodGet, "/invoices/"+invoice.Invoice_id, token, nil, &invoice)
+	if invoice.Payment_status == nil || *invoice.Payment_status != models.PaymentStatusPaid {
+		t.Errorf("the settled invoice is %v, want paid", invoice.Payment_status)
+	}
+}
@@ -1,14 +1,26 @@
 package routes
 
 import (
+	"restaurant_management/booking"
 	controller "restaurant_management/controller"
+	"restaurant_management/kitchen"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func TableRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/tables", controller.GetTables())
-	incomingRoutes.GET("/tables/:id", controller.GetTable())
-	incomingRoutes.POST("/tables", controller.CreateTable())
-	incomingRoutes.PATCH("/tables/:id", controller.UpdateTable())
+func TableRoutes(incomingRoutes *gin.Engine, store *repository.Store, tickets *kitchen.Hub) {
+	incomingRoutes.GET("/tables", controller.GetTables(store))
+	incomingRoutes.GET("/tables/:id", controller.GetTable(store))
+	incomingRoutes.POST("/tables", middleware.Authorize(models.RoleManager), controller.CreateTable(store))
+	incomingRoutes.PATCH("/tables/:id", middleware.Authorize(models.RoleManager), controller.UpdateTable(store))
+	incomingRoutes.PATCH("/tables/:id/status", middleware.Authorize(models.RoleManager, models.RoleWaiter), controller.UpdateTableStatus(store))
+	incomingRoutes.POST("/tables/:id/merge", middleware.Authorize(models.RoleWaiter, models.RoleCashier, models.RoleManager), controller.MergeTables(store, tickets))
+}
+
+func FloorRoutes(incomingRoutes *gin.Engine, store *repository.Store, prices pricing.Config, bookings booking.Config) {
+	incomingRoutes.GET("/floor", controller.GetFloor(store, prices, bookings))
 }
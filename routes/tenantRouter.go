@@ -0,0 +1,19 @@
+package routes
+
+import (
+	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/pricing"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TenantRoutes(incomingRoutes *gin.Engine, store *repository.Store, prices pricing.Config) {
+	incomingRoutes.GET("/tenant", controller.GetCurrentTenant(store, prices))
+	incomingRoutes.GET("/tenants", middleware.Authorize(models.RoleAdmin), middleware.HeadOffice(), controller.GetTenants(store))
+	incomingRoutes.GET("/tenants/:id", middleware.Authorize(models.RoleAdmin), middleware.HeadOffice(), controller.GetTenant(store))
+	incomingRoutes.POST("/tenants", middleware.Authorize(models.RoleAdmin), middleware.HeadOffice(), controller.CreateTenant(store))
+	incomingRoutes.PATCH("/tenants/:id", middleware.Authorize(models.RoleAdmin), middleware.HeadOffice(), controller.UpdateTenant(store))
+}
@@ -2,13 +2,34 @@ package routes
 
 import (
 	controller "restaurant_management/controller"
+	"restaurant_management/mail"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func UserRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/users", controller.GetUsers())
-	incomingRoutes.GET("/users/:id", controller.GetUser())
-	incomingRoutes.POST("/users/signup", controller.SignUp())
-	incomingRoutes.POST("/users/login", controller.Login())
+func UserRoutes(incomingRoutes *gin.Engine, store *repository.Store, mailer mail.Sender) {
+	incomingRoutes.GET("/users", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), controller.GetUsers(store))
+	incomingRoutes.GET("/users/:id", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), controller.GetUser(store))
+	incomingRoutes.PATCH("/users/:id", middleware.Authentication(store), middleware.AuthorizeUnlessSelf(models.RoleAdmin), controller.UpdateUser(store))
+	incomingRoutes.POST("/users/:id/password", middleware.Authentication(store), controller.ChangePassword(store))
+	incomingRoutes.PATCH("/users/:id/role", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), controller.UpdateUserRole(store))
+	incomingRoutes.PATCH("/users/:id/tenant", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), middleware.HeadOffice(), controller.UpdateUserTenant(store))
+	incomingRoutes.POST("/users/:id/unlock", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), controller.UnlockUser(store))
+	incomingRoutes.POST("/users/:id/deactivate", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), controller.DeactivateUser(store))
+	incomingRoutes.POST("/users/:id/reactivate", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), controller.ReactivateUser(store))
+	incomingRoutes.GET("/audit-events", middleware.Authentication(store), middleware.Authorize(models.RoleAdmin), controller.GetAuditEvents(store))
+	incomingRoutes.POST("/users/signup", controller.SignUp(store, mailer))
+	incomingRoutes.POST("/users/login", controller.Login(store))
+	incomingRoutes.POST("/users/login/totp", controller.LoginTOTP(store))
+	incomingRoutes.POST("/users/totp/enroll", middleware.Authentication(store), controller.EnrollTOTP(store))
+	incomingRoutes.POST("/users/totp/confirm", middleware.Authentication(store), controller.ConfirmTOTP(store))
+	incomingRoutes.POST("/users/refresh", controller.RefreshToken(store))
+	incomingRoutes.POST("/users/logout", controller.Logout(store))
+	incomingRoutes.POST("/users/verify", controller.VerifyEmail(store))
+	incomingRoutes.POST("/users/verify/resend", controller.ResendVerification(store, mailer))
+	incomingRoutes.POST("/users/password/forgot", controller.ForgotPassword(store, mailer))
+	incomingRoutes.POST("/users/password/reset", controller.ResetPassword(store))
 }
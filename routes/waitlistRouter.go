@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"restaurant_management/booking"
+	controller "restaurant_management/controller"
+	"restaurant_management/middleware"
+	"restaurant_management/models"
+	"restaurant_management/notify"
+	"restaurant_management/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func WaitlistRoutes(incomingRoutes *gin.Engine, store *repository.Store, bookings booking.Config, notifier notify.Notifier) {
+	hosting := middleware.Authorize(models.RoleManager, models.RoleWaiter)
+	incomingRoutes.GET("/waitlist", controller.GetWaitlist(store, bookings))
+	incomingRoutes.GET("/waitlist/quote", controller.QuoteWait(store, bookings))
+	incomingRoutes.POST("/waitlist", hosting, controller.AddToWaitlist(store, bookings))
+	incomingRoutes.POST("/waitlist/:id/notify", hosting, controller.NotifyWaitlistEntry(store, notifier))
+	incomingRoutes.POST("/waitlist/:id/seat", hosting, controller.SeatWaitlistEntry(store, bookings))
+	incomingRoutes.POST("/waitlist/:id/cancel", hosting, controller.CancelWaitlistEntry(store))
+}
@@ -0,0 +1,165 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Config lists the keys tokens may be signed with. Keys are read from PEM
+// files (PKCS#1 or PKCS#8 private keys, PKIX public keys); HS256 keys take a
+// secret inline or from a file.
+type Config struct {
+	// Signing_kid names the key new tokens are signed with. It must have a
+	// private key or secret.
+	Signing_kid string `json:"signing_kid"`
+	// Legacy_kid names the key that verifies tokens issued without a kid
+	// header, so tokens from before rotation was configured stay valid.
+	Legacy_kid string      `json:"legacy_kid"`
+	Keys       []KeyConfig `json:"keys"`
+}
+
+type KeyConfig struct {
+	Kid              string `json:"kid"`
+	Algorithm        string `json:"algorithm"`
+	Secret           string `json:"secret"`
+	Secret_file      string `json:"secret_file"`
+	Private_key_file string `json:"private_key_file"`
+	Public_key_file  string `json:"public_key_file"`
+}
+
+// Load reads a JSON key config from path.
+func Load(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return config.KeySet()
+}
+
+// KeySet loads every key of the config.
+func (c Config) KeySet() (*KeySet, error) {
+	set := &KeySet{keys: map[string]*Key{}}
+	for _, keyConfig := range c.Keys {
+		if keyConfig.Kid == "" {
+			return nil, errors.New("every key needs a kid")
+		}
+		if _, ok := set.keys[keyConfig.Kid]; ok {
+			return nil, fmt.Errorf("key %s is listed twice", keyConfig.Kid)
+		}
+		key, err := keyConfig.load()
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", keyConfig.Kid, err)
+		}
+		set.keys[key.Kid] = key
+	}
+
+	set.signing = set.keys[c.Signing_kid]
+	if set.signing == nil {
+		return nil, fmt.Errorf("signing key %q is not listed", c.Signing_kid)
+	}
+	if set.signing.sign == nil {
+		return nil, fmt.Errorf("signing key %s has no private key", c.Signing_kid)
+	}
+	if c.Legacy_kid != "" {
+		set.legacy = set.keys[c.Legacy_kid]
+		if set.legacy == nil {
+			return nil, fmt.Errorf("legacy key %q is not listed", c.Legacy_kid)
+		}
+	}
+	return set, nil
+}
+
+func (k KeyConfig) load() (*Key, error) {
+	key := &Key{Kid: k.Kid, Algorithm: k.Algorithm}
+	switch k.Algorithm {
+	case AlgHS256:
+		key.method = jwt.SigningMethodHS256
+		secret := []byte(k.Secret)
+		if k.Secret_file != "" {
+			data, err := os.ReadFile(k.Secret_file)
+			if err != nil {
+				return nil, err
+			}
+			secret = data
+		}
+		if len(secret) == 0 {
+			return nil, errors.New("HS256 needs a secret")
+		}
+		key.sign, key.verify = secret, secret
+	case AlgRS256:
+		key.method = jwt.SigningMethodRS256
+		if k.Private_key_file != "" {
+			data, err := os.ReadFile(k.Private_key_file)
+			if err != nil {
+				return nil, err
+			}
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.sign, key.verify = privateKey, &privateKey.PublicKey
+		} else if k.Public_key_file != "" {
+			data, err := os.ReadFile(k.Public_key_file)
+			if err != nil {
+				return nil, err
+			}
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.verify = publicKey
+		}
+	case AlgEdDSA:
+		key.method = SigningMethodEdDSA
+		if k.Private_key_file != "" {
+			parsed, err := parsePEM(k.Private_key_file, x509.ParsePKCS8PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			privateKey, ok := parsed.(ed25519.PrivateKey)
+			if !ok {
+				return nil, errors.New("private key is not an Ed25519 key")
+			}
+			key.sign, key.verify = privateKey, privateKey.Public()
+		} else if k.Public_key_file != "" {
+			parsed, err := parsePEM(k.Public_key_file, x509.ParsePKIXPublicKey)
+			if err != nil {
+				return nil, err
+			}
+			publicKey, ok := parsed.(ed25519.PublicKey)
+			if !ok {
+				return nil, errors.New("public key is not an Ed25519 key")
+			}
+			key.verify = publicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+	if key.verify == nil {
+		return nil, errors.New("needs a private_key_file or public_key_file")
+	}
+	return key, nil
+}
+
+func parsePEM(path string, parse func([]byte) (any, error)) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+	return parse(block.Bytes)
+}
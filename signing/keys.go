@@ -0,0 +1,110 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Supported token algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key is one entry of a KeySet. Verification-only keys have no signing
+// material.
+type Key struct {
+	Kid       string
+	Algorithm string
+	method    jwt.SigningMethod
+	sign      any
+	verify    any
+}
+
+// KeySet signs tokens with one key and verifies them with any key it holds,
+// so a retired key can keep verifying until the tokens it signed expire.
+type KeySet struct {
+	signing *Key
+	legacy  *Key
+	keys    map[string]*Key
+}
+
+// JWK is the public half of a key as published in a JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// Ephemeral returns a key set holding a single Ed25519 key generated for
+// this process. Tokens it signs stop verifying once the process exits.
+func Ephemeral() *KeySet {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	key := &Key{Kid: "ephemeral", Algorithm: AlgEdDSA, method: SigningMethodEdDSA, sign: privateKey, verify: publicKey}
+	return &KeySet{signing: key, keys: map[string]*Key{key.Kid: key}}
+}
+
+// Sign signs claims with the signing key and names it in the kid header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.Kid
+	return token.SignedString(s.signing.sign)
+}
+
+// Keyfunc finds the key a token was signed with for jwt.Parse. Tokens
+// without a kid predate key rotation and are checked against the legacy key,
+// if one is configured. The token's alg must match the key's algorithm.
+func (s *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	key := s.legacy
+	if kid, ok := token.Header["kid"]; ok {
+		id, _ := kid.(string)
+		key = s.keys[id]
+	}
+	if key == nil {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("key %s does not sign with %s", key.Kid, token.Method.Alg())
+	}
+	return key.verify, nil
+}
+
+// JWKS lists the public keys of the set. HS256 keys are shared secrets and
+// are never published.
+func (s *KeySet) JWKS() []JWK {
+	jwks := []JWK{}
+	for _, key := range s.keys {
+		switch publicKey := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "RSA", Kid: key.Kid, Use: "sig", Alg: key.Algorithm,
+				N: base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "OKP", Kid: key.Kid, Use: "sig", Alg: key.Algorithm,
+				Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
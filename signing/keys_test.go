@@ -0,0 +1,222 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// keyFiles writes an RSA and an Ed25519 key pair as PEM files into a
+// temporary directory and returns their paths by name: rsa, rsa.pub,
+// ed25519 and ed25519.pub.
+func keyFiles(t *testing.T) map[string]string {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPrivateDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublicDER, err := x509.MarshalPKIXPublicKey(edPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	blocks := map[string]*pem.Block{
+		"rsa":         {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		"rsa.pub":     {Type: "PUBLIC KEY", Bytes: rsaPublic},
+		"ed25519":     {Type: "PRIVATE KEY", Bytes: edPrivateDER},
+		"ed25519.pub": {Type: "PUBLIC KEY", Bytes: edPublicDER},
+	}
+	paths := map[string]string{}
+	for name, block := range blocks {
+		paths[name] = filepath.Join(dir, name+".pem")
+		if err := os.WriteFile(paths[name], pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func parse(t *testing.T, set *KeySet, token string) error {
+	t.Helper()
+	_, err := jwt.ParseWithClaims(token, &jwt.StandardClaims{}, set.Keyfunc)
+	return err
+}
+
+func TestSignAndVerify(t *testing.T) {
+	files := keyFiles(t)
+	for _, signing := range []KeyConfig{
+		{Kid: "hs", Algorithm: AlgHS256, Secret: "a shared secret"},
+		{Kid: "rs", Algorithm: AlgRS256, Private_key_file: files["rsa"]},
+		{Kid: "ed", Algorithm: AlgEdDSA, Private_key_file: files["ed25519"]},
+	} {
+		t.Run(signing.Algorithm, func(t *testing.T) {
+			set, err := Config{Signing_kid: signing.Kid, Keys: []KeyConfig{signing}}.KeySet()
+			if err != nil {
+				t.Fatalf("KeySet: %v", err)
+			}
+			token, err := set.Sign(&jwt.StandardClaims{Subject: "u"})
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			parsed, _ := new(jwt.Parser).Parse(token, nil)
+			if parsed == nil || parsed.Header["kid"] != signing.Kid || parsed.Method.Alg() != signing.Algorithm {
+				t.Errorf("token header = %v, want kid %s and alg %s", parsed.Header, signing.Kid, signing.Algorithm)
+			}
+			if err := parse(t, set, token); err != nil {
+				t.Errorf("token does not verify: %v", err)
+			}
+			if err := parse(t, Ephemeral(), token); err == nil {
+				t.Error("token verifies with an unrelated key set")
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	files := keyFiles(t)
+	before, err := Config{Signing_kid: "2025", Keys: []KeyConfig{
+		{Kid: "2025", Algorithm: AlgRS256, Private_key_file: files["rsa"]},
+	}}.KeySet()
+	if err != nil {
+		t.Fatalf("KeySet before rotation: %v", err)
+	}
+	old, err := before.Sign(&jwt.StandardClaims{Subject: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the retired key only verifies; a new one signs
+	after, err := Config{Signing_kid: "2026", Keys: []KeyConfig{
+		{Kid: "2025", Algorithm: AlgRS256, Public_key_file: files["rsa.pub"]},
+		{Kid: "2026", Algorithm: AlgEdDSA, Private_key_file: files["ed25519"]},
+	}}.KeySet()
+	if err != nil {
+		t.Fatalf("KeySet after rotation: %v", err)
+	}
+	if err := parse(t, after, old); err != nil {
+		t.Errorf("token of the retired key no longer verifies: %v", err)
+	}
+	fresh, err := after.Sign(&jwt.StandardClaims{Subject: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parse(t, before, fresh); err == nil {
+		t.Error("token of the new key verifies with the old set")
+	}
+}
+
+func TestLegacyTokens(t *testing.T) {
+	legacy := KeyConfig{Kid: "legacy", Algorithm: AlgHS256, Secret: "the old secret"}
+	signing := KeyConfig{Kid: "ed", Algorithm: AlgEdDSA, Private_key_file: keyFiles(t)["ed25519"]}
+	// a token from before rotation: HS256 with no kid header
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{Subject: "u"}).SignedString([]byte(legacy.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := Config{Signing_kid: "ed", Legacy_kid: "legacy", Keys: []KeyConfig{legacy, signing}}.KeySet()
+	if err != nil {
+		t.Fatalf("KeySet: %v", err)
+	}
+	if err := parse(t, set, token); err != nil {
+		t.Errorf("legacy token does not verify: %v", err)
+	}
+
+	set, err = Config{Signing_kid: "ed", Keys: []KeyConfig{legacy, signing}}.KeySet()
+	if err != nil {
+		t.Fatalf("KeySet: %v", err)
+	}
+	if err := parse(t, set, token); err == nil {
+		t.Error("token without a kid verifies although no legacy key is configured")
+	}
+}
+
+func TestAlgorithmMustMatchKey(t *testing.T) {
+	files := keyFiles(t)
+	set, err := Config{Signing_kid: "rs", Keys: []KeyConfig{
+		{Kid: "rs", Algorithm: AlgRS256, Private_key_file: files["rsa"]},
+	}}.KeySet()
+	if err != nil {
+		t.Fatalf("KeySet: %v", err)
+	}
+	// an HS256 token keyed with the published RSA public key must not pass
+	public, err := os.ReadFile(files["rsa.pub"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{Subject: "u"})
+	forged.Header["kid"] = "rs"
+	token, err := forged.SignedString(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parse(t, set, token); err == nil {
+		t.Error("HS256 token verifies against an RS256 key")
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	files := keyFiles(t)
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"signing key missing", Config{Signing_kid: "x", Keys: []KeyConfig{{Kid: "a", Algorithm: AlgHS256, Secret: "s"}}}, "not listed"},
+		{"signing key without private key", Config{Signing_kid: "a", Keys: []KeyConfig{{Kid: "a", Algorithm: AlgRS256, Public_key_file: files["rsa.pub"]}}}, "no private key"},
+		{"legacy key missing", Config{Signing_kid: "a", Legacy_kid: "b", Keys: []KeyConfig{{Kid: "a", Algorithm: AlgHS256, Secret: "s"}}}, "legacy key"},
+		{"kid listed twice", Config{Signing_kid: "a", Keys: []KeyConfig{{Kid: "a", Algorithm: AlgHS256, Secret: "s"}, {Kid: "a", Algorithm: AlgHS256, Secret: "t"}}}, "twice"},
+		{"kid missing", Config{Keys: []KeyConfig{{Algorithm: AlgHS256, Secret: "s"}}}, "kid"},
+		{"unsupported algorithm", Config{Signing_kid: "a", Keys: []KeyConfig{{Kid: "a", Algorithm: "none"}}}, "unsupported"},
+		{"empty secret", Config{Signing_kid: "a", Keys: []KeyConfig{{Kid: "a", Algorithm: AlgHS256}}}, "secret"},
+		{"wrong key type", Config{Signing_kid: "a", Keys: []KeyConfig{{Kid: "a", Algorithm: AlgEdDSA, Public_key_file: files["rsa.pub"]}}}, "not an Ed25519"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.config.KeySet(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: KeySet error = %v, want one mentioning %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	files := keyFiles(t)
+	set, err := Config{Signing_kid: "ed", Keys: []KeyConfig{
+		{Kid: "ed", Algorithm: AlgEdDSA, Private_key_file: files["ed25519"]},
+		{Kid: "hs", Algorithm: AlgHS256, Secret: "never published"},
+		{Kid: "rs", Algorithm: AlgRS256, Public_key_file: files["rsa.pub"]},
+	}}.KeySet()
+	if err != nil {
+		t.Fatalf("KeySet: %v", err)
+	}
+	jwks := set.JWKS()
+	if len(jwks) != 2 || jwks[0].Kid != "ed" || jwks[1].Kid != "rs" {
+		t.Fatalf("JWKS = %+v, want the ed and rs keys only", jwks)
+	}
+	if jwks[0].Kty != "OKP" || jwks[0].Crv != "Ed25519" || jwks[0].X == "" {
+		t.Errorf("Ed25519 JWK = %+v", jwks[0])
+	}
+	if jwks[1].Kty != "RSA" || jwks[1].N == "" || jwks[1].E != "AQAB" {
+		t.Errorf("RSA JWK = %+v", jwks[1])
+	}
+}
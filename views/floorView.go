@@ -0,0 +1,24 @@
+package views
+
+import (
+	"restaurant_management/money"
+	"time"
+)
+
+// FloorTableView is a table on the floor plan with its live status, the
+// orders running on it and what they come to so far.
+type FloorTableView struct {
+	Table_id            string      `json:"table_id"`
+	Table_number        *int        `json:"table_number"`
+	Number_of_guests    *int        `json:"number_of_guests"`
+	Section             *string     `json:"section"`
+	X                   *float64    `json:"x"`
+	Y                   *float64    `json:"y"`
+	Shape               *string     `json:"shape"`
+	Server_id           *string     `json:"server_id"`
+	Status              string      `json:"status"`
+	Order_ids           []string    `json:"order_ids"`
+	Order_total         money.Money `json:"order_total"`
+	Item_count          int         `json:"item_count"`
+	Next_reservation_at *time.Time  `json:"next_reservation_at"`
+}
@@ -1,6 +1,9 @@
 package views
 
-import "time"
+import (
+	"restaurant_management/pricing"
+	"time"
+)
 
 type InvoiceViewFormat struct {
 	Invoice_id       string
@@ -11,4 +14,5 @@ type InvoiceViewFormat struct {
 	Table_number     any
 	Payment_due_date time.Time
 	Order_details    any
+	Pricing          *pricing.Breakdown
 }
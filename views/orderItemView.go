@@ -0,0 +1,91 @@
+package views
+
+import (
+	"restaurant_management/models"
+	"restaurant_management/money"
+	"restaurant_management/pricing"
+)
+
+// OrderItemLine is one order item joined with its food and table. Price is
+// the unit price for the ordered size and modifiers and Amount is Price times
+// Quantity.
+type OrderItemLine struct {
+	Amount        *money.Money               `json:"amount" bson:"amount"`
+	Total_count   int                        `json:"total_count" bson:"total_count"`
+	Food_id       string                     `json:"food_id" bson:"food_id"`
+	Food_name     *string                    `json:"food_name" bson:"food_name"`
+	Food_image    *string                    `json:"food_image" bson:"food_image"`
+	Table_number  *int                       `json:"table_number" bson:"table_number"`
+	Table_id      string                     `json:"table_id" bson:"table_id"`
+	Order_id      string                     `json:"order_id" bson:"order_id"`
+	Price         *money.Money               `json:"price" bson:"price"`
+	Menu_category string                     `json:"menu_category" bson:"menu_category"`
+	Size          *string                    `json:"size" bson:"size"`
+	Modifiers     []models.OrderItemModifier `json:"modifiers" bson:"modifiers"`
+	Quantity      int                        `json:"quantity" bson:"quantity"`
+	Order_item_id string                     `json:"order_item_id" bson:"order_item_id"`
+	Seat          *int                       `json:"seat" bson:"seat"`
+}
+
+type OrderItemsGroup struct {
+	Order_id     string `json:"order_id" bson:"order_id"`
+	Table_id     string `json:"table_id" bson:"table_id"`
+	Table_number *int   `json:"table_number" bson:"table_number"`
+}
+
+// OrderItemsView is the per-order grouping returned by ItemsByOrder.
+type OrderItemsView struct {
+	ID           OrderItemsGroup `json:"_id" bson:"_id"`
+	Payment_due  money.Money     `json:"payment_due" bson:"payment_due"`
+	Total_count  int             `json:"total_count" bson:"total_count"`
+	Table_number *int            `json:"table_number" bson:"table_number"`
+	Order_items  []OrderItemLine `json:"order_items" bson:"order_items"`
+	// Pricing is the full bill; Payment_due is its total once priced.
+	Pricing *pricing.Breakdown `json:"pricing" bson:"pricing,omitempty"`
+}
+
+// GroupOrderItems groups joined lines the way the Mongo $group stage does:
+// by order id, table id and table number, in order of first appearance. It
+// fails when the lines of an order are in different currencies.
+func GroupOrderItems(lines []OrderItemLine) ([]OrderItemsView, error) {
+	orderItems := []OrderItemsView{}
+	for _, line := range lines {
+		group := OrderItemsGroup{
+			Order_id:     line.Order_id,
+			Table_id:     line.Table_id,
+			Table_number: line.Table_number,
+		}
+		index := -1
+		for i := range orderItems {
+			if sameGroup(orderItems[i].ID, group) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			orderItems = append(orderItems, OrderItemsView{ID: group, Table_number: group.Table_number})
+			index = len(orderItems) - 1
+		}
+
+		view := &orderItems[index]
+		if line.Amount != nil {
+			var err error
+			if view.Payment_due, err = view.Payment_due.Add(*line.Amount); err != nil {
+				return nil, err
+			}
+		}
+		view.Total_count++
+		view.Order_items = append(view.Order_items, line)
+	}
+	return orderItems, nil
+}
+
+func sameGroup(a, b OrderItemsGroup) bool {
+	if a.Order_id != b.Order_id || a.Table_id != b.Table_id {
+		return false
+	}
+	if a.Table_number == nil || b.Table_number == nil {
+		return a.Table_number == b.Table_number
+	}
+	return *a.Table_number == *b.Table_number
+}
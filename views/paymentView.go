@@ -0,0 +1,18 @@
+package views
+
+import (
+	"restaurant_management/models"
+	"restaurant_management/money"
+)
+
+// SettlementView is where the bill of an invoice stands: what is due, what
+// has been paid and the balance left, with the shares it was split into.
+type SettlementView struct {
+	Invoice_id     string           `json:"invoice_id"`
+	Order_id       string           `json:"order_id"`
+	Payment_status string           `json:"payment_status"`
+	Payment_due    money.Money      `json:"payment_due"`
+	Paid           money.Money      `json:"paid"`
+	Balance        money.Money      `json:"balance"`
+	Payments       []models.Payment `json:"payments"`
+}
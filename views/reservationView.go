@@ -0,0 +1,20 @@
+package views
+
+import "time"
+
+// AvailabilityView answers an availability search: the start times on Date
+// at which some table seats Party for Turn_minutes.
+type AvailabilityView struct {
+	Date         string     `json:"date"`
+	Party        int        `json:"party"`
+	Timezone     string     `json:"timezone"`
+	Turn_minutes int        `json:"turn_minutes"`
+	Slots        []SlotView `json:"slots"`
+}
+
+// SlotView is one bookable start time and how many tables are free for it.
+type SlotView struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Free_tables int       `json:"free_tables"`
+}
@@ -0,0 +1,63 @@
+package views
+
+import (
+	"restaurant_management/helpers"
+	"restaurant_management/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserView is a user as the API shows it: never the password hash, tokens
+// or TOTP secrets.
+type UserView struct {
+	ID             primitive.ObjectID `json:"ID"`
+	First_name     *string            `json:"first_name"`
+	Last_name      *string            `json:"last_name"`
+	Email          *string            `json:"email"`
+	Avatar         *string            `json:"avatar"`
+	Phone          *string            `json:"phone"`
+	Role           string             `json:"role"`
+	Tenant_id      string             `json:"tenant_id"`
+	Email_verified bool               `json:"email_verified"`
+	Totp_enabled   bool               `json:"totp_enabled"`
+	Deactivated_at *time.Time         `json:"deactivated_at"`
+	Created_at     time.Time          `json:"created_at"`
+	Updated_at     time.Time          `json:"updated_at"`
+	User_id        string             `json:"user_id"`
+}
+
+// LoginView is the response to a completed login: the user plus the tokens
+// just issued.
+type LoginView struct {
+	UserView
+	Token         string `json:"token"`
+	Refresh_token string `json:"refresh_token"`
+}
+
+func NewUserView(user models.User) UserView {
+	return UserView{
+		ID:             user.ID,
+		First_name:     user.First_name,
+		Last_name:      user.Last_name,
+		Email:          user.Email,
+		Avatar:         user.Avatar,
+		Phone:          user.Phone,
+		Role:           helpers.UserRole(user),
+		Tenant_id:      user.Tenant_id,
+		Email_verified: user.Email_verified,
+		Totp_enabled:   user.Totp_enabled,
+		Deactivated_at: user.Deactivated_at,
+		Created_at:     user.Created_at,
+		Updated_at:     user.Updated_at,
+		User_id:        user.User_id,
+	}
+}
+
+func NewUserViews(users []models.User) []UserView {
+	result := make([]UserView, 0, len(users))
+	for _, user := range users {
+		result = append(result, NewUserView(user))
+	}
+	return result
+}
@@ -0,0 +1,12 @@
+package views
+
+import "restaurant_management/models"
+
+// WaitlistEntryView is a party in the waitlist with its place in the queue
+// and how long it can still expect to wait. Estimated_wait_minutes is null
+// when no table seats the party.
+type WaitlistEntryView struct {
+	models.WaitlistEntry
+	Position               int  `json:"position"`
+	Estimated_wait_minutes *int `json:"estimated_wait_minutes"`
+}